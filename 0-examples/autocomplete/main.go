@@ -7,10 +7,10 @@ import (
 	"strings"
 
 	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
-	"github.com/diamondburned/arikawa/v3/utils/json/option"
 )
 
 // To run, do `GUILD_ID="GUILD ID" BOT_TOKEN="TOKEN HERE" go run .`
@@ -30,50 +30,32 @@ func main() {
 		log.Fatalln("Failed to get application ID:", err)
 	}
 
-	s.AddHandler(func(e *gateway.InteractionCreateEvent) {
-		var resp api.InteractionResponse
-		switch d := e.Data.(type) {
-		case *discord.CommandInteraction:
-			content := option.NewNullableString("Pong: " + d.Options[0].String() + "!")
-			resp = api.InteractionResponse{
-				Type: api.MessageInteractionWithSource,
-				Data: &api.InteractionResponseData{
-					Content: content,
-				},
-			}
-		case *discord.AutocompleteInteraction:
-			allChoices := api.AutocompleteStringChoices{
-				{Name: "Choice A", Value: "Choice A"},
-				{Name: "Choice B", Value: "Choice B"},
-				{Name: "Choice C", Value: "Choice C"},
-				{Name: "Abc Def", Value: "Abcdef"},
-				{Name: "Ghi Jkl", Value: "Ghijkl"},
-				{Name: "Mno Pqr", Value: "Mnopqr"},
-				{Name: "Stu Vwx", Value: "Stuvwx"},
-			}
-			query := strings.ToLower(d.Options[0].String())
-			var choices api.AutocompleteStringChoices
-			for _, choice := range allChoices {
-				if strings.HasPrefix(strings.ToLower(choice.Name), query) ||
-					strings.HasPrefix(strings.ToLower(choice.Value), query) {
-					choices = append(choices, choice)
-				}
-			}
-			resp = api.InteractionResponse{
-				Type: api.AutocompleteResult,
-				Data: &api.InteractionResponseData{
-					Choices: &choices,
-				},
-			}
-		default:
-			return
+	r := cmdroute.NewRouter()
+	r.AddFunc("ping", func(cmd cmdroute.CommandData) *api.InteractionResponse {
+		return cmdroute.Respond("Pong: " + cmd.Options[0].String() + "!")
+	})
+	r.AddAutocompleterFunc("ping", "text", func(data cmdroute.AutocompleteData) api.AutocompleteChoices {
+		allChoices := api.AutocompleteStringChoices{
+			{Name: "Choice A", Value: "Choice A"},
+			{Name: "Choice B", Value: "Choice B"},
+			{Name: "Choice C", Value: "Choice C"},
+			{Name: "Abc Def", Value: "Abcdef"},
+			{Name: "Ghi Jkl", Value: "Ghijkl"},
+			{Name: "Mno Pqr", Value: "Mnopqr"},
+			{Name: "Stu Vwx", Value: "Stuvwx"},
 		}
-
-		if err := s.RespondInteraction(e.ID, e.Token, resp); err != nil {
-			log.Println("failed to send interaction callback:", err)
+		query := strings.ToLower(data.Focused.String())
+		var choices api.AutocompleteStringChoices
+		for _, choice := range allChoices {
+			if strings.HasPrefix(strings.ToLower(choice.Name), query) ||
+				strings.HasPrefix(strings.ToLower(choice.Value), query) {
+				choices = append(choices, choice)
+			}
 		}
+		return choices
 	})
 
+	s.AddInteractionHandler(r)
 	s.AddIntents(gateway.IntentGuilds)
 	s.AddIntents(gateway.IntentGuildMessages)
 
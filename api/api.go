@@ -5,11 +5,13 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/api/rate"
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/utils/httputil"
 	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 )
 
 var (
@@ -83,7 +85,19 @@ func (c *Client) InjectRequest(r httpdriver.Request) error {
 	})
 
 	ctx := c.AcquireOptions.Context(r.GetContext())
-	return c.Session.Limiter.Acquire(ctx, r.GetPath())
+
+	trace := instrument.RequestTraceFromContext(ctx)
+	if trace == nil {
+		return c.Session.Limiter.Acquire(ctx, r.GetPath())
+	}
+
+	trace.Bucket = c.Session.Limiter.BucketKey(r.GetPath())
+
+	start := time.Now()
+	err := c.Session.Limiter.Acquire(ctx, r.GetPath())
+	trace.RateLimitWait += time.Since(start)
+
+	return err
 }
 
 func (c *Client) OnResponse(r httpdriver.Request, resp httpdriver.Response) error {
@@ -3,7 +3,13 @@ package api
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 )
 
 func TestContext(t *testing.T) {
@@ -18,3 +24,77 @@ func TestContext(t *testing.T) {
 		t.Fatal("Unexpected error:", err)
 	}
 }
+
+func TestInstrumenter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint := EndpointChannels
+	EndpointChannels = srv.URL + "/channels/"
+	t.Cleanup(func() { EndpointChannels = endpoint })
+
+	var events []instrument.Event
+
+	client := NewClient("")
+	client.RetryPolicy = &httputil.BackoffPolicy{Min: time.Millisecond, Max: time.Millisecond}
+	client.Instrumenter = instrument.Func(func(ev instrument.Event) {
+		events = append(events, ev)
+	})
+
+	if _, err := client.Channel(1); err != nil {
+		t.Fatal("failed to get channel:", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %#v", len(events), events)
+	}
+
+	for i, ev := range events[:2] {
+		start, ok := ev.(instrument.RequestStart)
+		if !ok {
+			t.Fatalf("event %d: expected RequestStart, got %T", i, ev)
+		}
+		if start.Attempt != i || start.Bucket != "/channels/1" || start.Method != "GET" {
+			t.Errorf("event %d: unexpected %#v", i, start)
+		}
+	}
+
+	finish, ok := events[2].(instrument.RequestFinish)
+	if !ok {
+		t.Fatalf("expected RequestFinish, got %T", events[2])
+	}
+	if finish.Status != 200 || finish.Retries != 1 || finish.Bucket != "/channels/1" || finish.Err != nil {
+		t.Errorf("unexpected %#v", finish)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Unknown Message", "code": 10008}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint := EndpointChannels
+	EndpointChannels = srv.URL + "/channels/"
+	t.Cleanup(func() { EndpointChannels = endpoint })
+
+	_, err := NewClient("").Message(1, 2)
+	if !errors.Is(err, ErrUnknownMessage) {
+		t.Fatal("expected ErrUnknownMessage, got", err)
+	}
+	if errors.Is(err, ErrUnknownChannel) {
+		t.Fatal("unexpected ErrUnknownChannel")
+	}
+
+	if desc := ErrorDescription(ErrUnknownMessage); desc != "Unknown message" {
+		t.Fatalf("unexpected description %q", desc)
+	}
+}
@@ -0,0 +1,74 @@
+package apitest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api/rate"
+)
+
+// bucket is a fixed window rate limit bucket.
+type bucket struct {
+	remaining int
+	reset     time.Time
+}
+
+// rateLimit takes a request from the bucket of the given path and sets the
+// rate limit headers. If the bucket is exhausted, then false is returned with
+// a 429 Too Many Requests result. It must be called with the mutex acquired.
+func (s *Server) rateLimit(h http.Header, path string) (result, bool) {
+	if s.RateLimit < 0 {
+		return result{}, true
+	}
+
+	key := rate.ParseBucketKey(path)
+	now := time.Now()
+
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.reset) {
+		b = &bucket{
+			remaining: s.RateLimit,
+			reset:     now.Add(s.RateLimitReset),
+		}
+		s.buckets[key] = b
+	}
+
+	resetAfter := b.reset.Sub(now)
+
+	h.Set("X-RateLimit-Bucket", key)
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.RateLimit))
+	h.Set("X-RateLimit-Reset", formatSeconds(time.Duration(b.reset.UnixNano())))
+	h.Set("X-RateLimit-Reset-After", formatSeconds(resetAfter))
+
+	if b.remaining == 0 {
+		s.limited++
+
+		// Retry-After is in whole seconds, so round it up.
+		retryAfter := (resetAfter + time.Second - 1) / time.Second
+
+		h.Set("X-RateLimit-Remaining", "0")
+		h.Set("Retry-After", strconv.Itoa(int(retryAfter)))
+
+		return result{
+			status: http.StatusTooManyRequests,
+			body: struct {
+				Message    string  `json:"message"`
+				RetryAfter float64 `json:"retry_after"`
+				Global     bool    `json:"global"`
+			}{
+				Message:    "You are being rate limited.",
+				RetryAfter: resetAfter.Seconds(),
+			},
+		}, false
+	}
+
+	b.remaining--
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(b.remaining))
+
+	return result{}, true
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
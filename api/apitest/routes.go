@@ -0,0 +1,751 @@
+package apitest
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+// request is a request matched to a route.
+type request struct {
+	*http.Request
+	// ids contains the snowflakes in the path, in order.
+	ids []discord.Snowflake
+}
+
+// result is the result of a handler. It is written after the server is
+// unlocked, and its events are then dispatched.
+type result struct {
+	status int
+	body   interface{}
+	events []ws.Event
+}
+
+type errorBody struct {
+	Code    httputil.ErrorCode `json:"code"`
+	Message string             `json:"message"`
+}
+
+func errorResult(status int, code httputil.ErrorCode) result {
+	msg := api.ErrorDescription(code)
+	if msg == "" {
+		msg = strconv.Itoa(status) + ": " + http.StatusText(status)
+	}
+
+	return result{
+		status: status,
+		body:   errorBody{Code: code, Message: msg},
+	}
+}
+
+func jsonResult(body interface{}, events ...ws.Event) result {
+	return result{status: http.StatusOK, body: body, events: events}
+}
+
+func noContentResult(events ...ws.Event) result {
+	return result{status: http.StatusNoContent, events: events}
+}
+
+type route struct {
+	method string
+	// path contains the segments of the path, where "{}" matches a snowflake.
+	path   []string
+	handle func(s *Server, r request) result
+}
+
+func newRoute(method, path string, handle func(s *Server, r request) result) route {
+	return route{
+		method: method,
+		path:   strings.Split(strings.Trim(path, "/"), "/"),
+		handle: handle,
+	}
+}
+
+func (rt route) match(method string, path []string) ([]discord.Snowflake, bool) {
+	if rt.method != method || len(rt.path) != len(path) {
+		return nil, false
+	}
+
+	var ids []discord.Snowflake
+
+	for i, part := range rt.path {
+		if part != "{}" {
+			if part != path[i] {
+				return nil, false
+			}
+			continue
+		}
+
+		id, err := discord.ParseSnowflake(path[i])
+		if err != nil {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, true
+}
+
+var routes = []route{
+	newRoute("GET", "/users/@me", (*Server).me),
+
+	newRoute("GET", "/guilds/{}", (*Server).guild),
+	newRoute("GET", "/guilds/{}/channels", (*Server).guildChannels),
+	newRoute("POST", "/guilds/{}/channels", (*Server).createChannel),
+	newRoute("GET", "/guilds/{}/roles", (*Server).roles),
+	newRoute("POST", "/guilds/{}/roles", (*Server).createRole),
+	newRoute("PATCH", "/guilds/{}/roles/{}", (*Server).modifyRole),
+	newRoute("DELETE", "/guilds/{}/roles/{}", (*Server).deleteRole),
+	newRoute("GET", "/guilds/{}/members", (*Server).members),
+	newRoute("GET", "/guilds/{}/members/{}", (*Server).member),
+	newRoute("PUT", "/guilds/{}/members/{}/roles/{}", (*Server).addMemberRole),
+	newRoute("DELETE", "/guilds/{}/members/{}/roles/{}", (*Server).removeMemberRole),
+
+	newRoute("GET", "/channels/{}", (*Server).channel),
+	newRoute("DELETE", "/channels/{}", (*Server).deleteChannel),
+	newRoute("GET", "/channels/{}/messages", (*Server).messages),
+	newRoute("POST", "/channels/{}/messages", (*Server).sendMessage),
+	newRoute("POST", "/channels/{}/messages/bulk-delete", (*Server).deleteMessages),
+	newRoute("GET", "/channels/{}/messages/{}", (*Server).message),
+	newRoute("PATCH", "/channels/{}/messages/{}", (*Server).editMessage),
+	newRoute("DELETE", "/channels/{}/messages/{}", (*Server).deleteMessage),
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, api.Path)
+
+	res := s.handle(w, r, path)
+
+	if res.body != nil {
+		b, err := json.Marshal(res.body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(res.status)
+		w.Write(b)
+	} else {
+		w.WriteHeader(res.status)
+	}
+
+	s.dispatch(res.events...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, path string) result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Token != "" && r.Header.Get("Authorization") != s.Token {
+		return errorResult(http.StatusUnauthorized, 0)
+	}
+
+	if res, ok := s.rateLimit(w.Header(), path); !ok {
+		return res
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, rt := range routes {
+		if ids, ok := rt.match(r.Method, parts); ok {
+			return rt.handle(s, request{Request: r, ids: ids})
+		}
+	}
+
+	return errorResult(http.StatusNotFound, 0)
+}
+
+// decode decodes the JSON body of the request. Multipart bodies are also
+// accepted, in which case the files are returned.
+func (r request) decode(v interface{}) ([]discord.Attachment, error) {
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, json.DecodeStream(r.Body, v)
+	}
+
+	var files []discord.Attachment
+	var found bool
+
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if part.FormName() == "payload_json" {
+			if err := json.DecodeStream(part, v); err != nil {
+				return nil, err
+			}
+			found = true
+			continue
+		}
+
+		if name := part.FileName(); name != "" {
+			n, _ := io.Copy(io.Discard, part)
+			files = append(files, discord.Attachment{
+				Filename: name,
+				Size:     uint64(n),
+			})
+		}
+	}
+
+	if !found {
+		return nil, errors.New("missing payload_json")
+	}
+
+	return files, nil
+}
+
+func (s *Server) me(r request) result {
+	return jsonResult(s.User)
+}
+
+func (s *Server) guild(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+	return jsonResult(g.Guild)
+}
+
+func (s *Server) guildChannels(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	channels := make([]discord.Channel, 0, len(g.channels))
+	for _, id := range g.channels {
+		channels = append(channels, s.channels[id].Channel)
+	}
+	return jsonResult(channels)
+}
+
+func (s *Server) createChannel(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	var data api.CreateChannelData
+	if _, err := r.decode(&data); err != nil || data.Name == "" {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	ch := discord.Channel{
+		ID:             discord.ChannelID(s.newID()),
+		GuildID:        g.ID,
+		Name:           data.Name,
+		Type:           data.Type,
+		Topic:          data.Topic,
+		Flags:          data.Flags,
+		VoiceBitrate:   data.VoiceBitrate,
+		VoiceUserLimit: data.VoiceUserLimit,
+		UserRateLimit:  data.UserRateLimit,
+		Overwrites:     data.Overwrites,
+		ParentID:       data.CategoryID,
+		NSFW:           data.NSFW,
+	}
+	if data.Position != nil {
+		ch.Position = *data.Position
+	}
+
+	s.channels[ch.ID] = &channel{Channel: ch}
+	g.channels = append(g.channels, ch.ID)
+
+	res := jsonResult(ch, &gateway.ChannelCreateEvent{Channel: ch})
+	res.status = http.StatusCreated
+	return res
+}
+
+func (s *Server) roles(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+	return jsonResult(g.Roles)
+}
+
+func (s *Server) createRole(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	var data api.CreateRoleData
+	if _, err := r.decode(&data); err != nil {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	role := discord.Role{
+		ID:          discord.RoleID(s.newID()),
+		Name:        data.Name,
+		Permissions: data.Permissions,
+		Color:       data.Color,
+		Hoist:       data.Hoist,
+		Mentionable: data.Mentionable,
+		Position:    len(g.Roles),
+	}
+	if role.Name == "" {
+		role.Name = "new role"
+	}
+
+	g.Roles = append(g.Roles, role)
+
+	return jsonResult(role, &gateway.GuildRoleCreateEvent{GuildID: g.ID, Role: role})
+}
+
+func (s *Server) modifyRole(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	i := g.roleIndex(discord.RoleID(r.ids[1]))
+	if i == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownRole)
+	}
+
+	var data api.ModifyRoleData
+	if _, err := r.decode(&data); err != nil {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	role := &g.Roles[i]
+	if data.Name != nil {
+		role.Name = data.Name.Val
+	}
+	if data.Permissions != nil {
+		role.Permissions = *data.Permissions
+	}
+	if data.Color != 0 {
+		role.Color = data.Color
+	}
+	if data.Hoist != nil {
+		role.Hoist = data.Hoist.Val
+	}
+	if data.Mentionable != nil {
+		role.Mentionable = data.Mentionable.Val
+	}
+
+	return jsonResult(*role, &gateway.GuildRoleUpdateEvent{GuildID: g.ID, Role: *role})
+}
+
+func (s *Server) deleteRole(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	roleID := discord.RoleID(r.ids[1])
+
+	i := g.roleIndex(roleID)
+	if i == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownRole)
+	}
+
+	g.Roles = append(g.Roles[:i], g.Roles[i+1:]...)
+
+	var events []ws.Event
+	for _, m := range g.members {
+		if j := roleIDIndex(m.RoleIDs, roleID); j != -1 {
+			m.RoleIDs = append(m.RoleIDs[:j], m.RoleIDs[j+1:]...)
+		}
+	}
+
+	events = append(events, &gateway.GuildRoleDeleteEvent{GuildID: g.ID, RoleID: roleID})
+	return noContentResult(events...)
+}
+
+func (g *guild) roleIndex(id discord.RoleID) int {
+	for i, role := range g.Roles {
+		if role.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func roleIDIndex(ids []discord.RoleID, id discord.RoleID) int {
+	for i, roleID := range ids {
+		if roleID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) members(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	query := r.URL.Query()
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 1000 {
+		limit = 1
+	}
+
+	after, _ := discord.ParseSnowflake(query.Get("after"))
+
+	members := make([]discord.Member, 0, limit)
+	for _, m := range g.sortedMembers() {
+		if len(members) == limit {
+			break
+		}
+		if discord.Snowflake(m.User.ID) > after {
+			members = append(members, m)
+		}
+	}
+
+	return jsonResult(members)
+}
+
+func (s *Server) member(r request) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	m, ok := g.members[discord.UserID(r.ids[1])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownMember)
+	}
+
+	return jsonResult(m)
+}
+
+func (s *Server) addMemberRole(r request) result {
+	return s.updateMemberRole(r, true)
+}
+
+func (s *Server) removeMemberRole(r request) result {
+	return s.updateMemberRole(r, false)
+}
+
+func (s *Server) updateMemberRole(r request, add bool) result {
+	g, ok := s.guilds[discord.GuildID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownGuild)
+	}
+
+	m, ok := g.members[discord.UserID(r.ids[1])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownMember)
+	}
+
+	roleID := discord.RoleID(r.ids[2])
+	if g.roleIndex(roleID) == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownRole)
+	}
+
+	i := roleIDIndex(m.RoleIDs, roleID)
+	switch {
+	case add && i == -1:
+		m.RoleIDs = append(m.RoleIDs, roleID)
+	case !add && i != -1:
+		m.RoleIDs = append(m.RoleIDs[:i], m.RoleIDs[i+1:]...)
+	default:
+		// Nothing changed, so there's no event.
+		return noContentResult()
+	}
+
+	return noContentResult(&gateway.GuildMemberUpdateEvent{
+		GuildID: g.ID,
+		RoleIDs: append([]discord.RoleID(nil), m.RoleIDs...),
+		User:    m.User,
+		Nick:    m.Nick,
+		Avatar:  m.Avatar,
+	})
+}
+
+func (s *Server) channel(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+	return jsonResult(ch.Channel)
+}
+
+func (s *Server) deleteChannel(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	delete(s.channels, ch.ID)
+
+	if g, ok := s.guilds[ch.GuildID]; ok {
+		for i, id := range g.channels {
+			if id == ch.ID {
+				g.channels = append(g.channels[:i], g.channels[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return jsonResult(ch.Channel, &gateway.ChannelDeleteEvent{Channel: ch.Channel})
+}
+
+func (s *Server) messages(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	query := r.URL.Query()
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	before, _ := discord.ParseSnowflake(query.Get("before"))
+	after, _ := discord.ParseSnowflake(query.Get("after"))
+
+	var msgs []discord.Message
+
+	if after.IsValid() {
+		// Take the oldest messages after the given ID.
+		for _, m := range ch.messages {
+			if len(msgs) == limit {
+				break
+			}
+			if discord.Snowflake(m.ID) > after {
+				msgs = append(msgs, m)
+			}
+		}
+		reverseMessages(msgs)
+	} else {
+		// Take the latest messages, optionally before the given ID.
+		for i := len(ch.messages) - 1; i >= 0 && len(msgs) < limit; i-- {
+			if !before.IsValid() || discord.Snowflake(ch.messages[i].ID) < before {
+				msgs = append(msgs, ch.messages[i])
+			}
+		}
+	}
+
+	if msgs == nil {
+		msgs = []discord.Message{}
+	}
+
+	return jsonResult(msgs)
+}
+
+func reverseMessages(msgs []discord.Message) {
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+}
+
+func (ch *channel) messageIndex(id discord.MessageID) int {
+	for i, m := range ch.messages {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) message(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	i := ch.messageIndex(discord.MessageID(r.ids[1]))
+	if i == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownMessage)
+	}
+
+	return jsonResult(ch.messages[i])
+}
+
+// selfMember returns the member of the current user in the guild, if any.
+func (s *Server) selfMember(guildID discord.GuildID) *discord.Member {
+	g, ok := s.guilds[guildID]
+	if !ok {
+		return nil
+	}
+
+	m, ok := g.members[s.User.ID]
+	if !ok {
+		return nil
+	}
+
+	member := *m
+	return &member
+}
+
+func (s *Server) sendMessage(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	var data api.SendMessageData
+
+	files, err := r.decode(&data)
+	if err != nil {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	if data.Content == "" && len(data.Embeds) == 0 && len(files) == 0 {
+		return errorResult(http.StatusBadRequest, api.ErrCannotSendEmptyMessage)
+	}
+
+	for i := range files {
+		files[i].ID = discord.AttachmentID(s.newID())
+	}
+
+	m := s.addMessage(ch, discord.Message{
+		Type:        discord.DefaultMessage,
+		Author:      s.User,
+		Content:     data.Content,
+		Nonce:       data.Nonce,
+		TTS:         data.TTS,
+		Embeds:      data.Embeds,
+		Components:  data.Components,
+		Reference:   data.Reference,
+		Attachments: files,
+	})
+	if m.Reference != nil {
+		m.Type = discord.InlinedReplyMessage
+		ch.messages[len(ch.messages)-1] = m
+	}
+
+	return jsonResult(m, &gateway.MessageCreateEvent{
+		Message: m,
+		Member:  s.selfMember(ch.GuildID),
+	})
+}
+
+func (s *Server) editMessage(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	i := ch.messageIndex(discord.MessageID(r.ids[1]))
+	if i == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownMessage)
+	}
+
+	var data api.EditMessageData
+	if _, err := r.decode(&data); err != nil {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	m := &ch.messages[i]
+
+	if m.Author.ID != s.User.ID && (data.Content != nil || data.Embeds != nil) {
+		return errorResult(http.StatusForbidden, api.ErrCannotEditMessageByOtherUser)
+	}
+
+	if data.Content != nil {
+		m.Content = data.Content.Val
+	}
+	if data.Embeds != nil {
+		m.Embeds = *data.Embeds
+	}
+	if data.Components != nil {
+		m.Components = *data.Components
+	}
+	if data.Flags != nil {
+		m.Flags = *data.Flags
+	}
+	m.EditedTimestamp = discord.NowTimestamp()
+
+	return jsonResult(*m, &gateway.MessageUpdateEvent{
+		Message: *m,
+		Member:  s.selfMember(ch.GuildID),
+	})
+}
+
+func (s *Server) deleteMessage(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	id := discord.MessageID(r.ids[1])
+
+	i := ch.messageIndex(id)
+	if i == -1 {
+		return errorResult(http.StatusNotFound, api.ErrUnknownMessage)
+	}
+
+	ch.messages = append(ch.messages[:i], ch.messages[i+1:]...)
+
+	return noContentResult(&gateway.MessageDeleteEvent{
+		ID:        id,
+		ChannelID: ch.ID,
+		GuildID:   ch.GuildID,
+	})
+}
+
+// bulkDeleteMaxAge is the maximum age of messages that can be bulk deleted.
+const bulkDeleteMaxAge = 14 * 24 * time.Hour
+
+func (s *Server) deleteMessages(r request) result {
+	ch, ok := s.channels[discord.ChannelID(r.ids[0])]
+	if !ok {
+		return errorResult(http.StatusNotFound, api.ErrUnknownChannel)
+	}
+
+	var data struct {
+		Messages []discord.MessageID `json:"messages"`
+	}
+
+	if _, err := r.decode(&data); err != nil {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidFormBody)
+	}
+
+	if len(data.Messages) < 2 || len(data.Messages) > 100 {
+		return errorResult(http.StatusBadRequest, api.ErrInvalidMessageDeleteCount)
+	}
+
+	for _, id := range data.Messages {
+		if time.Since(id.Time()) > bulkDeleteMaxAge {
+			return errorResult(http.StatusBadRequest, api.ErrMessageTooOldToBulkDelete)
+		}
+	}
+
+	var deleted []discord.MessageID
+	for _, id := range data.Messages {
+		if i := ch.messageIndex(id); i != -1 {
+			ch.messages = append(ch.messages[:i], ch.messages[i+1:]...)
+			deleted = append(deleted, id)
+		}
+	}
+
+	if len(deleted) == 0 {
+		return noContentResult()
+	}
+
+	return noContentResult(&gateway.MessageDeleteBulkEvent{
+		IDs:       deleted,
+		ChannelID: ch.ID,
+		GuildID:   ch.GuildID,
+	})
+}
@@ -0,0 +1,422 @@
+// Package apitest provides a fake Discord REST API server for integration
+// tests. It keeps guilds, channels, messages, members and roles in memory, so
+// that requests such as SendMessage, EditMessage, DeleteMessages or AddRole
+// have real effects, and it rate limits requests with the same headers as
+// Discord, so that the rate limiter of api.Client is exercised.
+//
+// Usage
+//
+// The server is seeded with resources, and clients created by Client send
+// their requests to the server instead of Discord:
+//
+//    srv := apitest.NewServer()
+//    defer srv.Close()
+//
+//    ch := srv.AddChannel(discord.Channel{Name: "general"})
+//
+//    c := srv.Client("Bot token")
+//    c.SendMessage(ch.ID, "hello")
+//
+//    msgs := srv.Messages(ch.ID)
+//
+// If a gateway is set, then every change is also dispatched as a gateway event,
+// so that a session.Session or state.State connected to a gatewaytest.Server
+// sees its own changes like it would with Discord:
+//
+//    gw := gatewaytest.NewServer()
+//    srv.SetGateway(gw)
+//
+//    s := session.NewWithGateway(gw.NewGateway(id), handler.New())
+//    s.Client = srv.Client(token)
+//
+package apitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+// Dispatcher sends dispatch events to gateway clients. It is implemented by
+// *gatewaytest.Server.
+type Dispatcher interface {
+	Dispatch(ev ws.Event) error
+}
+
+// DefaultRateLimit is the number of requests allowed per bucket in every
+// DefaultRateLimitReset if the Server doesn't have its own limits.
+var (
+	DefaultRateLimit      = 5
+	DefaultRateLimitReset = time.Second
+)
+
+// Server is a fake Discord REST API server. The options must be set before
+// Start is called.
+type Server struct {
+	// URL is the base URL of the server, such as http://127.0.0.1:1234.
+	URL string
+
+	// Token, if not empty, is the only token that is accepted. Requests with
+	// any other token fail with 401 Unauthorized.
+	Token string
+	// User is the current user. It is the author of all messages sent through
+	// the server.
+	User discord.User
+	// RateLimit is the number of requests allowed per bucket in every
+	// RateLimitReset. If it's 0, then DefaultRateLimit is used. If it's
+	// negative, then requests are never rate limited.
+	RateLimit      int
+	RateLimitReset time.Duration
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	gateway  Dispatcher
+	lastID   discord.Snowflake
+	guilds   map[discord.GuildID]*guild
+	channels map[discord.ChannelID]*channel
+	buckets  map[string]*bucket
+	limited  int
+}
+
+type guild struct {
+	discord.Guild
+	channels []discord.ChannelID
+	members  map[discord.UserID]*discord.Member
+}
+
+type channel struct {
+	discord.Channel
+	// messages is sorted by ID.
+	messages []discord.Message
+}
+
+// NewServer creates and starts a new Server.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer creates a new Server that isn't started. Start must be
+// called once its options are set.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		User: discord.User{
+			ID:       1,
+			Username: "apitest",
+			Bot:      true,
+		},
+		guilds:   make(map[discord.GuildID]*guild),
+		channels: make(map[discord.ChannelID]*channel),
+		buckets:  make(map[string]*bucket),
+	}
+	s.srv = httptest.NewUnstartedServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Start starts the server.
+func (s *Server) Start() {
+	if s.RateLimit == 0 {
+		s.RateLimit = DefaultRateLimit
+	}
+	if s.RateLimitReset == 0 {
+		s.RateLimitReset = DefaultRateLimitReset
+	}
+
+	s.srv.Start()
+	s.URL = s.srv.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client creates a new api.Client that sends all of its requests to the server
+// instead of Discord.
+func (s *Server) Client(token string) *api.Client {
+	u, _ := url.Parse(s.URL)
+
+	client := httputil.NewClient()
+	client.Client = httpdriver.WrapClient(http.Client{
+		Transport: redirectTransport{url: u, rt: s.srv.Client().Transport},
+		Timeout:   10 * time.Second,
+	})
+
+	return api.NewCustomClient(token, client)
+}
+
+// redirectTransport sends all requests to the server, keeping their paths.
+type redirectTransport struct {
+	url *url.URL
+	rt  http.RoundTripper
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.url.Scheme
+	r.URL.Host = t.url.Host
+	r.Host = t.url.Host
+	return t.rt.RoundTrip(r)
+}
+
+// RateLimited returns the number of requests that were rejected with 429 Too
+// Many Requests.
+func (s *Server) RateLimited() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limited
+}
+
+// newID returns a new unique snowflake. Snowflakes are increasing, so newer
+// messages always have greater IDs.
+func (s *Server) newID() discord.Snowflake {
+	id := discord.NewSnowflake(time.Now())
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	s.lastID = id
+	return id
+}
+
+// SetGateway sets the gateway that receives a dispatch event for every change.
+// If it's nil, then changes aren't dispatched. It may be called at any time.
+func (s *Server) SetGateway(d Dispatcher) {
+	s.mu.Lock()
+	s.gateway = d
+	s.mu.Unlock()
+}
+
+// dispatch sends the given events to the gateway, if any. It must not be
+// called with the mutex acquired.
+func (s *Server) dispatch(events ...ws.Event) {
+	s.mu.Lock()
+	d := s.gateway
+	s.mu.Unlock()
+
+	if d == nil {
+		return
+	}
+
+	for _, ev := range events {
+		d.Dispatch(ev)
+	}
+}
+
+// AddGuild adds the given guild. It is given an ID if it doesn't have one, and
+// an @everyone role if it has no roles. Its channels are set by AddChannel.
+func (s *Server) AddGuild(g discord.Guild) discord.Guild {
+	s.mu.Lock()
+
+	if !g.ID.IsValid() {
+		g.ID = discord.GuildID(s.newID())
+	}
+	if len(g.Roles) == 0 {
+		g.Roles = []discord.Role{{ID: discord.RoleID(g.ID), Name: "@everyone"}}
+	}
+	if !g.OwnerID.IsValid() {
+		g.OwnerID = s.User.ID
+	}
+
+	s.guilds[g.ID] = &guild{
+		Guild:   g,
+		members: make(map[discord.UserID]*discord.Member),
+	}
+
+	ev := s.guildCreateEvent(s.guilds[g.ID])
+	s.mu.Unlock()
+
+	s.dispatch(ev)
+	return g
+}
+
+// AddChannel adds the given channel. It is given an ID if it doesn't have one.
+// If it has a guild ID, then the guild must have been added.
+func (s *Server) AddChannel(ch discord.Channel) discord.Channel {
+	s.mu.Lock()
+
+	if !ch.ID.IsValid() {
+		ch.ID = discord.ChannelID(s.newID())
+	}
+
+	s.channels[ch.ID] = &channel{Channel: ch}
+	if g, ok := s.guilds[ch.GuildID]; ok {
+		g.channels = append(g.channels, ch.ID)
+	}
+
+	s.mu.Unlock()
+
+	s.dispatch(&gateway.ChannelCreateEvent{Channel: ch})
+	return ch
+}
+
+// AddMember adds the given member to the guild, which must have been added.
+func (s *Server) AddMember(guildID discord.GuildID, m discord.Member) discord.Member {
+	s.mu.Lock()
+
+	if g, ok := s.guilds[guildID]; ok {
+		if !m.Joined.IsValid() {
+			m.Joined = discord.NowTimestamp()
+		}
+		g.members[m.User.ID] = &m
+	}
+
+	s.mu.Unlock()
+
+	s.dispatch(&gateway.GuildMemberAddEvent{Member: m, GuildID: guildID})
+	return m
+}
+
+// AddRole adds the given role to the guild, which must have been added. It is
+// given an ID if it doesn't have one.
+func (s *Server) AddRole(guildID discord.GuildID, r discord.Role) discord.Role {
+	s.mu.Lock()
+
+	if !r.ID.IsValid() {
+		r.ID = discord.RoleID(s.newID())
+	}
+	if g, ok := s.guilds[guildID]; ok {
+		g.Roles = append(g.Roles, r)
+	}
+
+	s.mu.Unlock()
+
+	s.dispatch(&gateway.GuildRoleCreateEvent{GuildID: guildID, Role: r})
+	return r
+}
+
+// AddMessage adds the given message, as if it was sent by its author. The
+// channel must have been added. The message is given an ID if it doesn't have
+// one, which must be greater than the IDs of all messages in the channel.
+func (s *Server) AddMessage(m discord.Message) discord.Message {
+	s.mu.Lock()
+
+	ch, ok := s.channels[m.ChannelID]
+	if ok {
+		m = s.addMessage(ch, m)
+	}
+
+	s.mu.Unlock()
+
+	s.dispatch(&gateway.MessageCreateEvent{Message: m})
+	return m
+}
+
+func (s *Server) addMessage(ch *channel, m discord.Message) discord.Message {
+	if !m.ID.IsValid() {
+		m.ID = discord.MessageID(s.newID())
+	}
+	if !m.Timestamp.IsValid() {
+		m.Timestamp = discord.NewTimestamp(m.ID.Time())
+	}
+	m.ChannelID = ch.ID
+	m.GuildID = ch.GuildID
+
+	ch.messages = append(ch.messages, m)
+	ch.LastMessageID = m.ID
+
+	return m
+}
+
+// Guild returns the guild with the given ID.
+func (s *Server) Guild(id discord.GuildID) (discord.Guild, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.guilds[id]
+	if !ok {
+		return discord.Guild{}, false
+	}
+
+	guild := g.Guild
+	guild.Roles = append([]discord.Role(nil), g.Roles...)
+	return guild, true
+}
+
+// Channel returns the channel with the given ID.
+func (s *Server) Channel(id discord.ChannelID) (discord.Channel, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[id]
+	if !ok {
+		return discord.Channel{}, false
+	}
+	return ch.Channel, true
+}
+
+// Messages returns the messages in the channel from oldest to latest.
+func (s *Server) Messages(channelID discord.ChannelID) []discord.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[channelID]
+	if !ok {
+		return nil
+	}
+	return append([]discord.Message(nil), ch.messages...)
+}
+
+// Member returns the member of the guild with the given user ID.
+func (s *Server) Member(guildID discord.GuildID, userID discord.UserID) (discord.Member, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.guilds[guildID]
+	if !ok {
+		return discord.Member{}, false
+	}
+
+	m, ok := g.members[userID]
+	if !ok {
+		return discord.Member{}, false
+	}
+
+	member := *m
+	member.RoleIDs = append([]discord.RoleID(nil), m.RoleIDs...)
+	return member, true
+}
+
+// sortedMembers returns the members of the guild sorted by user ID.
+func (g *guild) sortedMembers() []discord.Member {
+	members := make([]discord.Member, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, *m)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].User.ID < members[j].User.ID
+	})
+
+	return members
+}
+
+// guildCreateEvent returns the Guild Create event of the guild, which contains
+// its channels and members.
+func (s *Server) guildCreateEvent(g *guild) *gateway.GuildCreateEvent {
+	ev := &gateway.GuildCreateEvent{
+		Guild:   g.Guild,
+		Joined:  discord.NowTimestamp(),
+		Members: g.sortedMembers(),
+	}
+	ev.Roles = append([]discord.Role(nil), g.Roles...)
+	ev.MemberCount = uint64(len(g.members))
+
+	for _, id := range g.channels {
+		ev.Channels = append(ev.Channels, s.channels[id].Channel)
+	}
+
+	return ev
+}
@@ -0,0 +1,268 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/gateway/gatewaytest"
+	"github.com/diamondburned/arikawa/v3/session"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+)
+
+func newServer(t *testing.T) *Server {
+	srv := NewUnstartedServer()
+	srv.Token = "Bot token"
+	srv.RateLimit = -1
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func expectCode(t *testing.T, err error, code httputil.ErrorCode) {
+	t.Helper()
+
+	var httpErr *httputil.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != code {
+		t.Fatalf("expected error code %d, got %v", code, err)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	srv := newServer(t)
+	c := srv.Client("Bot token")
+
+	g := srv.AddGuild(discord.Guild{Name: "guild"})
+	ch := srv.AddChannel(discord.Channel{GuildID: g.ID, Name: "general"})
+
+	other := srv.AddMessage(discord.Message{
+		ChannelID: ch.ID,
+		Author:    discord.User{ID: 2, Username: "other"},
+		Content:   "hi",
+	})
+
+	msg, err := c.SendMessage(ch.ID, "hello")
+	if err != nil {
+		t.Fatal("failed to send message:", err)
+	}
+	if msg.Content != "hello" || msg.Author.ID != srv.User.ID || msg.GuildID != g.ID {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+
+	// SendMessage doesn't send empty messages, so bypass its validation.
+	err = c.FastRequest(
+		"POST", api.EndpointChannels+ch.ID.String()+"/messages",
+		httputil.WithJSONBody(api.SendMessageData{}),
+	)
+	expectCode(t, err, api.ErrCannotSendEmptyMessage)
+
+	edited, err := c.EditMessage(ch.ID, msg.ID, "hello, world")
+	if err != nil {
+		t.Fatal("failed to edit message:", err)
+	}
+	if edited.Content != "hello, world" || !edited.EditedTimestamp.IsValid() {
+		t.Fatalf("unexpected edited message %#v", edited)
+	}
+
+	_, err = c.EditMessage(ch.ID, other.ID, "mine now")
+	expectCode(t, err, api.ErrCannotEditMessageByOtherUser)
+
+	msgs, err := c.Messages(ch.ID, 0)
+	if err != nil {
+		t.Fatal("failed to get messages:", err)
+	}
+	if len(msgs) != 2 || msgs[0].ID != msg.ID || msgs[0].Content != "hello, world" {
+		t.Fatalf("unexpected messages %#v", msgs)
+	}
+
+	err = c.DeleteMessages(ch.ID, []discord.MessageID{other.ID, msg.ID}, "")
+	if err != nil {
+		t.Fatal("failed to delete messages:", err)
+	}
+
+	if msgs := srv.Messages(ch.ID); len(msgs) != 0 {
+		t.Fatalf("messages were not deleted: %#v", msgs)
+	}
+
+	_, err = c.Message(ch.ID, msg.ID)
+	expectCode(t, err, api.ErrUnknownMessage)
+
+	_, err = c.SendMessage(ch.ID+1, "hello")
+	expectCode(t, err, api.ErrUnknownChannel)
+}
+
+func TestMessagesPagination(t *testing.T) {
+	srv := newServer(t)
+	c := srv.Client("Bot token")
+
+	ch := srv.AddChannel(discord.Channel{Name: "general"})
+
+	var ids []discord.MessageID
+	for i := 0; i < 250; i++ {
+		ids = append(ids, srv.AddMessage(discord.Message{ChannelID: ch.ID}).ID)
+	}
+
+	msgs, err := c.Messages(ch.ID, 0)
+	if err != nil {
+		t.Fatal("failed to get messages:", err)
+	}
+	if len(msgs) != 250 {
+		t.Fatalf("expected 250 messages, got %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.ID != ids[len(ids)-1-i] {
+			t.Fatalf("message %d is out of order", i)
+		}
+	}
+
+	msgs, err = c.MessagesAfter(ch.ID, ids[99], 120)
+	if err != nil {
+		t.Fatal("failed to get messages after:", err)
+	}
+	if len(msgs) != 120 || msgs[0].ID != ids[219] || msgs[119].ID != ids[100] {
+		t.Fatalf("unexpected messages after: got %d", len(msgs))
+	}
+
+	msgs, err = c.MessagesBefore(ch.ID, ids[10], 0)
+	if err != nil {
+		t.Fatal("failed to get messages before:", err)
+	}
+	if len(msgs) != 10 || msgs[0].ID != ids[9] {
+		t.Fatalf("unexpected messages before: got %d", len(msgs))
+	}
+}
+
+func TestRoles(t *testing.T) {
+	srv := newServer(t)
+	c := srv.Client("Bot token")
+
+	g := srv.AddGuild(discord.Guild{Name: "guild"})
+	user := discord.User{ID: 2, Username: "member"}
+	srv.AddMember(g.ID, discord.Member{User: user})
+
+	role, err := c.CreateRole(g.ID, api.CreateRoleData{Name: "role"})
+	if err != nil {
+		t.Fatal("failed to create role:", err)
+	}
+
+	if err := c.AddRole(g.ID, user.ID, role.ID, api.AddRoleData{}); err != nil {
+		t.Fatal("failed to add role:", err)
+	}
+
+	m, err := c.Member(g.ID, user.ID)
+	if err != nil {
+		t.Fatal("failed to get member:", err)
+	}
+	if len(m.RoleIDs) != 1 || m.RoleIDs[0] != role.ID {
+		t.Fatalf("unexpected roles %v", m.RoleIDs)
+	}
+
+	if err := c.RemoveRole(g.ID, user.ID, role.ID, ""); err != nil {
+		t.Fatal("failed to remove role:", err)
+	}
+
+	if m, _ := srv.Member(g.ID, user.ID); len(m.RoleIDs) != 0 {
+		t.Fatalf("role was not removed: %v", m.RoleIDs)
+	}
+
+	err = c.AddRole(g.ID, user.ID, role.ID+1, api.AddRoleData{})
+	expectCode(t, err, api.ErrUnknownRole)
+
+	for i := discord.UserID(3); i < 10; i++ {
+		srv.AddMember(g.ID, discord.Member{User: discord.User{ID: i}})
+	}
+
+	members, err := c.MembersAfter(g.ID, 4, 0)
+	if err != nil {
+		t.Fatal("failed to get members:", err)
+	}
+	if len(members) != 5 || members[0].User.ID != 5 {
+		t.Fatalf("unexpected members %#v", members)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := newServer(t)
+
+	_, err := srv.Client("Bot bad token").Me()
+
+	var httpErr *httputil.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != 401 {
+		t.Fatal("expected 401, got", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := NewUnstartedServer()
+	srv.RateLimit = 2
+	srv.RateLimitReset = 500 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	c := srv.Client("Bot token")
+	ch := srv.AddChannel(discord.Channel{Name: "general"})
+
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.SendMessage(ch.ID, "hello"); err != nil {
+			t.Fatal("failed to send message:", err)
+		}
+	}
+
+	// 5 requests with 2 per window take at least 2 resets.
+	if since := time.Since(start); since < time.Second {
+		t.Errorf("requests were not rate limited, took %v", since)
+	}
+
+	if n := srv.RateLimited(); n != 0 {
+		t.Errorf("client was rate limited %d times", n)
+	}
+}
+
+func TestGatewayDispatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	gw := gatewaytest.NewServer()
+	t.Cleanup(gw.Close)
+
+	srv := newServer(t)
+	srv.SetGateway(gw)
+
+	s := session.NewWithGateway(gw.NewGateway(gateway.DefaultIdentifier("Bot token")), handler.New())
+	s.Client = srv.Client("Bot token")
+	t.Cleanup(func() { s.Close() })
+
+	messages := make(chan *gateway.MessageCreateEvent, 1)
+	s.AddHandler(messages)
+
+	if err := s.Open(ctx); err != nil {
+		t.Fatal("failed to open:", err)
+	}
+
+	if _, err := gw.Accept(ctx); err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	ch := srv.AddChannel(discord.Channel{Name: "general"})
+
+	sent, err := s.SendMessage(ch.ID, "hello")
+	if err != nil {
+		t.Fatal("failed to send message:", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.ID != sent.ID || msg.Content != "hello" {
+			t.Fatalf("unexpected message %#v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for message")
+	}
+}
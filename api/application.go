@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"net/url"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/utils/httputil"
@@ -32,6 +33,24 @@ type CreateCommandData struct {
 	Type                     discord.CommandType    `json:"type,omitempty"`
 }
 
+// NewCreateCommandData creates a new chat input command with the options
+// derived from the given struct, which is usually the same struct that the
+// options are unmarshaled into. See discord.MarshalCommandOptions for the
+// supported struct tags.
+func NewCreateCommandData(name, description string, v interface{}) (CreateCommandData, error) {
+	options, err := discord.MarshalCommandOptions(v)
+	if err != nil {
+		return CreateCommandData{}, err
+	}
+
+	return CreateCommandData{
+		Type:        discord.ChatInputCommand,
+		Name:        name,
+		Description: description,
+		Options:     options,
+	}, nil
+}
+
 func (c CreateCommandData) MarshalJSON() ([]byte, error) {
 	type RawCreateCommandData CreateCommandData
 	cmd := struct {
@@ -82,6 +101,20 @@ func (c *Client) Commands(appID discord.AppID) ([]discord.Command, error) {
 	)
 }
 
+// CommandsWithLocalizations returns the global commands of the application
+// with all of their localizations, instead of only the ones of the locale of
+// the client.
+func (c *Client) CommandsWithLocalizations(appID discord.AppID) ([]discord.Command, error) {
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(
+		&cmds, "GET",
+		EndpointApplications+appID.String()+"/commands",
+		httputil.WithSchema(c, url.Values{
+			"with_localizations": {"true"},
+		}),
+	)
+}
+
 func (c *Client) Command(
 	appID discord.AppID, commandID discord.CommandID) (*discord.Command, error) {
 
@@ -148,6 +181,22 @@ func (c *Client) GuildCommands(
 	)
 }
 
+// GuildCommandsWithLocalizations returns the commands of the application in
+// the guild with all of their localizations, instead of only the ones of the
+// locale of the client.
+func (c *Client) GuildCommandsWithLocalizations(
+	appID discord.AppID, guildID discord.GuildID) ([]discord.Command, error) {
+
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(
+		&cmds, "GET",
+		EndpointApplications+appID.String()+"/guilds/"+guildID.String()+"/commands",
+		httputil.WithSchema(c, url.Values{
+			"with_localizations": {"true"},
+		}),
+	)
+}
+
 func (c *Client) GuildCommand(
 	appID discord.AppID,
 	guildID discord.GuildID, commandID discord.CommandID) (*discord.Command, error) {
@@ -0,0 +1,203 @@
+package cmdroute
+
+import (
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// CommandData is the data of a routed application command.
+type CommandData struct {
+	// Event is the interaction event of the command.
+	Event *discord.InteractionEvent
+	// Data is the command interaction data.
+	Data *discord.CommandInteraction
+	// Name is the full path of the command, such as "admin ban".
+	Name string
+	// Options are the options of the innermost subcommand, or of the command
+	// itself if it has no subcommands.
+	Options discord.CommandInteractionOptions
+}
+
+// CommandHandler handles a routed application command.
+type CommandHandler interface {
+	// HandleCommand handles the command and returns a response. A nil response
+	// means that no response is sent.
+	HandleCommand(cmd CommandData) *api.InteractionResponse
+}
+
+// CommandHandlerFunc is a function that implements CommandHandler.
+type CommandHandlerFunc func(cmd CommandData) *api.InteractionResponse
+
+var _ CommandHandler = CommandHandlerFunc(nil)
+
+// HandleCommand implements CommandHandler.
+func (f CommandHandlerFunc) HandleCommand(cmd CommandData) *api.InteractionResponse {
+	return f(cmd)
+}
+
+type commandHandler struct{ CommandHandler }
+
+func (h commandHandler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	data := ev.Data.(*discord.CommandInteraction)
+	name, opts := commandPath(data.Name, data.Options)
+
+	return h.HandleCommand(CommandData{
+		Event:   ev,
+		Data:    data,
+		Name:    name,
+		Options: opts,
+	})
+}
+
+// AutocompleteData is the data of a routed autocompletion.
+type AutocompleteData struct {
+	// Event is the interaction event of the autocompletion.
+	Event *discord.InteractionEvent
+	// Data is the autocomplete interaction data.
+	Data *discord.AutocompleteInteraction
+	// Name is the full path of the command, such as "admin ban".
+	Name string
+	// Focused is the option that is being autocompleted.
+	Focused discord.AutocompleteOption
+}
+
+// Autocompleter autocompletes an option of an application command.
+type Autocompleter interface {
+	// Autocomplete returns the choices for the focused option. A nil value
+	// means that no response is sent.
+	Autocomplete(data AutocompleteData) api.AutocompleteChoices
+}
+
+// AutocompleterFunc is a function that implements Autocompleter.
+type AutocompleterFunc func(data AutocompleteData) api.AutocompleteChoices
+
+var _ Autocompleter = AutocompleterFunc(nil)
+
+// Autocomplete implements Autocompleter.
+func (f AutocompleterFunc) Autocomplete(data AutocompleteData) api.AutocompleteChoices {
+	return f(data)
+}
+
+type autocompleteHandler struct{ Autocompleter }
+
+func (h autocompleteHandler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	data := ev.Data.(*discord.AutocompleteInteraction)
+	name, focused := autocompletePath(data.Name, data.Options)
+
+	choices := h.Autocomplete(AutocompleteData{
+		Event:   ev,
+		Data:    data,
+		Name:    name,
+		Focused: *focused,
+	})
+	if choices == nil {
+		return nil
+	}
+
+	return &api.InteractionResponse{
+		Type: api.AutocompleteResult,
+		Data: &api.InteractionResponseData{Choices: choices},
+	}
+}
+
+// ComponentData is the data of a routed component interaction.
+type ComponentData struct {
+	// Event is the interaction event of the component.
+	Event *discord.InteractionEvent
+	// Data is the component interaction data.
+	Data discord.ComponentInteraction
+	// Prefix is the custom ID prefix that the component was routed by.
+	Prefix string
+	// Arg is the rest of the custom ID after the prefix.
+	Arg string
+}
+
+// ComponentHandler handles a routed component interaction.
+type ComponentHandler interface {
+	// HandleComponent handles the component and returns a response. A nil
+	// response means that no response is sent.
+	HandleComponent(data ComponentData) *api.InteractionResponse
+}
+
+// ComponentHandlerFunc is a function that implements ComponentHandler.
+type ComponentHandlerFunc func(data ComponentData) *api.InteractionResponse
+
+var _ ComponentHandler = ComponentHandlerFunc(nil)
+
+// HandleComponent implements ComponentHandler.
+func (f ComponentHandlerFunc) HandleComponent(data ComponentData) *api.InteractionResponse {
+	return f(data)
+}
+
+type componentHandler struct {
+	ComponentHandler
+	prefix string
+}
+
+func (h componentHandler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	data := ev.Data.(discord.ComponentInteraction)
+
+	return h.HandleComponent(ComponentData{
+		Event:  ev,
+		Data:   data,
+		Prefix: h.prefix,
+		Arg:    string(data.ID())[len(h.prefix):],
+	})
+}
+
+// ModalData is the data of a routed modal submission.
+type ModalData struct {
+	// Event is the interaction event of the modal submission.
+	Event *discord.InteractionEvent
+	// Data is the modal interaction data.
+	Data *discord.ModalInteraction
+	// Prefix is the custom ID prefix that the modal was routed by.
+	Prefix string
+	// Arg is the rest of the custom ID after the prefix.
+	Arg string
+}
+
+// ModalHandler handles a routed modal submission.
+type ModalHandler interface {
+	// HandleModal handles the modal submission and returns a response. A nil
+	// response means that no response is sent.
+	HandleModal(data ModalData) *api.InteractionResponse
+}
+
+// ModalHandlerFunc is a function that implements ModalHandler.
+type ModalHandlerFunc func(data ModalData) *api.InteractionResponse
+
+var _ ModalHandler = ModalHandlerFunc(nil)
+
+// HandleModal implements ModalHandler.
+func (f ModalHandlerFunc) HandleModal(data ModalData) *api.InteractionResponse {
+	return f(data)
+}
+
+type modalHandler struct {
+	ModalHandler
+	prefix string
+}
+
+func (h modalHandler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	data := ev.Data.(*discord.ModalInteraction)
+
+	return h.HandleModal(ModalData{
+		Event:  ev,
+		Data:   data,
+		Prefix: h.prefix,
+		Arg:    string(data.CustomID)[len(h.prefix):],
+	})
+}
+
+// Respond is a helper that returns a response with a message containing the
+// given content.
+func Respond(content string) *api.InteractionResponse {
+	return &api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+		},
+	}
+}
@@ -0,0 +1,299 @@
+// Package cmdroute provides a router for interaction events. It routes
+// application commands by their command path, components by their custom ID
+// prefix, modals by their custom ID prefix, and autocompletion by the command
+// path and the focused option.
+//
+// Router implements webhook.InteractionHandler, so it can be used with both the
+// gateway and the webhook.InteractionServer:
+//
+//	r := cmdroute.NewRouter()
+//	r.AddFunc("ping", func(cmd cmdroute.CommandData) *api.InteractionResponse {
+//	    return cmdroute.Respond("Pong!")
+//	})
+//
+//	// Gateway.
+//	s.AddInteractionHandler(r)
+//	// HTTP.
+//	srv, err := webhook.NewInteractionServer(pubkey, r)
+package cmdroute
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/webhook"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Middleware wraps around an interaction handler. A middleware may return a
+// response without calling next to stop the interaction from being handled.
+type Middleware func(next webhook.InteractionHandler) webhook.InteractionHandler
+
+// Router is a router for interaction events. A Router instance is thread-safe,
+// and a zero-value Router is not valid; use NewRouter.
+type Router struct {
+	// NotFound is called when no route matches the interaction. By default,
+	// it returns nil, which means no response is sent.
+	NotFound webhook.InteractionHandler
+
+	root   *routes
+	parent *Router
+	prefix string
+
+	mwMut       sync.RWMutex
+	middlewares []Middleware
+}
+
+var _ webhook.InteractionHandler = (*Router)(nil)
+
+// routes is the route table shared by a Router and all of its sub-routers.
+type routes struct {
+	mut           sync.RWMutex
+	commands      map[string]route
+	autocompletes map[autocompleteKey]route
+	components    []prefixRoute
+	modals        []prefixRoute
+}
+
+type autocompleteKey struct {
+	path   string
+	option string
+}
+
+// route is a handler along with the router that it was added in, which
+// decides the middlewares that are used.
+type route struct {
+	router  *Router
+	handler webhook.InteractionHandler
+}
+
+type prefixRoute struct {
+	prefix string
+	route
+}
+
+// NewRouter creates a new Router.
+func NewRouter() *Router {
+	return &Router{
+		root: &routes{
+			commands:      make(map[string]route),
+			autocompletes: make(map[autocompleteKey]route),
+		},
+	}
+}
+
+// Use adds the given middlewares into the router. The middlewares apply to all
+// routes added into this router and its sub-routers, including routes that
+// were added before Use was called. Middlewares are called in the order that
+// they are added.
+func (r *Router) Use(mws ...Middleware) {
+	r.mwMut.Lock()
+	r.middlewares = append(r.middlewares, mws...)
+	r.mwMut.Unlock()
+}
+
+// Sub creates a sub-router for the command with the given name and calls fn
+// with it. All commands added into the sub-router are prefixed with the name,
+// so adding "ban" into the sub-router of "admin" will route the command path
+// "admin ban". Middlewares added into the sub-router only apply to its
+// routes.
+func (r *Router) Sub(name string, fn func(r *Router)) {
+	fn(&Router{
+		root:   r.root,
+		parent: r,
+		prefix: r.path(name),
+	})
+}
+
+// With creates a sub-router that shares the same command prefix but uses
+// additional middlewares.
+func (r *Router) With(mws ...Middleware) *Router {
+	return &Router{
+		root:        r.root,
+		parent:      r,
+		prefix:      r.prefix,
+		middlewares: mws,
+	}
+}
+
+func (r *Router) path(name string) string {
+	if r.prefix == "" {
+		return name
+	}
+	return r.prefix + " " + name
+}
+
+// Add adds a command handler for the given command name. The name may be a
+// path separated by spaces, such as "admin ban", to route subcommands and
+// subcommand groups.
+func (r *Router) Add(name string, h CommandHandler) {
+	r.root.mut.Lock()
+	defer r.root.mut.Unlock()
+
+	r.root.commands[r.path(name)] = route{r, commandHandler{h}}
+}
+
+// AddFunc is a function variant of Add.
+func (r *Router) AddFunc(name string, f CommandHandlerFunc) {
+	r.Add(name, f)
+}
+
+// AddAutocompleter adds an autocompleter for the option with the given name of
+// the given command. The command name follows the same rules as Add's.
+func (r *Router) AddAutocompleter(command, option string, a Autocompleter) {
+	r.root.mut.Lock()
+	defer r.root.mut.Unlock()
+
+	key := autocompleteKey{r.path(command), option}
+	r.root.autocompletes[key] = route{r, autocompleteHandler{a}}
+}
+
+// AddAutocompleterFunc is a function variant of AddAutocompleter.
+func (r *Router) AddAutocompleterFunc(command, option string, f AutocompleterFunc) {
+	r.AddAutocompleter(command, option, f)
+}
+
+// AddComponent adds a handler for components whose custom IDs start with the
+// given prefix. If multiple prefixes match, the longest one is used. This
+// allows state to be encoded after the prefix, such as "page:3".
+func (r *Router) AddComponent(prefix string, h ComponentHandler) {
+	r.root.mut.Lock()
+	defer r.root.mut.Unlock()
+
+	r.root.components = append(r.root.components, prefixRoute{
+		prefix: prefix,
+		route:  route{r, componentHandler{h, prefix}},
+	})
+}
+
+// AddComponentFunc is a function variant of AddComponent.
+func (r *Router) AddComponentFunc(prefix string, f ComponentHandlerFunc) {
+	r.AddComponent(prefix, f)
+}
+
+// AddModal adds a handler for modals whose custom IDs start with the given
+// prefix. Prefixes follow the same rules as AddComponent's.
+func (r *Router) AddModal(prefix string, h ModalHandler) {
+	r.root.mut.Lock()
+	defer r.root.mut.Unlock()
+
+	r.root.modals = append(r.root.modals, prefixRoute{
+		prefix: prefix,
+		route:  route{r, modalHandler{h, prefix}},
+	})
+}
+
+// AddModalFunc is a function variant of AddModal.
+func (r *Router) AddModalFunc(prefix string, f ModalHandlerFunc) {
+	r.AddModal(prefix, f)
+}
+
+// HandleInteraction implements webhook.InteractionHandler.
+func (r *Router) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	route, ok := r.find(ev)
+	if !ok {
+		if r.NotFound != nil {
+			return r.NotFound.HandleInteraction(ev)
+		}
+		return nil
+	}
+
+	return route.router.wrap(route.handler).HandleInteraction(ev)
+}
+
+// wrap wraps h with the middlewares of the router and all of its parents. The
+// root router's middlewares are called first.
+func (r *Router) wrap(h webhook.InteractionHandler) webhook.InteractionHandler {
+	for router := r; router != nil; router = router.parent {
+		router.mwMut.RLock()
+		mws := router.middlewares
+		router.mwMut.RUnlock()
+
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+	}
+	return h
+}
+
+func (r *Router) find(ev *discord.InteractionEvent) (route, bool) {
+	r.root.mut.RLock()
+	defer r.root.mut.RUnlock()
+
+	switch data := ev.Data.(type) {
+	case *discord.CommandInteraction:
+		path, _ := commandPath(data.Name, data.Options)
+		route, ok := r.root.commands[path]
+		return route, ok
+
+	case *discord.AutocompleteInteraction:
+		path, focused := autocompletePath(data.Name, data.Options)
+		if focused == nil {
+			return route{}, false
+		}
+		route, ok := r.root.autocompletes[autocompleteKey{path, focused.Name}]
+		return route, ok
+
+	case discord.ComponentInteraction:
+		return findPrefix(r.root.components, string(data.ID()))
+
+	case *discord.ModalInteraction:
+		return findPrefix(r.root.modals, string(data.CustomID))
+	}
+
+	return route{}, false
+}
+
+func findPrefix(routes []prefixRoute, id string) (route, bool) {
+	var found *prefixRoute
+	for i, r := range routes {
+		if !strings.HasPrefix(id, r.prefix) {
+			continue
+		}
+		if found == nil || len(r.prefix) > len(found.prefix) {
+			found = &routes[i]
+		}
+	}
+
+	if found == nil {
+		return route{}, false
+	}
+	return found.route, true
+}
+
+// commandPath returns the full path of the invoked command, including
+// subcommand groups and subcommands, as well as the options of the innermost
+// subcommand.
+func commandPath(
+	name string, opts discord.CommandInteractionOptions) (string, discord.CommandInteractionOptions) {
+
+	for len(opts) == 1 && isSubcommand(opts[0].Type) {
+		name += " " + opts[0].Name
+		opts = opts[0].Options
+	}
+	return name, opts
+}
+
+// autocompletePath returns the full path of the command being autocompleted as
+// well as the focused option, which is nil if there's none.
+func autocompletePath(
+	name string, opts discord.AutocompleteOptions) (string, *discord.AutocompleteOption) {
+
+	for len(opts) == 1 && isSubcommand(opts[0].Type) {
+		name += " " + opts[0].Name
+		opts = opts[0].Options
+	}
+
+	for i, opt := range opts {
+		if opt.Focused {
+			return name, &opts[i]
+		}
+	}
+
+	return name, nil
+}
+
+func isSubcommand(t discord.CommandOptionType) bool {
+	return t == discord.SubcommandOptionType || t == discord.SubcommandGroupOptionType
+}
@@ -0,0 +1,251 @@
+package cmdroute
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/webhook"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+func responseContent(t *testing.T, resp *api.InteractionResponse) string {
+	t.Helper()
+
+	if resp == nil {
+		t.Fatal("unexpected nil response")
+	}
+	if resp.Data == nil {
+		t.Fatal("unexpected nil response data")
+	}
+	return resp.Data.Content.Val
+}
+
+func commandEvent(name string, opts ...discord.CommandInteractionOption) *discord.InteractionEvent {
+	return &discord.InteractionEvent{
+		Data: &discord.CommandInteraction{
+			Name:    name,
+			Options: opts,
+		},
+	}
+}
+
+func TestRouterCommands(t *testing.T) {
+	r := NewRouter()
+	r.AddFunc("ping", func(cmd CommandData) *api.InteractionResponse {
+		return Respond("pong")
+	})
+	r.Sub("admin", func(r *Router) {
+		r.AddFunc("ban", func(cmd CommandData) *api.InteractionResponse {
+			return Respond(cmd.Name + ":" + cmd.Options[0].Name)
+		})
+		r.Sub("role", func(r *Router) {
+			r.AddFunc("add", func(cmd CommandData) *api.InteractionResponse {
+				return Respond(cmd.Name)
+			})
+		})
+	})
+
+	tests := []struct {
+		name   string
+		ev     *discord.InteractionEvent
+		expect string
+	}{
+		{
+			name:   "command",
+			ev:     commandEvent("ping"),
+			expect: "pong",
+		},
+		{
+			name: "subcommand",
+			ev: commandEvent("admin", discord.CommandInteractionOption{
+				Type: discord.SubcommandOptionType,
+				Name: "ban",
+				Options: []discord.CommandInteractionOption{
+					{Type: discord.UserOptionType, Name: "user"},
+				},
+			}),
+			expect: "admin ban:user",
+		},
+		{
+			name: "subcommand group",
+			ev: commandEvent("admin", discord.CommandInteractionOption{
+				Type: discord.SubcommandGroupOptionType,
+				Name: "role",
+				Options: []discord.CommandInteractionOption{
+					{Type: discord.SubcommandOptionType, Name: "add"},
+				},
+			}),
+			expect: "admin role add",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := responseContent(t, r.HandleInteraction(test.ev))
+			if content != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, content)
+			}
+		})
+	}
+
+	if resp := r.HandleInteraction(commandEvent("unknown")); resp != nil {
+		t.Fatal("unexpected response for unknown command:", resp)
+	}
+}
+
+func TestRouterMiddlewares(t *testing.T) {
+	var calls []string
+
+	mw := func(name string) Middleware {
+		return func(next webhook.InteractionHandler) webhook.InteractionHandler {
+			return webhook.InteractionHandlerFunc(
+				func(ev *discord.InteractionEvent) *api.InteractionResponse {
+					calls = append(calls, name)
+					return next.HandleInteraction(ev)
+				},
+			)
+		}
+	}
+
+	deny := func(next webhook.InteractionHandler) webhook.InteractionHandler {
+		return webhook.InteractionHandlerFunc(
+			func(ev *discord.InteractionEvent) *api.InteractionResponse {
+				return Respond("denied")
+			},
+		)
+	}
+
+	r := NewRouter()
+	r.Use(mw("root1"), mw("root2"))
+	r.AddFunc("ping", func(cmd CommandData) *api.InteractionResponse {
+		calls = append(calls, "ping")
+		return Respond("pong")
+	})
+	r.Sub("admin", func(r *Router) {
+		r.Use(mw("admin"))
+		r.AddFunc("ban", func(cmd CommandData) *api.InteractionResponse {
+			calls = append(calls, "ban")
+			return Respond("banned")
+		})
+		r.With(deny).AddFunc("kick", func(cmd CommandData) *api.InteractionResponse {
+			t.Error("kick called despite being denied")
+			return nil
+		})
+	})
+
+	r.HandleInteraction(commandEvent("ping"))
+	assertCalls(t, calls, "root1", "root2", "ping")
+
+	calls = nil
+	r.HandleInteraction(commandEvent("admin", discord.CommandInteractionOption{
+		Type: discord.SubcommandOptionType,
+		Name: "ban",
+	}))
+	assertCalls(t, calls, "root1", "root2", "admin", "ban")
+
+	calls = nil
+	resp := r.HandleInteraction(commandEvent("admin", discord.CommandInteractionOption{
+		Type: discord.SubcommandOptionType,
+		Name: "kick",
+	}))
+	assertCalls(t, calls, "root1", "root2", "admin")
+
+	if content := responseContent(t, resp); content != "denied" {
+		t.Fatalf("expected denied response, got %q", content)
+	}
+}
+
+func assertCalls(t *testing.T, calls []string, expect ...string) {
+	t.Helper()
+
+	if strings.Join(calls, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expected calls %q, got %q", expect, calls)
+	}
+}
+
+func TestRouterComponents(t *testing.T) {
+	r := NewRouter()
+	r.AddComponentFunc("page:", func(data ComponentData) *api.InteractionResponse {
+		return Respond("page " + data.Arg)
+	})
+	r.AddComponentFunc("page:last", func(data ComponentData) *api.InteractionResponse {
+		return Respond("last page")
+	})
+	r.AddModalFunc("report:", func(data ModalData) *api.InteractionResponse {
+		return Respond("report " + data.Arg)
+	})
+
+	tests := []struct {
+		name   string
+		data   discord.InteractionData
+		expect string
+	}{
+		{
+			name:   "button",
+			data:   &discord.ButtonInteraction{CustomID: "page:3"},
+			expect: "page 3",
+		},
+		{
+			name:   "longest prefix",
+			data:   &discord.ButtonInteraction{CustomID: "page:last"},
+			expect: "last page",
+		},
+		{
+			name:   "select",
+			data:   &discord.SelectInteraction{CustomID: "page:5"},
+			expect: "page 5",
+		},
+		{
+			name:   "modal",
+			data:   &discord.ModalInteraction{CustomID: "report:42"},
+			expect: "report 42",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := r.HandleInteraction(&discord.InteractionEvent{Data: test.data})
+			if content := responseContent(t, resp); content != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, content)
+			}
+		})
+	}
+}
+
+func TestRouterAutocomplete(t *testing.T) {
+	r := NewRouter()
+	r.Sub("music", func(r *Router) {
+		r.AddAutocompleterFunc("play", "song", func(data AutocompleteData) api.AutocompleteChoices {
+			return api.AutocompleteStringChoices{
+				{Name: data.Name, Value: data.Focused.String()},
+			}
+		})
+	})
+
+	resp := r.HandleInteraction(&discord.InteractionEvent{
+		Data: &discord.AutocompleteInteraction{
+			Name: "music",
+			Options: discord.AutocompleteOptions{{
+				Type: discord.SubcommandOptionType,
+				Name: "play",
+				Options: []discord.AutocompleteOption{
+					{Type: discord.StringOptionType, Name: "volume", Value: []byte("1")},
+					{Type: discord.StringOptionType, Name: "song", Value: []byte(`"nev"`), Focused: true},
+				},
+			}},
+		},
+	})
+
+	if resp == nil || resp.Type != api.AutocompleteResult {
+		t.Fatalf("unexpected response %#v", resp)
+	}
+
+	choices, ok := resp.Data.Choices.(api.AutocompleteStringChoices)
+	if !ok || len(choices) != 1 {
+		t.Fatalf("unexpected choices %#v", resp.Data.Choices)
+	}
+	if choices[0].Name != "music play" || choices[0].Value != "nev" {
+		t.Fatalf("unexpected choice %#v", choices[0])
+	}
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// CommandSyncAction is the action taken on a single command during a sync.
+type CommandSyncAction uint8
+
+const (
+	// CommandCreated means that the command did not exist and was created.
+	CommandCreated CommandSyncAction = iota + 1
+	// CommandEdited means that the command existed but differed, so it was
+	// edited.
+	CommandEdited
+	// CommandDeleted means that the command existed but was not desired, so
+	// it was deleted.
+	CommandDeleted
+)
+
+// String returns the action in lower case, such as "create".
+func (a CommandSyncAction) String() string {
+	switch a {
+	case CommandCreated:
+		return "create"
+	case CommandEdited:
+		return "edit"
+	case CommandDeleted:
+		return "delete"
+	default:
+		return fmt.Sprintf("CommandSyncAction(%d)", uint8(a))
+	}
+}
+
+// CommandChange is a single change made during a sync.
+type CommandChange struct {
+	Action CommandSyncAction
+	// GuildID is the guild that the command belongs to. It is invalid for
+	// global commands.
+	GuildID discord.GuildID
+	// Name is the name of the command.
+	Name string
+	// Data is the desired command. It is empty for deletions.
+	Data CreateCommandData
+	// Existing is the command that existed before the sync. It is nil for
+	// creations.
+	Existing *discord.Command
+	// Fields are the names of the top-level JSON fields that differ, such as
+	// "description" or "options". It is only set for edits.
+	Fields []string
+}
+
+// String formats the change in a human-readable form.
+func (c CommandChange) String() string {
+	var b strings.Builder
+	b.WriteString(c.Action.String())
+
+	if c.GuildID.IsValid() {
+		b.WriteString(" guild ")
+		b.WriteString(c.GuildID.String())
+	} else {
+		b.WriteString(" global")
+	}
+
+	b.WriteString(" command ")
+	b.WriteString(c.Name)
+
+	if len(c.Fields) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(c.Fields, ", "))
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// CommandSyncReport is the report of a sync.
+type CommandSyncReport struct {
+	// Changes are the changes that were made, or would have been made if the
+	// sync was a dry run.
+	Changes []CommandChange
+	// Unchanged is the number of commands that were already up-to-date.
+	Unchanged int
+	// DryRun is true if no changes were actually made.
+	DryRun bool
+}
+
+// String formats the report in a human-readable form, with one change per
+// line.
+func (r CommandSyncReport) String() string {
+	var b strings.Builder
+	if r.DryRun {
+		b.WriteString("dry run: ")
+	}
+	fmt.Fprintf(&b, "%d changed, %d unchanged", len(r.Changes), r.Unchanged)
+
+	for _, change := range r.Changes {
+		b.WriteString("\n")
+		b.WriteString(change.String())
+	}
+
+	return b.String()
+}
+
+// SyncCommandsData is the data for SyncCommands.
+type SyncCommandsData struct {
+	// Commands are the desired global commands. Global commands that aren't
+	// in this list are deleted.
+	Commands []CreateCommandData
+	// Guilds maps a guild to its desired guild commands. Only the guilds in
+	// this map are synced, and guild commands that aren't in their guild's
+	// list are deleted. This is useful for registering commands that should
+	// only exist in a few guilds, or for overriding global commands while
+	// developing, since guild commands are updated instantly.
+	Guilds map[discord.GuildID][]CreateCommandData
+	// DryRun, if true, only computes the changes without making them.
+	DryRun bool
+}
+
+// SyncCommands synchronizes the registered application commands with the
+// desired ones. Unlike BulkOverwriteCommands, only the commands that
+// semantically differ are created, edited or deleted, so syncing on every
+// startup doesn't count toward the daily command creation limit.
+//
+// The existing commands are fetched with all of their localizations. Fields
+// that are populated by Discord, such as the localized names, the IDs and the
+// version, are ignored when comparing commands.
+func (c *Client) SyncCommands(
+	appID discord.AppID, data SyncCommandsData) (*CommandSyncReport, error) {
+
+	report := CommandSyncReport{DryRun: data.DryRun}
+
+	existing, err := c.CommandsWithLocalizations(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get global commands")
+	}
+
+	if err := c.syncCommands(&report, appID, 0, existing, data.Commands); err != nil {
+		return &report, err
+	}
+
+	// Sync guilds in a stable order.
+	guildIDs := make([]discord.GuildID, 0, len(data.Guilds))
+	for guildID := range data.Guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	sort.Slice(guildIDs, func(i, j int) bool { return guildIDs[i] < guildIDs[j] })
+
+	for _, guildID := range guildIDs {
+		existing, err := c.GuildCommandsWithLocalizations(appID, guildID)
+		if err != nil {
+			return &report, errors.Wrapf(err, "failed to get commands of guild %d", guildID)
+		}
+
+		err = c.syncCommands(&report, appID, guildID, existing, data.Guilds[guildID])
+		if err != nil {
+			return &report, err
+		}
+	}
+
+	return &report, nil
+}
+
+// commandKey identifies a command. Commands of different types may share the
+// same name.
+type commandKey struct {
+	name string
+	typ  discord.CommandType
+}
+
+func (c *Client) syncCommands(
+	report *CommandSyncReport,
+	appID discord.AppID, guildID discord.GuildID,
+	existing []discord.Command, desired []CreateCommandData) error {
+
+	existingMap := make(map[commandKey]*discord.Command, len(existing))
+	for i, cmd := range existing {
+		existingMap[commandKey{cmd.Name, cmd.Type}] = &existing[i]
+	}
+
+	var changes []CommandChange
+
+	for _, data := range desired {
+		key := commandKey{data.Name, data.Type}
+		if key.typ == 0 {
+			key.typ = discord.ChatInputCommand
+		}
+
+		cmd, ok := existingMap[key]
+		if !ok {
+			changes = append(changes, CommandChange{
+				Action:  CommandCreated,
+				GuildID: guildID,
+				Name:    data.Name,
+				Data:    data,
+			})
+			continue
+		}
+
+		delete(existingMap, key)
+
+		fields, err := diffCommand(guildID, cmd, data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to diff command %q", data.Name)
+		}
+
+		if len(fields) == 0 {
+			report.Unchanged++
+			continue
+		}
+
+		changes = append(changes, CommandChange{
+			Action:   CommandEdited,
+			GuildID:  guildID,
+			Name:     data.Name,
+			Data:     data,
+			Existing: cmd,
+			Fields:   fields,
+		})
+	}
+
+	for i, cmd := range existing {
+		if _, ok := existingMap[commandKey{cmd.Name, cmd.Type}]; ok {
+			changes = append(changes, CommandChange{
+				Action:   CommandDeleted,
+				GuildID:  guildID,
+				Name:     cmd.Name,
+				Existing: &existing[i],
+			})
+		}
+	}
+
+	for _, change := range changes {
+		if !report.DryRun {
+			if err := c.applyCommandChange(appID, change); err != nil {
+				return errors.Wrapf(err, "failed to %s", change)
+			}
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	return nil
+}
+
+func (c *Client) applyCommandChange(appID discord.AppID, change CommandChange) error {
+	var err error
+
+	switch change.Action {
+	case CommandCreated:
+		if change.GuildID.IsValid() {
+			_, err = c.CreateGuildCommand(appID, change.GuildID, change.Data)
+		} else {
+			_, err = c.CreateCommand(appID, change.Data)
+		}
+	case CommandEdited:
+		if change.GuildID.IsValid() {
+			_, err = c.EditGuildCommand(appID, change.GuildID, change.Existing.ID, change.Data)
+		} else {
+			_, err = c.EditCommand(appID, change.Existing.ID, change.Data)
+		}
+	case CommandDeleted:
+		if change.GuildID.IsValid() {
+			err = c.DeleteGuildCommand(appID, change.GuildID, change.Existing.ID)
+		} else {
+			err = c.DeleteCommand(appID, change.Existing.ID)
+		}
+	}
+
+	return err
+}
+
+// serverCommandFields are the fields that are populated by Discord and should
+// be ignored when comparing commands.
+var serverCommandFields = []string{
+	"id",
+	"application_id",
+	"guild_id",
+	"version",
+	"name_localized",
+	"description_localized",
+}
+
+// diffCommand returns the names of the top-level fields that differ between
+// the existing command and the desired command.
+func diffCommand(
+	guildID discord.GuildID, existing *discord.Command, desired CreateCommandData) ([]string, error) {
+
+	existingData := CreateCommandData{
+		Name:                     existing.Name,
+		NameLocalizations:        existing.NameLocalizations,
+		Description:              existing.Description,
+		DescriptionLocalizations: existing.DescriptionLocalizations,
+		Options:                  existing.Options,
+		DefaultMemberPermissions: existing.DefaultMemberPermissions,
+		NoDMPermission:           existing.NoDMPermission,
+		NoDefaultPermission:      existing.NoDefaultPermission,
+		Type:                     existing.Type,
+	}
+
+	a, err := normalizeCommand(guildID, existingData)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := normalizeCommand(guildID, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for k, v := range a {
+		if !reflect.DeepEqual(v, b[k]) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// normalizeCommand marshals the command into a generic JSON object with all
+// server-populated and zero-value fields removed, so that commands can be
+// compared semantically.
+func normalizeCommand(guildID discord.GuildID, data CreateCommandData) (map[string]interface{}, error) {
+	if data.Type == 0 {
+		data.Type = discord.ChatInputCommand
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	if guildID.IsValid() {
+		// DM permissions only apply to global commands.
+		delete(v, "dm_permission")
+	}
+
+	normalized, _ := normalizeJSON(v).(map[string]interface{})
+	return normalized, nil
+}
+
+// normalizeJSON recursively removes server-populated fields and fields that
+// are equivalent to being absent. Numbers are kept, since zero may be a
+// meaningful value, such as a minimum.
+func normalizeJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, field := range serverCommandFields {
+			delete(v, field)
+		}
+		for k, fieldv := range v {
+			fieldv = normalizeJSON(fieldv)
+			if fieldv == nil {
+				delete(v, k)
+			} else {
+				v[k] = fieldv
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, elem := range v {
+			v[i] = normalizeJSON(elem)
+		}
+		return v
+
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+
+	case bool:
+		if !v {
+			return nil
+		}
+		return v
+
+	default:
+		return v
+	}
+}
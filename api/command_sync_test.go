@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// commandServer is a fake server that serves existing commands and records
+// the requests that modify them.
+type commandServer struct {
+	mut      sync.Mutex
+	global   string
+	guild    string
+	requests []string
+
+	// unlocalized counts the GETs without with_localizations.
+	unlocalized int
+}
+
+func (s *commandServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if r.Method == "GET" {
+		body := s.global
+		if strings.Contains(r.URL.Path, "/guilds/") {
+			body = s.guild
+		}
+
+		// Discord only sends all localizations if they're asked for.
+		if r.URL.Query().Get("with_localizations") != "true" {
+			s.unlocalized++
+			body = stripLocalizations(body)
+		}
+
+		w.Write([]byte(body))
+		return
+	}
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if r.Method == "DELETE" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Write([]byte("{}"))
+}
+
+// stripLocalizations removes the localizations of the commands and their
+// options in the JSON body.
+func stripLocalizations(body string) string {
+	var cmds []map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &cmds); err != nil {
+		panic(err)
+	}
+
+	var strip func(v map[string]interface{})
+	strip = func(v map[string]interface{}) {
+		delete(v, "name_localizations")
+		delete(v, "description_localizations")
+
+		options, _ := v["options"].([]interface{})
+		for _, option := range options {
+			if option, ok := option.(map[string]interface{}); ok {
+				strip(option)
+			}
+		}
+	}
+
+	for _, cmd := range cmds {
+		strip(cmd)
+	}
+
+	b, err := json.Marshal(cmds)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func newCommandServer(t *testing.T, global, guild string) (*commandServer, *Client) {
+	s := &commandServer{global: global, guild: guild}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	endpoint := EndpointApplications
+	EndpointApplications = srv.URL + "/applications/"
+	t.Cleanup(func() { EndpointApplications = endpoint })
+
+	return s, NewClient("")
+}
+
+const existingGlobalCommands = `[
+	{
+		"id": "10",
+		"application_id": "1",
+		"version": "100",
+		"type": 1,
+		"name": "ping",
+		"name_localized": "ping",
+		"description": "Ping!",
+		"dm_permission": true,
+		"default_permission": true
+	},
+	{
+		"id": "11",
+		"application_id": "1",
+		"version": "101",
+		"type": 1,
+		"name": "ban",
+		"description": "Ban a user.",
+		"dm_permission": true,
+		"default_permission": true,
+		"options": [
+			{"type": 6, "name": "user", "description": "The user.", "required": true},
+			{"type": 4, "name": "days", "description": "Days.", "min_value": 0}
+		]
+	},
+	{
+		"id": "12",
+		"application_id": "1",
+		"version": "102",
+		"type": 1,
+		"name": "old",
+		"description": "Old command.",
+		"dm_permission": true,
+		"default_permission": true
+	}
+]`
+
+func TestSyncCommands(t *testing.T) {
+	desired := []CreateCommandData{
+		{
+			Name:        "ping",
+			Description: "Ping!",
+		},
+		{
+			Name:        "ban",
+			Description: "Ban a user.",
+			Options: discord.CommandOptions{
+				&discord.UserOption{OptionName: "user", Description: "The user.", Required: true},
+				&discord.IntegerOption{OptionName: "days", Description: "Days.", Min: option.NewInt(1)},
+			},
+		},
+		{
+			Name:        "new",
+			Description: "New command.",
+		},
+	}
+
+	guild := []CreateCommandData{
+		{Name: "debug", Description: "Debug."},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		s, client := newCommandServer(t, existingGlobalCommands, "[]")
+
+		report, err := client.SyncCommands(1, SyncCommandsData{
+			Commands: desired,
+			Guilds:   map[discord.GuildID][]CreateCommandData{5: guild},
+			DryRun:   true,
+		})
+		if err != nil {
+			t.Fatal("failed to sync:", err)
+		}
+
+		if len(s.requests) > 0 {
+			t.Fatal("dry run made requests:", s.requests)
+		}
+
+		expect := strings.Join([]string{
+			"dry run: 4 changed, 1 unchanged",
+			"edit global command ban (options)",
+			"create global command new",
+			"delete global command old",
+			"create guild 5 command debug",
+		}, "\n")
+
+		if report.String() != expect {
+			t.Fatalf("unexpected report:\n%s\nexpected:\n%s", report, expect)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		s, client := newCommandServer(t, existingGlobalCommands, "[]")
+
+		_, err := client.SyncCommands(1, SyncCommandsData{
+			Commands: desired,
+			Guilds:   map[discord.GuildID][]CreateCommandData{5: guild},
+		})
+		if err != nil {
+			t.Fatal("failed to sync:", err)
+		}
+
+		expect := []string{
+			"PATCH /applications/1/commands/11",
+			"POST /applications/1/commands",
+			"DELETE /applications/1/commands/12",
+			"POST /applications/1/guilds/5/commands",
+		}
+
+		if strings.Join(s.requests, "\n") != strings.Join(expect, "\n") {
+			t.Fatalf("unexpected requests %q", s.requests)
+		}
+	})
+}
+
+func TestSyncCommandsUnchanged(t *testing.T) {
+	s, client := newCommandServer(t, "[]", `[{
+		"id": "20",
+		"application_id": "1",
+		"guild_id": "5",
+		"version": "200",
+		"name": "debug",
+		"name_localizations": {"fr": "déboguer"},
+		"name_localized": "déboguer",
+		"description": "Debug.",
+		"default_permission": true
+	}]`)
+
+	report, err := client.SyncCommands(1, SyncCommandsData{
+		Guilds: map[discord.GuildID][]CreateCommandData{
+			5: {{
+				Name:              "debug",
+				NameLocalizations: discord.StringLocales{"fr": "déboguer"},
+				Description:       "Debug.",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal("failed to sync:", err)
+	}
+
+	if len(report.Changes) > 0 || report.Unchanged != 1 {
+		t.Fatalf("unexpected report: %s", report)
+	}
+
+	if len(s.requests) > 0 {
+		t.Fatal("unexpected requests:", s.requests)
+	}
+
+	if s.unlocalized > 0 {
+		t.Fatal("commands were fetched without with_localizations")
+	}
+}
@@ -0,0 +1,457 @@
+// Code generated by generrorcodes. DO NOT EDIT.
+
+package api
+
+import "github.com/diamondburned/arikawa/v3/utils/httputil"
+
+// JSON error codes returned by Discord. Use errors.Is to check for them:
+//
+//	if errors.Is(err, api.ErrUnknownMessage) {
+//	    // The message was deleted.
+//	}
+const (
+	// ErrUnknownAccount is code 10001: Unknown account.
+	ErrUnknownAccount httputil.ErrorCode = 10001
+	// ErrUnknownApplication is code 10002: Unknown application.
+	ErrUnknownApplication httputil.ErrorCode = 10002
+	// ErrUnknownChannel is code 10003: Unknown channel.
+	ErrUnknownChannel httputil.ErrorCode = 10003
+	// ErrUnknownGuild is code 10004: Unknown guild.
+	ErrUnknownGuild httputil.ErrorCode = 10004
+	// ErrUnknownIntegration is code 10005: Unknown integration.
+	ErrUnknownIntegration httputil.ErrorCode = 10005
+	// ErrUnknownInvite is code 10006: Unknown invite.
+	ErrUnknownInvite httputil.ErrorCode = 10006
+	// ErrUnknownMember is code 10007: Unknown member.
+	ErrUnknownMember httputil.ErrorCode = 10007
+	// ErrUnknownMessage is code 10008: Unknown message.
+	ErrUnknownMessage httputil.ErrorCode = 10008
+	// ErrUnknownPermissionOverwrite is code 10009: Unknown permission overwrite.
+	ErrUnknownPermissionOverwrite httputil.ErrorCode = 10009
+	// ErrUnknownProvider is code 10010: Unknown provider.
+	ErrUnknownProvider httputil.ErrorCode = 10010
+	// ErrUnknownRole is code 10011: Unknown role.
+	ErrUnknownRole httputil.ErrorCode = 10011
+	// ErrUnknownToken is code 10012: Unknown token.
+	ErrUnknownToken httputil.ErrorCode = 10012
+	// ErrUnknownUser is code 10013: Unknown user.
+	ErrUnknownUser httputil.ErrorCode = 10013
+	// ErrUnknownEmoji is code 10014: Unknown emoji.
+	ErrUnknownEmoji httputil.ErrorCode = 10014
+	// ErrUnknownWebhook is code 10015: Unknown webhook.
+	ErrUnknownWebhook httputil.ErrorCode = 10015
+	// ErrUnknownWebhookService is code 10016: Unknown webhook service.
+	ErrUnknownWebhookService httputil.ErrorCode = 10016
+	// ErrUnknownSession is code 10020: Unknown session.
+	ErrUnknownSession httputil.ErrorCode = 10020
+	// ErrUnknownBan is code 10026: Unknown ban.
+	ErrUnknownBan httputil.ErrorCode = 10026
+	// ErrUnknownSKU is code 10027: Unknown SKU.
+	ErrUnknownSKU httputil.ErrorCode = 10027
+	// ErrUnknownStoreListing is code 10028: Unknown Store Listing.
+	ErrUnknownStoreListing httputil.ErrorCode = 10028
+	// ErrUnknownEntitlement is code 10029: Unknown entitlement.
+	ErrUnknownEntitlement httputil.ErrorCode = 10029
+	// ErrUnknownBuild is code 10030: Unknown build.
+	ErrUnknownBuild httputil.ErrorCode = 10030
+	// ErrUnknownLobby is code 10031: Unknown lobby.
+	ErrUnknownLobby httputil.ErrorCode = 10031
+	// ErrUnknownBranch is code 10032: Unknown branch.
+	ErrUnknownBranch httputil.ErrorCode = 10032
+	// ErrUnknownStoreDirectoryLayout is code 10033: Unknown store directory layout.
+	ErrUnknownStoreDirectoryLayout httputil.ErrorCode = 10033
+	// ErrUnknownRedistributable is code 10036: Unknown redistributable.
+	ErrUnknownRedistributable httputil.ErrorCode = 10036
+	// ErrUnknownGiftCode is code 10038: Unknown gift code.
+	ErrUnknownGiftCode httputil.ErrorCode = 10038
+	// ErrUnknownStream is code 10049: Unknown stream.
+	ErrUnknownStream httputil.ErrorCode = 10049
+	// ErrUnknownPremiumServerSubscribeCooldown is code 10050: Unknown premium server subscribe cooldown.
+	ErrUnknownPremiumServerSubscribeCooldown httputil.ErrorCode = 10050
+	// ErrUnknownGuildTemplate is code 10057: Unknown guild template.
+	ErrUnknownGuildTemplate httputil.ErrorCode = 10057
+	// ErrUnknownDiscoverableServerCategory is code 10059: Unknown discoverable server category.
+	ErrUnknownDiscoverableServerCategory httputil.ErrorCode = 10059
+	// ErrUnknownSticker is code 10060: Unknown sticker.
+	ErrUnknownSticker httputil.ErrorCode = 10060
+	// ErrUnknownInteraction is code 10062: Unknown interaction.
+	ErrUnknownInteraction httputil.ErrorCode = 10062
+	// ErrUnknownApplicationCommand is code 10063: Unknown application command.
+	ErrUnknownApplicationCommand httputil.ErrorCode = 10063
+	// ErrUnknownVoiceState is code 10065: Unknown voice state.
+	ErrUnknownVoiceState httputil.ErrorCode = 10065
+	// ErrUnknownApplicationCommandPermissions is code 10066: Unknown application command permissions.
+	ErrUnknownApplicationCommandPermissions httputil.ErrorCode = 10066
+	// ErrUnknownStageInstance is code 10067: Unknown Stage Instance.
+	ErrUnknownStageInstance httputil.ErrorCode = 10067
+	// ErrUnknownGuildMemberVerificationForm is code 10068: Unknown Guild Member Verification Form.
+	ErrUnknownGuildMemberVerificationForm httputil.ErrorCode = 10068
+	// ErrUnknownGuildWelcomeScreen is code 10069: Unknown Guild Welcome Screen.
+	ErrUnknownGuildWelcomeScreen httputil.ErrorCode = 10069
+	// ErrUnknownGuildScheduledEvent is code 10070: Unknown Guild Scheduled Event.
+	ErrUnknownGuildScheduledEvent httputil.ErrorCode = 10070
+	// ErrUnknownGuildScheduledEventUser is code 10071: Unknown Guild Scheduled Event User.
+	ErrUnknownGuildScheduledEventUser httputil.ErrorCode = 10071
+	// ErrBotsCannotUseEndpoint is code 20001: Bots cannot use this endpoint.
+	ErrBotsCannotUseEndpoint httputil.ErrorCode = 20001
+	// ErrOnlyBotsCanUseEndpoint is code 20002: Only bots can use this endpoint.
+	ErrOnlyBotsCanUseEndpoint httputil.ErrorCode = 20002
+	// ErrExplicitContentCannotBeSent is code 20009: Explicit content cannot be sent to the desired recipient(s).
+	ErrExplicitContentCannotBeSent httputil.ErrorCode = 20009
+	// ErrNotAuthorizedForApplication is code 20012: You are not authorized to perform this action on this application.
+	ErrNotAuthorizedForApplication httputil.ErrorCode = 20012
+	// ErrSlowmodeRateLimit is code 20016: This action cannot be performed due to slowmode rate limit.
+	ErrSlowmodeRateLimit httputil.ErrorCode = 20016
+	// ErrOnlyOwnerCanPerformAction is code 20018: Only the owner of this account can perform this action.
+	ErrOnlyOwnerCanPerformAction httputil.ErrorCode = 20018
+	// ErrAnnouncementRateLimit is code 20022: This message cannot be edited due to announcement rate limits.
+	ErrAnnouncementRateLimit httputil.ErrorCode = 20022
+	// ErrChannelWriteRateLimit is code 20028: The channel you are writing has hit the write rate limit.
+	ErrChannelWriteRateLimit httputil.ErrorCode = 20028
+	// ErrDisallowedWords is code 20031: Your Stage topic, server name, server description, or channel names contain words that are not allowed.
+	ErrDisallowedWords httputil.ErrorCode = 20031
+	// ErrGuildPremiumTooLow is code 20035: Guild premium subscription level too low.
+	ErrGuildPremiumTooLow httputil.ErrorCode = 20035
+	// ErrMaxGuilds is code 30001: Maximum number of guilds reached (100).
+	ErrMaxGuilds httputil.ErrorCode = 30001
+	// ErrMaxFriends is code 30002: Maximum number of friends reached (1000).
+	ErrMaxFriends httputil.ErrorCode = 30002
+	// ErrMaxPins is code 30003: Maximum number of pins reached for the channel (50).
+	ErrMaxPins httputil.ErrorCode = 30003
+	// ErrMaxRecipients is code 30004: Maximum number of recipients reached (10).
+	ErrMaxRecipients httputil.ErrorCode = 30004
+	// ErrMaxRoles is code 30005: Maximum number of guild roles reached (250).
+	ErrMaxRoles httputil.ErrorCode = 30005
+	// ErrMaxWebhooks is code 30007: Maximum number of webhooks reached (10).
+	ErrMaxWebhooks httputil.ErrorCode = 30007
+	// ErrMaxEmojis is code 30008: Maximum number of emojis reached.
+	ErrMaxEmojis httputil.ErrorCode = 30008
+	// ErrMaxReactions is code 30010: Maximum number of reactions reached (20).
+	ErrMaxReactions httputil.ErrorCode = 30010
+	// ErrMaxChannels is code 30013: Maximum number of guild channels reached (500).
+	ErrMaxChannels httputil.ErrorCode = 30013
+	// ErrMaxAttachments is code 30015: Maximum number of attachments in a message reached (10).
+	ErrMaxAttachments httputil.ErrorCode = 30015
+	// ErrMaxInvites is code 30016: Maximum number of invites reached (1000).
+	ErrMaxInvites httputil.ErrorCode = 30016
+	// ErrMaxAnimatedEmojis is code 30018: Maximum number of animated emojis reached.
+	ErrMaxAnimatedEmojis httputil.ErrorCode = 30018
+	// ErrMaxServerMembers is code 30019: Maximum number of server members reached.
+	ErrMaxServerMembers httputil.ErrorCode = 30019
+	// ErrMaxServerCategories is code 30030: Maximum number of server categories has been reached (5).
+	ErrMaxServerCategories httputil.ErrorCode = 30030
+	// ErrGuildAlreadyHasTemplate is code 30031: Guild already has a template.
+	ErrGuildAlreadyHasTemplate httputil.ErrorCode = 30031
+	// ErrMaxThreadParticipants is code 30033: Max number of thread participants has been reached (1000).
+	ErrMaxThreadParticipants httputil.ErrorCode = 30033
+	// ErrMaxBansForNonGuildMembers is code 30035: Maximum number of bans for non-guild members have been exceeded.
+	ErrMaxBansForNonGuildMembers httputil.ErrorCode = 30035
+	// ErrMaxBansFetches is code 30037: Maximum number of bans fetches has been reached.
+	ErrMaxBansFetches httputil.ErrorCode = 30037
+	// ErrMaxUncompletedScheduledEvents is code 30038: Maximum number of uncompleted guild scheduled events reached (100).
+	ErrMaxUncompletedScheduledEvents httputil.ErrorCode = 30038
+	// ErrMaxStickers is code 30039: Maximum number of stickers reached.
+	ErrMaxStickers httputil.ErrorCode = 30039
+	// ErrMaxPruneRequests is code 30040: Maximum number of prune requests has been reached. Try again later.
+	ErrMaxPruneRequests httputil.ErrorCode = 30040
+	// ErrMaxGuildWidgetSettingsUpdates is code 30042: Maximum number of guild widget settings updates has been reached. Try again later.
+	ErrMaxGuildWidgetSettingsUpdates httputil.ErrorCode = 30042
+	// ErrUnauthorized is code 40001: Unauthorized. Provide a valid token and try again.
+	ErrUnauthorized httputil.ErrorCode = 40001
+	// ErrAccountVerificationRequired is code 40002: You need to verify your account in order to perform this action.
+	ErrAccountVerificationRequired httputil.ErrorCode = 40002
+	// ErrOpeningDirectMessagesTooFast is code 40003: You are opening direct messages too fast.
+	ErrOpeningDirectMessagesTooFast httputil.ErrorCode = 40003
+	// ErrRequestEntityTooLarge is code 40005: Request entity too large. Try sending something smaller in size.
+	ErrRequestEntityTooLarge httputil.ErrorCode = 40005
+	// ErrFeatureTemporarilyDisabled is code 40006: This feature has been temporarily disabled server-side.
+	ErrFeatureTemporarilyDisabled httputil.ErrorCode = 40006
+	// ErrUserBannedFromGuild is code 40007: The user is banned from this guild.
+	ErrUserBannedFromGuild httputil.ErrorCode = 40007
+	// ErrTargetUserNotConnectedToVoice is code 40032: Target user is not connected to voice.
+	ErrTargetUserNotConnectedToVoice httputil.ErrorCode = 40032
+	// ErrMessageAlreadyCrossposted is code 40033: This message has already been crossposted.
+	ErrMessageAlreadyCrossposted httputil.ErrorCode = 40033
+	// ErrApplicationCommandNameExists is code 40041: An application command with that name already exists.
+	ErrApplicationCommandNameExists httputil.ErrorCode = 40041
+	// ErrInteractionAlreadyAcknowledged is code 40060: Interaction has already been acknowledged.
+	ErrInteractionAlreadyAcknowledged httputil.ErrorCode = 40060
+	// ErrMissingAccess is code 50001: Missing access.
+	ErrMissingAccess httputil.ErrorCode = 50001
+	// ErrInvalidAccountType is code 50002: Invalid account type.
+	ErrInvalidAccountType httputil.ErrorCode = 50002
+	// ErrCannotExecuteOnDMChannel is code 50003: Cannot execute action on a DM channel.
+	ErrCannotExecuteOnDMChannel httputil.ErrorCode = 50003
+	// ErrGuildWidgetDisabled is code 50004: Guild widget disabled.
+	ErrGuildWidgetDisabled httputil.ErrorCode = 50004
+	// ErrCannotEditMessageByOtherUser is code 50005: Cannot edit a message authored by another user.
+	ErrCannotEditMessageByOtherUser httputil.ErrorCode = 50005
+	// ErrCannotSendEmptyMessage is code 50006: Cannot send an empty message.
+	ErrCannotSendEmptyMessage httputil.ErrorCode = 50006
+	// ErrCannotSendMessagesToUser is code 50007: Cannot send messages to this user.
+	ErrCannotSendMessagesToUser httputil.ErrorCode = 50007
+	// ErrCannotSendMessagesInVoiceChannel is code 50008: Cannot send messages in a voice channel.
+	ErrCannotSendMessagesInVoiceChannel httputil.ErrorCode = 50008
+	// ErrChannelVerificationLevelTooHigh is code 50009: Channel verification level is too high for you to gain access.
+	ErrChannelVerificationLevelTooHigh httputil.ErrorCode = 50009
+	// ErrOAuth2ApplicationNoBot is code 50010: OAuth2 application does not have a bot.
+	ErrOAuth2ApplicationNoBot httputil.ErrorCode = 50010
+	// ErrOAuth2ApplicationLimitReached is code 50011: OAuth2 application limit reached.
+	ErrOAuth2ApplicationLimitReached httputil.ErrorCode = 50011
+	// ErrInvalidOAuth2State is code 50012: Invalid OAuth2 state.
+	ErrInvalidOAuth2State httputil.ErrorCode = 50012
+	// ErrMissingPermissions is code 50013: You lack permissions to perform that action.
+	ErrMissingPermissions httputil.ErrorCode = 50013
+	// ErrInvalidAuthenticationToken is code 50014: Invalid authentication token provided.
+	ErrInvalidAuthenticationToken httputil.ErrorCode = 50014
+	// ErrNoteTooLong is code 50015: Note was too long.
+	ErrNoteTooLong httputil.ErrorCode = 50015
+	// ErrInvalidMessageDeleteCount is code 50016: Provided too few or too many messages to delete. Must provide at least 2 and fewer than 100 messages to delete.
+	ErrInvalidMessageDeleteCount httputil.ErrorCode = 50016
+	// ErrMessagePinnedInOtherChannel is code 50019: A message can only be pinned to the channel it was sent in.
+	ErrMessagePinnedInOtherChannel httputil.ErrorCode = 50019
+	// ErrInvalidInviteCode is code 50020: Invite code was either invalid or taken.
+	ErrInvalidInviteCode httputil.ErrorCode = 50020
+	// ErrCannotExecuteOnSystemMessage is code 50021: Cannot execute action on a system message.
+	ErrCannotExecuteOnSystemMessage httputil.ErrorCode = 50021
+	// ErrCannotExecuteOnChannelType is code 50024: Cannot execute action on this channel type.
+	ErrCannotExecuteOnChannelType httputil.ErrorCode = 50024
+	// ErrInvalidOAuth2AccessToken is code 50025: Invalid OAuth2 access token provided.
+	ErrInvalidOAuth2AccessToken httputil.ErrorCode = 50025
+	// ErrMissingOAuth2Scope is code 50026: Missing required OAuth2 scope.
+	ErrMissingOAuth2Scope httputil.ErrorCode = 50026
+	// ErrInvalidWebhookToken is code 50027: Invalid webhook token provided.
+	ErrInvalidWebhookToken httputil.ErrorCode = 50027
+	// ErrInvalidRole is code 50028: Invalid role.
+	ErrInvalidRole httputil.ErrorCode = 50028
+	// ErrInvalidRecipients is code 50033: Invalid Recipient(s).
+	ErrInvalidRecipients httputil.ErrorCode = 50033
+	// ErrMessageTooOldToBulkDelete is code 50034: A message provided was too old to bulk delete.
+	ErrMessageTooOldToBulkDelete httputil.ErrorCode = 50034
+	// ErrInvalidFormBody is code 50035: Invalid form body (returned for both application/json and multipart/form-data bodies), or invalid Content-Type provided.
+	ErrInvalidFormBody httputil.ErrorCode = 50035
+	// ErrInviteAcceptedToGuildWithoutBot is code 50036: An invite was accepted to a guild the application's bot is not in.
+	ErrInviteAcceptedToGuildWithoutBot httputil.ErrorCode = 50036
+	// ErrInvalidAPIVersion is code 50041: Invalid API version provided.
+	ErrInvalidAPIVersion httputil.ErrorCode = 50041
+	// ErrFileExceedsMaxSize is code 50045: File uploaded exceeds the maximum size.
+	ErrFileExceedsMaxSize httputil.ErrorCode = 50045
+	// ErrInvalidFileUploaded is code 50046: Invalid file uploaded.
+	ErrInvalidFileUploaded httputil.ErrorCode = 50046
+	// ErrCannotSelfRedeemGift is code 50054: Cannot self-redeem this gift.
+	ErrCannotSelfRedeemGift httputil.ErrorCode = 50054
+	// ErrInvalidGuild is code 50055: Invalid Guild.
+	ErrInvalidGuild httputil.ErrorCode = 50055
+	// ErrInvalidMessageType is code 50068: Invalid message type.
+	ErrInvalidMessageType httputil.ErrorCode = 50068
+	// ErrPaymentSourceRequired is code 50070: Payment source required to redeem gift.
+	ErrPaymentSourceRequired httputil.ErrorCode = 50070
+	// ErrCannotDeleteCommunityChannel is code 50074: Cannot delete a channel required for Community guilds.
+	ErrCannotDeleteCommunityChannel httputil.ErrorCode = 50074
+	// ErrInvalidStickerSent is code 50081: Invalid sticker sent.
+	ErrInvalidStickerSent httputil.ErrorCode = 50081
+	// ErrThreadArchived is code 50083: Tried to perform an operation on an archived thread, such as editing a message or adding a user to the thread.
+	ErrThreadArchived httputil.ErrorCode = 50083
+	// ErrInvalidThreadNotificationSettings is code 50084: Invalid thread notification settings.
+	ErrInvalidThreadNotificationSettings httputil.ErrorCode = 50084
+	// ErrBeforeEarlierThanThreadCreation is code 50085: 'before' value is earlier than the thread creation date.
+	ErrBeforeEarlierThanThreadCreation httputil.ErrorCode = 50085
+	// ErrCommunityServerChannelsMustBeText is code 50086: Community server channels must be text channels.
+	ErrCommunityServerChannelsMustBeText httputil.ErrorCode = 50086
+	// ErrServerNotAvailableInLocation is code 50095: This server is not available in your location.
+	ErrServerNotAvailableInLocation httputil.ErrorCode = 50095
+	// ErrMonetizationRequired is code 50097: This server needs monetization enabled in order to perform this action.
+	ErrMonetizationRequired httputil.ErrorCode = 50097
+	// ErrBoostsRequired is code 50101: This server needs more boosts to perform this action.
+	ErrBoostsRequired httputil.ErrorCode = 50101
+	// ErrInvalidJSON is code 50109: The request body contains invalid JSON.
+	ErrInvalidJSON httputil.ErrorCode = 50109
+	// ErrTwoFactorRequired is code 60003: Two factor is required for this operation.
+	ErrTwoFactorRequired httputil.ErrorCode = 60003
+	// ErrNoUsersWithDiscordTag is code 80004: No users with DiscordTag exist.
+	ErrNoUsersWithDiscordTag httputil.ErrorCode = 80004
+	// ErrReactionBlocked is code 90001: Reaction was blocked.
+	ErrReactionBlocked httputil.ErrorCode = 90001
+	// ErrAPIResourceOverloaded is code 130000: API resource is currently overloaded. Try again a little later.
+	ErrAPIResourceOverloaded httputil.ErrorCode = 130000
+	// ErrStageAlreadyOpen is code 150006: The Stage is already open.
+	ErrStageAlreadyOpen httputil.ErrorCode = 150006
+	// ErrCannotReplyWithoutReadHistory is code 160002: Cannot reply without permission to read message history.
+	ErrCannotReplyWithoutReadHistory httputil.ErrorCode = 160002
+	// ErrThreadAlreadyCreated is code 160004: A thread has already been created for this message.
+	ErrThreadAlreadyCreated httputil.ErrorCode = 160004
+	// ErrThreadLocked is code 160005: Thread is locked.
+	ErrThreadLocked httputil.ErrorCode = 160005
+	// ErrMaxActiveThreads is code 160006: Maximum number of active threads reached.
+	ErrMaxActiveThreads httputil.ErrorCode = 160006
+	// ErrMaxActiveAnnouncementThreads is code 160007: Maximum number of active announcement threads reached.
+	ErrMaxActiveAnnouncementThreads httputil.ErrorCode = 160007
+	// ErrInvalidLottieJSON is code 170001: Invalid JSON for uploaded Lottie file.
+	ErrInvalidLottieJSON httputil.ErrorCode = 170001
+	// ErrLottieRasterizedImages is code 170002: Uploaded Lotties cannot contain rasterized images such as PNG or JPEG.
+	ErrLottieRasterizedImages httputil.ErrorCode = 170002
+	// ErrStickerMaxFramerateExceeded is code 170003: Sticker maximum framerate exceeded.
+	ErrStickerMaxFramerateExceeded httputil.ErrorCode = 170003
+	// ErrStickerMaxFramesExceeded is code 170004: Sticker frame count exceeds maximum of 1000 frames.
+	ErrStickerMaxFramesExceeded httputil.ErrorCode = 170004
+	// ErrLottieAnimationMaxDimensionsExceeded is code 170005: Lottie animation maximum dimensions exceeded.
+	ErrLottieAnimationMaxDimensionsExceeded httputil.ErrorCode = 170005
+	// ErrStickerFramerateOutOfRange is code 170006: Sticker frame rate is either too small or too large.
+	ErrStickerFramerateOutOfRange httputil.ErrorCode = 170006
+	// ErrStickerAnimationDurationExceeded is code 170007: Sticker animation duration exceeds maximum of 5 seconds.
+	ErrStickerAnimationDurationExceeded httputil.ErrorCode = 170007
+	// ErrCannotUpdateFinishedEvent is code 180000: Cannot update a finished event.
+	ErrCannotUpdateFinishedEvent httputil.ErrorCode = 180000
+	// ErrFailedToCreateStageForEvent is code 180002: Failed to create stage needed for stage event.
+	ErrFailedToCreateStageForEvent httputil.ErrorCode = 180002
+)
+
+var errorDescriptions = map[httputil.ErrorCode]string{
+	ErrUnknownAccount:                        "Unknown account",
+	ErrUnknownApplication:                    "Unknown application",
+	ErrUnknownChannel:                        "Unknown channel",
+	ErrUnknownGuild:                          "Unknown guild",
+	ErrUnknownIntegration:                    "Unknown integration",
+	ErrUnknownInvite:                         "Unknown invite",
+	ErrUnknownMember:                         "Unknown member",
+	ErrUnknownMessage:                        "Unknown message",
+	ErrUnknownPermissionOverwrite:            "Unknown permission overwrite",
+	ErrUnknownProvider:                       "Unknown provider",
+	ErrUnknownRole:                           "Unknown role",
+	ErrUnknownToken:                          "Unknown token",
+	ErrUnknownUser:                           "Unknown user",
+	ErrUnknownEmoji:                          "Unknown emoji",
+	ErrUnknownWebhook:                        "Unknown webhook",
+	ErrUnknownWebhookService:                 "Unknown webhook service",
+	ErrUnknownSession:                        "Unknown session",
+	ErrUnknownBan:                            "Unknown ban",
+	ErrUnknownSKU:                            "Unknown SKU",
+	ErrUnknownStoreListing:                   "Unknown Store Listing",
+	ErrUnknownEntitlement:                    "Unknown entitlement",
+	ErrUnknownBuild:                          "Unknown build",
+	ErrUnknownLobby:                          "Unknown lobby",
+	ErrUnknownBranch:                         "Unknown branch",
+	ErrUnknownStoreDirectoryLayout:           "Unknown store directory layout",
+	ErrUnknownRedistributable:                "Unknown redistributable",
+	ErrUnknownGiftCode:                       "Unknown gift code",
+	ErrUnknownStream:                         "Unknown stream",
+	ErrUnknownPremiumServerSubscribeCooldown: "Unknown premium server subscribe cooldown",
+	ErrUnknownGuildTemplate:                  "Unknown guild template",
+	ErrUnknownDiscoverableServerCategory:     "Unknown discoverable server category",
+	ErrUnknownSticker:                        "Unknown sticker",
+	ErrUnknownInteraction:                    "Unknown interaction",
+	ErrUnknownApplicationCommand:             "Unknown application command",
+	ErrUnknownVoiceState:                     "Unknown voice state",
+	ErrUnknownApplicationCommandPermissions:  "Unknown application command permissions",
+	ErrUnknownStageInstance:                  "Unknown Stage Instance",
+	ErrUnknownGuildMemberVerificationForm:    "Unknown Guild Member Verification Form",
+	ErrUnknownGuildWelcomeScreen:             "Unknown Guild Welcome Screen",
+	ErrUnknownGuildScheduledEvent:            "Unknown Guild Scheduled Event",
+	ErrUnknownGuildScheduledEventUser:        "Unknown Guild Scheduled Event User",
+	ErrBotsCannotUseEndpoint:                 "Bots cannot use this endpoint",
+	ErrOnlyBotsCanUseEndpoint:                "Only bots can use this endpoint",
+	ErrExplicitContentCannotBeSent:           "Explicit content cannot be sent to the desired recipient(s)",
+	ErrNotAuthorizedForApplication:           "You are not authorized to perform this action on this application",
+	ErrSlowmodeRateLimit:                     "This action cannot be performed due to slowmode rate limit",
+	ErrOnlyOwnerCanPerformAction:             "Only the owner of this account can perform this action",
+	ErrAnnouncementRateLimit:                 "This message cannot be edited due to announcement rate limits",
+	ErrChannelWriteRateLimit:                 "The channel you are writing has hit the write rate limit",
+	ErrDisallowedWords:                       "Your Stage topic, server name, server description, or channel names contain words that are not allowed",
+	ErrGuildPremiumTooLow:                    "Guild premium subscription level too low",
+	ErrMaxGuilds:                             "Maximum number of guilds reached (100)",
+	ErrMaxFriends:                            "Maximum number of friends reached (1000)",
+	ErrMaxPins:                               "Maximum number of pins reached for the channel (50)",
+	ErrMaxRecipients:                         "Maximum number of recipients reached (10)",
+	ErrMaxRoles:                              "Maximum number of guild roles reached (250)",
+	ErrMaxWebhooks:                           "Maximum number of webhooks reached (10)",
+	ErrMaxEmojis:                             "Maximum number of emojis reached",
+	ErrMaxReactions:                          "Maximum number of reactions reached (20)",
+	ErrMaxChannels:                           "Maximum number of guild channels reached (500)",
+	ErrMaxAttachments:                        "Maximum number of attachments in a message reached (10)",
+	ErrMaxInvites:                            "Maximum number of invites reached (1000)",
+	ErrMaxAnimatedEmojis:                     "Maximum number of animated emojis reached",
+	ErrMaxServerMembers:                      "Maximum number of server members reached",
+	ErrMaxServerCategories:                   "Maximum number of server categories has been reached (5)",
+	ErrGuildAlreadyHasTemplate:               "Guild already has a template",
+	ErrMaxThreadParticipants:                 "Max number of thread participants has been reached (1000)",
+	ErrMaxBansForNonGuildMembers:             "Maximum number of bans for non-guild members have been exceeded",
+	ErrMaxBansFetches:                        "Maximum number of bans fetches has been reached",
+	ErrMaxUncompletedScheduledEvents:         "Maximum number of uncompleted guild scheduled events reached (100)",
+	ErrMaxStickers:                           "Maximum number of stickers reached",
+	ErrMaxPruneRequests:                      "Maximum number of prune requests has been reached. Try again later",
+	ErrMaxGuildWidgetSettingsUpdates:         "Maximum number of guild widget settings updates has been reached. Try again later",
+	ErrUnauthorized:                          "Unauthorized. Provide a valid token and try again",
+	ErrAccountVerificationRequired:           "You need to verify your account in order to perform this action",
+	ErrOpeningDirectMessagesTooFast:          "You are opening direct messages too fast",
+	ErrRequestEntityTooLarge:                 "Request entity too large. Try sending something smaller in size",
+	ErrFeatureTemporarilyDisabled:            "This feature has been temporarily disabled server-side",
+	ErrUserBannedFromGuild:                   "The user is banned from this guild",
+	ErrTargetUserNotConnectedToVoice:         "Target user is not connected to voice",
+	ErrMessageAlreadyCrossposted:             "This message has already been crossposted",
+	ErrApplicationCommandNameExists:          "An application command with that name already exists",
+	ErrInteractionAlreadyAcknowledged:        "Interaction has already been acknowledged",
+	ErrMissingAccess:                         "Missing access",
+	ErrInvalidAccountType:                    "Invalid account type",
+	ErrCannotExecuteOnDMChannel:              "Cannot execute action on a DM channel",
+	ErrGuildWidgetDisabled:                   "Guild widget disabled",
+	ErrCannotEditMessageByOtherUser:          "Cannot edit a message authored by another user",
+	ErrCannotSendEmptyMessage:                "Cannot send an empty message",
+	ErrCannotSendMessagesToUser:              "Cannot send messages to this user",
+	ErrCannotSendMessagesInVoiceChannel:      "Cannot send messages in a voice channel",
+	ErrChannelVerificationLevelTooHigh:       "Channel verification level is too high for you to gain access",
+	ErrOAuth2ApplicationNoBot:                "OAuth2 application does not have a bot",
+	ErrOAuth2ApplicationLimitReached:         "OAuth2 application limit reached",
+	ErrInvalidOAuth2State:                    "Invalid OAuth2 state",
+	ErrMissingPermissions:                    "You lack permissions to perform that action",
+	ErrInvalidAuthenticationToken:            "Invalid authentication token provided",
+	ErrNoteTooLong:                           "Note was too long",
+	ErrInvalidMessageDeleteCount:             "Provided too few or too many messages to delete. Must provide at least 2 and fewer than 100 messages to delete",
+	ErrMessagePinnedInOtherChannel:           "A message can only be pinned to the channel it was sent in",
+	ErrInvalidInviteCode:                     "Invite code was either invalid or taken",
+	ErrCannotExecuteOnSystemMessage:          "Cannot execute action on a system message",
+	ErrCannotExecuteOnChannelType:            "Cannot execute action on this channel type",
+	ErrInvalidOAuth2AccessToken:              "Invalid OAuth2 access token provided",
+	ErrMissingOAuth2Scope:                    "Missing required OAuth2 scope",
+	ErrInvalidWebhookToken:                   "Invalid webhook token provided",
+	ErrInvalidRole:                           "Invalid role",
+	ErrInvalidRecipients:                     "Invalid Recipient(s)",
+	ErrMessageTooOldToBulkDelete:             "A message provided was too old to bulk delete",
+	ErrInvalidFormBody:                       "Invalid form body (returned for both application/json and multipart/form-data bodies), or invalid Content-Type provided",
+	ErrInviteAcceptedToGuildWithoutBot:       "An invite was accepted to a guild the application's bot is not in",
+	ErrInvalidAPIVersion:                     "Invalid API version provided",
+	ErrFileExceedsMaxSize:                    "File uploaded exceeds the maximum size",
+	ErrInvalidFileUploaded:                   "Invalid file uploaded",
+	ErrCannotSelfRedeemGift:                  "Cannot self-redeem this gift",
+	ErrInvalidGuild:                          "Invalid Guild",
+	ErrInvalidMessageType:                    "Invalid message type",
+	ErrPaymentSourceRequired:                 "Payment source required to redeem gift",
+	ErrCannotDeleteCommunityChannel:          "Cannot delete a channel required for Community guilds",
+	ErrInvalidStickerSent:                    "Invalid sticker sent",
+	ErrThreadArchived:                        "Tried to perform an operation on an archived thread, such as editing a message or adding a user to the thread",
+	ErrInvalidThreadNotificationSettings:     "Invalid thread notification settings",
+	ErrBeforeEarlierThanThreadCreation:       "'before' value is earlier than the thread creation date",
+	ErrCommunityServerChannelsMustBeText:     "Community server channels must be text channels",
+	ErrServerNotAvailableInLocation:          "This server is not available in your location",
+	ErrMonetizationRequired:                  "This server needs monetization enabled in order to perform this action",
+	ErrBoostsRequired:                        "This server needs more boosts to perform this action",
+	ErrInvalidJSON:                           "The request body contains invalid JSON",
+	ErrTwoFactorRequired:                     "Two factor is required for this operation",
+	ErrNoUsersWithDiscordTag:                 "No users with DiscordTag exist",
+	ErrReactionBlocked:                       "Reaction was blocked",
+	ErrAPIResourceOverloaded:                 "API resource is currently overloaded. Try again a little later",
+	ErrStageAlreadyOpen:                      "The Stage is already open",
+	ErrCannotReplyWithoutReadHistory:         "Cannot reply without permission to read message history",
+	ErrThreadAlreadyCreated:                  "A thread has already been created for this message",
+	ErrThreadLocked:                          "Thread is locked",
+	ErrMaxActiveThreads:                      "Maximum number of active threads reached",
+	ErrMaxActiveAnnouncementThreads:          "Maximum number of active announcement threads reached",
+	ErrInvalidLottieJSON:                     "Invalid JSON for uploaded Lottie file",
+	ErrLottieRasterizedImages:                "Uploaded Lotties cannot contain rasterized images such as PNG or JPEG",
+	ErrStickerMaxFramerateExceeded:           "Sticker maximum framerate exceeded",
+	ErrStickerMaxFramesExceeded:              "Sticker frame count exceeds maximum of 1000 frames",
+	ErrLottieAnimationMaxDimensionsExceeded:  "Lottie animation maximum dimensions exceeded",
+	ErrStickerFramerateOutOfRange:            "Sticker frame rate is either too small or too large",
+	ErrStickerAnimationDurationExceeded:      "Sticker animation duration exceeds maximum of 5 seconds",
+	ErrCannotUpdateFinishedEvent:             "Cannot update a finished event",
+	ErrFailedToCreateStageForEvent:           "Failed to create stage needed for stage event",
+}
@@ -0,0 +1,11 @@
+package api
+
+import "github.com/diamondburned/arikawa/v3/utils/httputil"
+
+//go:generate go run ../utils/cmd/generrorcodes -i error_codes.tsv -o error_codes.go
+
+// ErrorDescription returns Discord's description of the given JSON error
+// code. An empty string is returned if the code is unknown.
+func ErrorDescription(code httputil.ErrorCode) string {
+	return errorDescriptions[code]
+}
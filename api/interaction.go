@@ -54,6 +54,26 @@ func (resp InteractionResponse) WriteMultipart(body *multipart.Writer) error {
 	return sendpart.Write(body, resp, resp.Data.Files)
 }
 
+// NewModalResponse creates a modal response with text inputs derived from the
+// given struct. The submission can be read back into the same struct using
+// discord.ModalInteraction's Unmarshal method. See discord.MarshalTextInputs
+// for the supported struct tags.
+func NewModalResponse(customID discord.ComponentID, title string, v interface{}) (*InteractionResponse, error) {
+	components, err := discord.MarshalTextInputs(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InteractionResponse{
+		Type: ModalResponse,
+		Data: &InteractionResponseData{
+			CustomID:   option.NewNullableString(string(customID)),
+			Title:      option.NewNullableString(title),
+			Components: &components,
+		},
+	}, nil
+}
+
 // InteractionResponseData is InteractionApplicationCommandCallbackData in the
 // official documentation.
 type InteractionResponseData struct {
@@ -90,8 +90,14 @@ func NewLimiter(prefix string) *Limiter {
 	}
 }
 
+// BucketKey returns the key of the bucket that the given URL path is rate
+// limited by.
+func (l *Limiter) BucketKey(path string) string {
+	return ParseBucketKey(strings.TrimPrefix(path, l.Prefix))
+}
+
 func (l *Limiter) getBucket(path string, store bool) *bucket {
-	path = ParseBucketKey(strings.TrimPrefix(path, l.Prefix))
+	path = l.BucketKey(path)
 
 	l.bucketMu.Lock()
 	defer l.bucketMu.Unlock()
@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
@@ -9,6 +10,9 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
@@ -84,6 +88,30 @@ type InteractionErrorFunc func(w http.ResponseWriter, r *http.Request, code int,
 type InteractionServer struct {
 	ErrorFunc InteractionErrorFunc
 
+	// ErrorLog is called with errors that happen while delivering the
+	// responses of handlers that missed the Timeout.
+	ErrorLog func(err error) // default: log.Println
+
+	// Client is used to deliver the responses of handlers that missed the
+	// Timeout by editing the deferred response. If it's nil, then the server
+	// always waits for the handler, since late responses couldn't be
+	// delivered.
+	Client *api.Client
+	// Timeout is the duration to wait for the handler before responding with
+	// a deferred response, since Discord fails the interaction if it's not
+	// responded to within 3 seconds. It's only used if Client is set.
+	//
+	// Only message responses can be delivered late. If a late handler returns
+	// nil or a response of another type, such as a modal, then the deferred
+	// message is deleted instead, and the latter is reported to ErrorLog.
+	Timeout time.Duration // 2.5s
+	// MaxSkew is the maximum difference between the signed timestamp and the
+	// current time. Requests outside of this are rejected to prevent replay
+	// attacks.
+	MaxSkew time.Duration // 5m
+	// MaxBodySize is the maximum size of a request body in bytes.
+	MaxBodySize int64 // 1MB
+
 	interactionHandler InteractionHandler
 	httpHandler        http.Handler
 	pubkey             ed25519.PublicKey
@@ -101,6 +129,10 @@ func NewInteractionServer(pubkey string, handler InteractionHandler) (*Interacti
 		ErrorFunc: func(w http.ResponseWriter, r *http.Request, code int, err error) {
 			writeError(w, code, err)
 		},
+		ErrorLog:           func(err error) { log.Println("webhook:", err) },
+		Timeout:            2500 * time.Millisecond,
+		MaxSkew:            5 * time.Minute,
+		MaxBodySize:        1 << 20,
 		interactionHandler: handler,
 		httpHandler:        nil,
 		pubkey:             pubkeyB,
@@ -116,6 +148,10 @@ func NewInteractionServer(pubkey string, handler InteractionHandler) (*Interacti
 
 // ServeHTTP implements http.Handler.
 func (s *InteractionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.MaxBodySize)
+	}
+
 	s.httpHandler.ServeHTTP(w, r)
 }
 
@@ -125,34 +161,154 @@ func (s *InteractionServer) handle(w http.ResponseWriter, r *http.Request) {
 		var ev discord.InteractionEvent
 
 		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			if isBodyTooLarge(err) {
+				s.ErrorFunc(w, r, http.StatusRequestEntityTooLarge, err)
+				return
+			}
 			s.ErrorFunc(w, r, 400, errors.Wrap(err, "cannot decode interaction body"))
 			return
 		}
 
-		switch ev.Data.(type) {
-		case *discord.PingInteraction:
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(api.InteractionResponse{
+		if _, ok := ev.Data.(*discord.PingInteraction); ok {
+			s.writeResponse(w, r, &api.InteractionResponse{
 				Type: api.PongInteraction,
 			})
+			return
 		}
 
-		resp := s.interactionHandler.HandleInteraction(&ev)
+		resp := s.handleInteraction(r.Context(), &ev)
 		if resp != nil && resp.Type != api.PongInteraction {
-			if resp.NeedsMultipart() {
-				body := multipart.NewWriter(w)
-				w.Header().Set("Content-Type", body.FormDataContentType())
-				resp.WriteMultipart(body)
-			} else {
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(resp)
-			}
+			s.writeResponse(w, r, resp)
 		}
 	default:
 		s.ErrorFunc(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
 	}
 }
 
+// handleInteraction calls the handler and waits for it until the timeout or
+// until ctx is done. If the handler misses the deadline, then a deferred
+// response is returned instead, and the handler's response is delivered later
+// using the client. Without a client, the handler is always waited for.
+func (s *InteractionServer) handleInteraction(
+	ctx context.Context, ev *discord.InteractionEvent) *api.InteractionResponse {
+
+	if s.Timeout <= 0 || s.Client == nil {
+		return s.interactionHandler.HandleInteraction(ev)
+	}
+
+	respCh := make(chan *api.InteractionResponse, 1)
+	go func() { respCh <- s.interactionHandler.HandleInteraction(ev) }()
+
+	timer := time.NewTimer(s.Timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-respCh:
+		return resp
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	if _, ok := ev.Data.(*discord.AutocompleteInteraction); ok {
+		// Autocompletion cannot be deferred, so respond with no choices.
+		return &api.InteractionResponse{
+			Type: api.AutocompleteResult,
+			Data: &api.InteractionResponseData{
+				Choices: api.AutocompleteStringChoices{},
+			},
+		}
+	}
+
+	go s.deliverLate(ev, respCh)
+	return deferredResponse(ev, 0)
+}
+
+// deliverLate waits for the late response of a handler and edits the deferred
+// response with it. Responses that can't be delivered late remove the deferred
+// response instead.
+func (s *InteractionServer) deliverLate(ev *discord.InteractionEvent, respCh <-chan *api.InteractionResponse) {
+	resp := <-respCh
+	if resp == nil {
+		s.removeDeferred(ev)
+		return
+	}
+
+	switch resp.Type {
+	case api.MessageInteractionWithSource, api.UpdateMessage:
+		if resp.Data == nil {
+			s.removeDeferred(ev)
+			return
+		}
+
+		_, err := s.Client.EditInteractionResponse(ev.AppID, ev.Token, api.EditInteractionResponseData{
+			Content:         resp.Data.Content,
+			Embeds:          resp.Data.Embeds,
+			Components:      resp.Data.Components,
+			AllowedMentions: resp.Data.AllowedMentions,
+			Files:           resp.Data.Files,
+		})
+		if err != nil {
+			s.ErrorLog(errors.Wrap(err, "failed to deliver late response"))
+		}
+	case api.DeferredMessageInteractionWithSource, api.DeferredMessageUpdate:
+		// The handler deferred by itself, so it'll follow up on its own.
+	default:
+		s.ErrorLog(errors.Errorf("response type %d cannot be delivered late", resp.Type))
+		s.removeDeferred(ev)
+	}
+}
+
+// removeDeferred deletes the deferred message of a command, so that it doesn't
+// stay in the loading state. Deferred updates of components are left alone,
+// since their original response is the message the component is on.
+func (s *InteractionServer) removeDeferred(ev *discord.InteractionEvent) {
+	if _, ok := ev.Data.(discord.ComponentInteraction); ok {
+		return
+	}
+
+	if err := s.Client.DeleteInteractionResponse(ev.AppID, ev.Token); err != nil {
+		s.ErrorLog(errors.Wrap(err, "failed to delete deferred response"))
+	}
+}
+
+// writeResponse encodes the whole response before writing anything, so that
+// encoding errors can still be reported.
+func (s *InteractionServer) writeResponse(
+	w http.ResponseWriter, r *http.Request, resp *api.InteractionResponse) {
+
+	var body bytes.Buffer
+	var contentType string
+
+	if resp.NeedsMultipart() {
+		multipartBody := multipart.NewWriter(&body)
+		contentType = multipartBody.FormDataContentType()
+
+		if err := resp.WriteMultipart(multipartBody); err != nil {
+			s.ErrorFunc(w, r, 500, errors.Wrap(err, "cannot write multipart response"))
+			return
+		}
+		if err := multipartBody.Close(); err != nil {
+			s.ErrorFunc(w, r, 500, errors.Wrap(err, "cannot write multipart response"))
+			return
+		}
+	} else {
+		contentType = "application/json"
+
+		if err := json.NewEncoder(&body).Encode(resp); err != nil {
+			s.ErrorFunc(w, r, 500, errors.Wrap(err, "cannot encode response"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body.Bytes())
+}
+
+func isBodyTooLarge(err error) bool {
+	// http.MaxBytesError is only available since Go 1.19.
+	return err != nil && strings.Contains(err.Error(), "request body too large")
+}
+
 // withVerification was written thanks to @bsdlp and their code
 // https://github.com/bsdlp/discord-interactions-go/blob/a2ba844/interactions/verify_example_test.go#L63.
 func (s *InteractionServer) withVerification(next http.Handler) http.Handler {
@@ -185,6 +341,10 @@ func (s *InteractionServer) withVerification(next http.Handler) http.Handler {
 		msg.WriteString(timestamp)
 
 		if _, err := io.Copy(&msg, r.Body); err != nil {
+			if isBodyTooLarge(err) {
+				s.ErrorFunc(w, r, http.StatusRequestEntityTooLarge, err)
+				return
+			}
 			s.ErrorFunc(w, r, 500, errors.Wrap(err, "cannot read body"))
 			return
 		}
@@ -194,6 +354,26 @@ func (s *InteractionServer) withVerification(next http.Handler) http.Handler {
 			return
 		}
 
+		// Only check the timestamp after the signature is verified, since the
+		// timestamp is signed as well.
+		if s.MaxSkew > 0 {
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				s.ErrorFunc(w, r, 400, errors.Wrap(err, "invalid X-Signature-Timestamp"))
+				return
+			}
+
+			skew := time.Since(time.Unix(unix, 0))
+			if skew < 0 {
+				skew = -skew
+			}
+
+			if skew > s.MaxSkew {
+				s.ErrorFunc(w, r, 401, errors.New("X-Signature-Timestamp is too old or too new"))
+				return
+			}
+		}
+
 		// Return the request body for use.
 		body := msg.Bytes()[len(timestamp):]
 		r.Body = io.NopCloser(bytes.NewReader(body))
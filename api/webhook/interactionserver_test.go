@@ -0,0 +1,318 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/arikawa/v3/utils/sendpart"
+)
+
+type testSigner struct {
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestSigner(t *testing.T) testSigner {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("failed to generate key:", err)
+	}
+	return testSigner{pub, priv}
+}
+
+func (s testSigner) newServer(t *testing.T, h InteractionHandler) *InteractionServer {
+	srv, err := NewInteractionServer(hex.EncodeToString(s.pub), h)
+	if err != nil {
+		t.Fatal("failed to create server:", err)
+	}
+	return srv
+}
+
+// request creates a signed request with the given timestamp.
+func (s testSigner) request(body string, timestamp time.Time) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	sig := ed25519.Sign(s.priv, []byte(ts+body))
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+	r.Header.Set("X-Signature-Timestamp", ts)
+	return r
+}
+
+const (
+	pingBody    = `{"id":"1","type":1,"token":"token","version":1}`
+	commandBody = `{"id":"1","application_id":"2","type":2,"token":"token","version":1,"data":{"id":"3","name":"ping"}}`
+)
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) api.InteractionResponse {
+	t.Helper()
+
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+
+	var resp api.InteractionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal("failed to decode response:", err)
+	}
+	return resp
+}
+
+func TestInteractionServerVerification(t *testing.T) {
+	signer := newTestSigner(t)
+	srv := signer.newServer(t, InteractionHandlerFunc(
+		func(ev *discord.InteractionEvent) *api.InteractionResponse { return nil },
+	))
+
+	tests := []struct {
+		name string
+		req  func() *http.Request
+		code int
+	}{
+		{
+			name: "valid",
+			req:  func() *http.Request { return signer.request(pingBody, time.Now()) },
+			code: 200,
+		},
+		{
+			name: "stale timestamp",
+			req: func() *http.Request {
+				return signer.request(pingBody, time.Now().Add(-10*time.Minute))
+			},
+			code: 401,
+		},
+		{
+			name: "future timestamp",
+			req: func() *http.Request {
+				return signer.request(pingBody, time.Now().Add(10*time.Minute))
+			},
+			code: 401,
+		},
+		{
+			name: "bad signature",
+			req: func() *http.Request {
+				r := signer.request(pingBody, time.Now())
+				r.Header.Set("X-Signature-Timestamp", strconv.FormatInt(time.Now().Unix()+1, 10))
+				return r
+			},
+			code: 401,
+		},
+		{
+			name: "too large",
+			req: func() *http.Request {
+				body := `{"id":"1","type":1,"token":"` + strings.Repeat("a", 2<<20) + `"}`
+				return signer.request(body, time.Now())
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, test.req())
+
+			if w.Code != test.code {
+				t.Fatalf("expected status %d, got %d: %s", test.code, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestInteractionServerPing(t *testing.T) {
+	var called int32
+
+	signer := newTestSigner(t)
+	srv := signer.newServer(t, InteractionHandlerFunc(
+		func(ev *discord.InteractionEvent) *api.InteractionResponse {
+			atomic.StoreInt32(&called, 1)
+			return nil
+		},
+	))
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, signer.request(pingBody, time.Now()))
+
+	if resp := decodeResponse(t, w); resp.Type != api.PongInteraction {
+		t.Fatalf("expected pong, got %#v", resp)
+	}
+
+	if atomic.LoadInt32(&called) == 1 {
+		t.Fatal("handler was called for ping")
+	}
+}
+
+func TestInteractionServerTimeout(t *testing.T) {
+	release := make(chan struct{})
+
+	recorder, client := newRecordingClient(t)
+
+	signer := newTestSigner(t)
+	srv := signer.newServer(t, InteractionHandlerFunc(
+		func(ev *discord.InteractionEvent) *api.InteractionResponse {
+			<-release
+			return nil
+		},
+	))
+	srv.Client = client
+	srv.Timeout = 10 * time.Millisecond
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, signer.request(commandBody, time.Now()))
+
+	if resp := decodeResponse(t, w); resp.Type != api.DeferredMessageInteractionWithSource {
+		t.Fatalf("expected deferred response, got %#v", resp)
+	}
+
+	// Wait for the late response, so that it doesn't outlive the client.
+	close(release)
+	for len(recorder.recorded()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInteractionServerTimeoutNoClient(t *testing.T) {
+	signer := newTestSigner(t)
+	srv := signer.newServer(t, InteractionHandlerFunc(
+		func(ev *discord.InteractionEvent) *api.InteractionResponse {
+			time.Sleep(50 * time.Millisecond)
+			return &api.InteractionResponse{
+				Type: api.MessageInteractionWithSource,
+				Data: &api.InteractionResponseData{Content: option.NewNullableString("slow")},
+			}
+		},
+	))
+	srv.Timeout = 10 * time.Millisecond
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, signer.request(commandBody, time.Now()))
+
+	resp := decodeResponse(t, w)
+	if resp.Type != api.MessageInteractionWithSource || resp.Data == nil ||
+		resp.Data.Content == nil || resp.Data.Content.Val != "slow" {
+
+		t.Fatalf("expected the handler's response, got %#v", resp)
+	}
+}
+
+func TestInteractionServerDeliverLate(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     *api.InteractionResponse
+		requests []string
+		errors   int
+	}{
+		{
+			name: "message",
+			resp: &api.InteractionResponse{
+				Type: api.MessageInteractionWithSource,
+				Data: &api.InteractionResponseData{Content: option.NewNullableString("hi")},
+			},
+			requests: []string{"PATCH /webhooks/2/token/messages/@original"},
+		},
+		{
+			name:     "nil",
+			resp:     nil,
+			requests: []string{"DELETE /webhooks/2/token/messages/@original"},
+		},
+		{
+			name:     "modal",
+			resp:     &api.InteractionResponse{Type: api.ModalResponse},
+			requests: []string{"DELETE /webhooks/2/token/messages/@original"},
+			errors:   1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder, client := newRecordingClient(t)
+
+			release := make(chan struct{})
+			done := make(chan struct{})
+
+			signer := newTestSigner(t)
+			srv := signer.newServer(t, InteractionHandlerFunc(
+				func(ev *discord.InteractionEvent) *api.InteractionResponse {
+					<-release
+					return test.resp
+				},
+			))
+			srv.Client = client
+			srv.Timeout = 10 * time.Millisecond
+
+			var errs int32
+			srv.ErrorLog = func(err error) { atomic.AddInt32(&errs, 1) }
+
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, signer.request(commandBody, time.Now()))
+			decodeResponse(t, w)
+
+			go func() {
+				defer close(done)
+				for len(recorder.recorded()) < len(test.requests) {
+					time.Sleep(time.Millisecond)
+				}
+			}()
+
+			close(release)
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the late response")
+			}
+
+			if got := recorder.recorded(); !reflect.DeepEqual(got, test.requests) {
+				t.Fatalf("unexpected requests %q, expected %q", got, test.requests)
+			}
+			if got := atomic.LoadInt32(&errs); int(got) != test.errors {
+				t.Fatalf("expected %d errors, got %d", test.errors, got)
+			}
+		})
+	}
+}
+
+func TestInteractionServerMultipartError(t *testing.T) {
+	signer := newTestSigner(t)
+	srv := signer.newServer(t, InteractionHandlerFunc(
+		func(ev *discord.InteractionEvent) *api.InteractionResponse {
+			return &api.InteractionResponse{
+				Type: api.MessageInteractionWithSource,
+				Data: &api.InteractionResponseData{
+					Files: []sendpart.File{{Name: "a.txt", Reader: errReader{}}},
+				},
+			}
+		},
+	))
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, signer.request(commandBody, time.Now()))
+
+	if w.Code != 500 {
+		t.Fatalf("expected status 500, got %d: %s", w.Code, w.Body)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("--")) {
+		t.Fatalf("partial multipart body was written: %s", w.Body)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
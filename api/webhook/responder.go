@@ -0,0 +1,342 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+const (
+	// InitialResponseTimeout is the duration that Discord waits for the
+	// initial response of an interaction before it fails.
+	InitialResponseTimeout = 3 * time.Second
+	// InteractionTokenLifetime is the duration that an interaction token can
+	// be used for editing the original response and sending followups.
+	InteractionTokenLifetime = 15 * time.Minute
+)
+
+var (
+	// ErrInteractionExpired is returned when responding to an interaction
+	// whose token has expired.
+	ErrInteractionExpired = errors.New("interaction token has expired")
+	// ErrAlreadyAcknowledged is returned when trying to send a response that
+	// can only be the initial response, such as autocomplete results or
+	// modals, after the interaction has already been acknowledged.
+	ErrAlreadyAcknowledged = errors.New("interaction has already been acknowledged")
+	// ErrResponderDone is returned when trying to respond to an interaction
+	// after its handler returned without acknowledging it.
+	ErrResponderDone = errors.New("interaction handler returned without responding")
+)
+
+// AutoDeferHandler is an InteractionHandler that runs its handler in the
+// background with a Responder. If the handler doesn't respond within the
+// threshold, then the interaction is deferred, and the first reply after that
+// edits the deferred response.
+type AutoDeferHandler struct {
+	// Client is used for editing the original response and sending followups.
+	Client *api.Client
+	// Handler is called in its own goroutine for every interaction.
+	Handler func(*Responder)
+	// Threshold is the duration to wait for the handler's initial response
+	// before deferring. It must be less than InitialResponseTimeout.
+	Threshold time.Duration // 2s
+	// Flags are the message flags of the deferred response, such as
+	// discord.EphemeralMessage. The flags of a deferred response cannot be
+	// changed later.
+	Flags discord.MessageFlags
+	// SendDirectly, if true, sends the initial response using Client instead
+	// of returning it from HandleInteraction. This guarantees that the initial
+	// response is delivered before any followup, but it must only be used for
+	// interactions that come from the gateway.
+	SendDirectly bool
+}
+
+var _ InteractionHandler = (*AutoDeferHandler)(nil)
+
+// NewAutoDeferHandler creates a new AutoDeferHandler with the default
+// threshold.
+func NewAutoDeferHandler(client *api.Client, f func(*Responder)) *AutoDeferHandler {
+	return &AutoDeferHandler{
+		Client:    client,
+		Handler:   f,
+		Threshold: 2 * time.Second,
+	}
+}
+
+// HandleInteraction implements InteractionHandler.
+func (h *AutoDeferHandler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
+	r := newResponder(h.Client, ev, h.SendDirectly)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Handler(r)
+	}()
+
+	timer := time.NewTimer(h.Threshold)
+	defer timer.Stop()
+
+	select {
+	case resp := <-r.initial:
+		return r.handOff(resp)
+	case <-done:
+		select {
+		case resp := <-r.initial:
+			return r.handOff(resp)
+		default:
+		}
+		if r.finish() {
+			return nil
+		}
+		// Raced with a late response.
+		return r.handOff(<-r.initial)
+	case <-timer.C:
+		if !r.deferNow() {
+			// Raced with the handler's initial response.
+			return r.handOff(<-r.initial)
+		}
+		return r.handOff(deferredResponse(ev, h.Flags))
+	}
+}
+
+type responderState uint8
+
+const (
+	responderNew responderState = iota
+	responderDeferred
+	responderResponded
+	responderDone
+)
+
+// Responder keeps track of the response lifecycle of a single interaction. It
+// knows whether the interaction has been acknowledged and routes each reply to
+// the initial response, the deferred original response or a followup. A
+// Responder is thread-safe.
+type Responder struct {
+	// Event is the interaction event being responded to.
+	Event *discord.InteractionEvent
+
+	client *api.Client
+	direct bool
+	expiry time.Time
+
+	mut   sync.Mutex
+	state responderState
+
+	// initial receives the initial response for HandleInteraction.
+	initial chan *api.InteractionResponse
+	// acked is closed once the initial response has been handed off.
+	acked  chan struct{}
+	ackErr error
+}
+
+func newResponder(client *api.Client, ev *discord.InteractionEvent, direct bool) *Responder {
+	created := ev.ID.Time()
+	if !ev.ID.IsValid() {
+		created = time.Now()
+	}
+
+	return &Responder{
+		Event:   ev,
+		client:  client,
+		direct:  direct,
+		expiry:  created.Add(InteractionTokenLifetime),
+		initial: make(chan *api.InteractionResponse, 1),
+		acked:   make(chan struct{}),
+	}
+}
+
+// Deadline returns the time that the interaction token expires.
+func (r *Responder) Deadline() time.Time {
+	return r.expiry
+}
+
+// Expired returns true if the interaction token has expired.
+func (r *Responder) Expired() bool {
+	return !time.Now().Before(r.expiry)
+}
+
+// Acknowledged returns true if the interaction has been responded to or
+// deferred.
+func (r *Responder) Acknowledged() bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	return r.state == responderDeferred || r.state == responderResponded
+}
+
+// Defer defers the interaction if it hasn't been acknowledged yet. The flags
+// are used for the deferred response.
+func (r *Responder) Defer(flags discord.MessageFlags) error {
+	if r.Expired() {
+		return ErrInteractionExpired
+	}
+
+	r.mut.Lock()
+	if r.state != responderNew {
+		done := r.state == responderDone
+		r.mut.Unlock()
+
+		if done {
+			return ErrResponderDone
+		}
+		return nil
+	}
+	r.state = responderDeferred
+	r.mut.Unlock()
+
+	r.initial <- deferredResponse(r.Event, flags)
+	return r.waitAck()
+}
+
+// Reply replies with a message. The reply becomes the initial response, the
+// deferred response or a followup, depending on the state of the interaction.
+// The message is returned if it's known.
+func (r *Responder) Reply(data api.InteractionResponseData) (*discord.Message, error) {
+	return r.Respond(api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &data,
+	})
+}
+
+// Respond responds to the interaction. If the interaction hasn't been
+// acknowledged, then resp is the initial response. If it was deferred, then
+// the deferred response is edited with resp's data. Otherwise, a followup
+// message is sent. The message is returned if it's known, which is not the
+// case for the initial response.
+func (r *Responder) Respond(resp api.InteractionResponse) (*discord.Message, error) {
+	if r.Expired() {
+		return nil, ErrInteractionExpired
+	}
+
+	r.mut.Lock()
+	state := r.state
+
+	switch resp.Type {
+	case api.DeferredMessageInteractionWithSource, api.DeferredMessageUpdate:
+		r.mut.Unlock()
+
+		var flags discord.MessageFlags
+		if resp.Data != nil {
+			flags = resp.Data.Flags
+		}
+		return nil, r.Defer(flags)
+	}
+
+	switch state {
+	case responderNew:
+		r.state = responderResponded
+		r.mut.Unlock()
+
+		r.initial <- &resp
+		return nil, r.waitAck()
+
+	case responderDone:
+		r.mut.Unlock()
+		return nil, ErrResponderDone
+	}
+
+	switch resp.Type {
+	case api.PongInteraction, api.AutocompleteResult, api.ModalResponse:
+		r.mut.Unlock()
+		return nil, ErrAlreadyAcknowledged
+	}
+
+	r.state = responderResponded
+	r.mut.Unlock()
+
+	if err := r.waitAck(); err != nil {
+		return nil, err
+	}
+
+	var data api.InteractionResponseData
+	if resp.Data != nil {
+		data = *resp.Data
+	}
+
+	if state == responderDeferred {
+		m, err := r.client.EditInteractionResponse(r.Event.AppID, r.Event.Token,
+			api.EditInteractionResponseData{
+				Content:         data.Content,
+				Embeds:          data.Embeds,
+				Components:      data.Components,
+				AllowedMentions: data.AllowedMentions,
+				Files:           data.Files,
+			},
+		)
+		return m, errors.Wrap(err, "failed to edit deferred response")
+	}
+
+	m, err := r.client.FollowUpInteraction(r.Event.AppID, r.Event.Token, data)
+	return m, errors.Wrap(err, "failed to send followup")
+}
+
+// waitAck waits until the initial response is handed off and returns the
+// error of sending it, if it was sent directly.
+func (r *Responder) waitAck() error {
+	<-r.acked
+	return r.ackErr
+}
+
+// deferNow marks the interaction as deferred if it hasn't been acknowledged.
+// False is returned if the handler already responded.
+func (r *Responder) deferNow() bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.state != responderNew {
+		return false
+	}
+
+	r.state = responderDeferred
+	return true
+}
+
+// finish marks the responder as done if the interaction hasn't been
+// acknowledged. False is returned if the handler already responded.
+func (r *Responder) finish() bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.state != responderNew {
+		return false
+	}
+
+	r.state = responderDone
+	close(r.acked)
+	return true
+}
+
+// handOff hands off the initial response. If the responder sends directly,
+// then the response is sent using the client and nil is returned.
+func (r *Responder) handOff(resp *api.InteractionResponse) *api.InteractionResponse {
+	defer close(r.acked)
+
+	if !r.direct {
+		return resp
+	}
+
+	if err := r.client.RespondInteraction(r.Event.ID, r.Event.Token, *resp); err != nil {
+		r.ackErr = errors.Wrap(err, "failed to send initial response")
+	}
+	return nil
+}
+
+// deferredResponse returns the deferred response for the interaction.
+// Component interactions defer updating their message, while everything else
+// defers a new message.
+func deferredResponse(ev *discord.InteractionEvent, flags discord.MessageFlags) *api.InteractionResponse {
+	typ := api.DeferredMessageInteractionWithSource
+	if _, ok := ev.Data.(discord.ComponentInteraction); ok {
+		typ = api.DeferredMessageUpdate
+	}
+
+	return &api.InteractionResponse{
+		Type: typ,
+		Data: &api.InteractionResponseData{Flags: flags},
+	}
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// recordingServer records the method and path of every request.
+type recordingServer struct {
+	mut      sync.Mutex
+	requests []string
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mut.Unlock()
+
+	if strings.Contains(r.URL.Path, "/callback") {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Write([]byte(`{"id":"1"}`))
+}
+
+func (s *recordingServer) recorded() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func newRecordingClient(t *testing.T) (*recordingServer, *api.Client) {
+	s := &recordingServer{}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	webhooks, interactions := api.EndpointWebhooks, api.EndpointInteractions
+	api.EndpointWebhooks = srv.URL + "/webhooks/"
+	api.EndpointInteractions = srv.URL + "/interactions/"
+	t.Cleanup(func() {
+		api.EndpointWebhooks, api.EndpointInteractions = webhooks, interactions
+	})
+
+	return s, api.NewClient("")
+}
+
+func newTestEvent() *discord.InteractionEvent {
+	return &discord.InteractionEvent{
+		ID:    discord.InteractionID(discord.NewSnowflake(time.Now())),
+		AppID: 1,
+		Token: "token",
+		Data:  &discord.CommandInteraction{Name: "test"},
+	}
+}
+
+func reply(content string) api.InteractionResponseData {
+	return api.InteractionResponseData{Content: option.NewNullableString(content)}
+}
+
+func TestAutoDeferHandlerImmediate(t *testing.T) {
+	s, client := newRecordingClient(t)
+
+	done := make(chan error, 1)
+	h := NewAutoDeferHandler(client, func(r *Responder) {
+		if _, err := r.Reply(reply("first")); err != nil {
+			done <- err
+			return
+		}
+		_, err := r.Reply(reply("second"))
+		done <- err
+	})
+
+	resp := h.HandleInteraction(newTestEvent())
+	if resp == nil || resp.Type != api.MessageInteractionWithSource {
+		t.Fatalf("unexpected initial response %#v", resp)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal("failed to reply:", err)
+	}
+
+	expect := []string{"POST /webhooks/1/token"}
+	if strings.Join(s.recorded(), ",") != strings.Join(expect, ",") {
+		t.Fatalf("unexpected requests %q", s.recorded())
+	}
+}
+
+func TestAutoDeferHandlerDeferred(t *testing.T) {
+	s, client := newRecordingClient(t)
+
+	done := make(chan error, 1)
+	h := NewAutoDeferHandler(client, func(r *Responder) {
+		time.Sleep(50 * time.Millisecond)
+
+		if !r.Acknowledged() {
+			done <- errors.New("interaction was not deferred")
+			return
+		}
+		if _, err := r.Reply(reply("first")); err != nil {
+			done <- err
+			return
+		}
+		_, err := r.Reply(reply("second"))
+		done <- err
+	})
+	h.Threshold = 10 * time.Millisecond
+	h.Flags = discord.EphemeralMessage
+
+	resp := h.HandleInteraction(newTestEvent())
+	if resp == nil || resp.Type != api.DeferredMessageInteractionWithSource {
+		t.Fatalf("unexpected initial response %#v", resp)
+	}
+	if resp.Data.Flags != discord.EphemeralMessage {
+		t.Fatalf("unexpected deferred flags %v", resp.Data.Flags)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal("failed to reply:", err)
+	}
+
+	expect := []string{
+		"PATCH /webhooks/1/token/messages/@original",
+		"POST /webhooks/1/token",
+	}
+	if strings.Join(s.recorded(), ",") != strings.Join(expect, ",") {
+		t.Fatalf("unexpected requests %q", s.recorded())
+	}
+}
+
+func TestAutoDeferHandlerDirect(t *testing.T) {
+	s, client := newRecordingClient(t)
+
+	done := make(chan error, 1)
+	h := NewAutoDeferHandler(client, func(r *Responder) {
+		r.Reply(reply("first"))
+		_, err := r.Reply(reply("second"))
+		done <- err
+	})
+	h.SendDirectly = true
+
+	if resp := h.HandleInteraction(newTestEvent()); resp != nil {
+		t.Fatalf("unexpected returned response %#v", resp)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal("failed to reply:", err)
+	}
+
+	recorded := s.recorded()
+	if len(recorded) != 2 ||
+		!strings.HasSuffix(recorded[0], "/callback") || recorded[1] != "POST /webhooks/1/token" {
+		t.Fatalf("unexpected requests %q", recorded)
+	}
+}
+
+func TestResponderErrors(t *testing.T) {
+	_, client := newRecordingClient(t)
+
+	t.Run("expired", func(t *testing.T) {
+		ev := newTestEvent()
+		ev.ID = discord.InteractionID(discord.NewSnowflake(time.Now().Add(-time.Hour)))
+
+		errCh := make(chan error, 1)
+		h := NewAutoDeferHandler(client, func(r *Responder) {
+			_, err := r.Reply(reply("late"))
+			errCh <- err
+		})
+		h.HandleInteraction(ev)
+
+		if err := <-errCh; err != ErrInteractionExpired {
+			t.Fatalf("expected ErrInteractionExpired, got %v", err)
+		}
+	})
+
+	t.Run("autocomplete after defer", func(t *testing.T) {
+		errCh := make(chan error, 1)
+		h := NewAutoDeferHandler(client, func(r *Responder) {
+			r.Defer(0)
+			_, err := r.Respond(api.InteractionResponse{Type: api.AutocompleteResult})
+			errCh <- err
+		})
+		h.HandleInteraction(newTestEvent())
+
+		if err := <-errCh; err != ErrAlreadyAcknowledged {
+			t.Fatalf("expected ErrAlreadyAcknowledged, got %v", err)
+		}
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		h := NewAutoDeferHandler(client, func(r *Responder) {})
+		if resp := h.HandleInteraction(newTestEvent()); resp != nil {
+			t.Fatalf("unexpected response %#v", resp)
+		}
+	})
+}
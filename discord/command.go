@@ -447,6 +447,10 @@ type StringOption struct {
 	DescriptionLocalizations StringLocales  `json:"description_localizations,omitempty"`
 	Required                 bool           `json:"required"`
 	Choices                  []StringChoice `json:"choices,omitempty"`
+	// MinLength is the minimum allowed length, from 0 to 6000.
+	MinLength option.Int `json:"min_length,omitempty"`
+	// MaxLength is the maximum allowed length, from 1 to 6000.
+	MaxLength option.Int `json:"max_length,omitempty"`
 	// Autocomplete must not be true if Choices are present.
 	Autocomplete bool `json:"autocomplete"`
 	// LocalizedOptionName is only populated when this is received from
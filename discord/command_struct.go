@@ -0,0 +1,576 @@
+package discord
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// NewCommandFromStruct creates a new chat input command with the options
+// derived from the given struct. See MarshalCommandOptions for the supported
+// struct tags.
+func NewCommandFromStruct(name, description string, v interface{}) (Command, error) {
+	options, err := MarshalCommandOptions(v)
+	if err != nil {
+		return Command{}, err
+	}
+
+	return Command{
+		Type:        ChatInputCommand,
+		Name:        name,
+		Description: description,
+		Options:     options,
+	}, nil
+}
+
+// MarshalCommandOptions derives the command options from the given struct or
+// struct pointer. It is the reverse of CommandInteractionOptions.Unmarshal, so
+// the same struct can be used both to register a command and to read its
+// invocations.
+//
+// The option name and optionality follow the same rules as Unmarshal: the
+// "discord" struct tag names the option, a "?" suffix or a pointer type makes
+// the option optional, and all other options are required. Struct fields
+// become subcommands, and struct fields that only contain struct fields become
+// subcommand groups.
+//
+// Struct Tags
+//
+// The following struct tags are supported:
+//
+//    - description:"..." sets the description of the option. Discord
+//      requires one, so the field name is used if it's missing.
+//    - min:"n" and max:"n" set the minimum and maximum value of integer and
+//      number options, or the minimum and maximum length of string options.
+//    - choices:"name=value;..." sets the choices of string, integer and
+//      number options. A choice without a name uses its value as the name.
+//    - channel_types:"text,voice,..." sets the allowed channel types of
+//      channel options. Both names such as "text", "voice", "category",
+//      "news", "public_thread", "stage" and "forum" and numbers are allowed.
+//    - autocomplete:"true" enables autocompletion.
+//    - name_localizations:"lang=...;..." and
+//      description_localizations:"lang=...;..." set the localizations.
+//
+// For example:
+//
+//    type BanOptions struct {
+//        User   discord.UserID `discord:"user" description:"The user to ban."`
+//        Days   int            `discord:"days?" min:"0" max:"7"`
+//        Reason *string        `discord:"reason" description:"Why?"`
+//    }
+//
+// Required options are always placed before optional options, as Discord
+// requires.
+func MarshalCommandOptions(v interface{}) (CommandOptions, error) {
+	rt := reflect.TypeOf(v)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, errors.New("v is not a struct or a pointer to a struct")
+	}
+
+	switch {
+	case isSubcommandGroupStruct(rt):
+		return marshalSubcommands(rt, true)
+	case isSubcommandStruct(rt):
+		return marshalSubcommands(rt, false)
+	}
+
+	values, err := marshalOptionValues(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(CommandOptions, len(values))
+	for i, value := range values {
+		options[i] = value
+	}
+	return options, nil
+}
+
+// isSubcommandStruct returns true if the struct only has subcommands.
+func isSubcommandStruct(rt reflect.Type) bool {
+	var n int
+	for _, field := range commandFields(rt) {
+		if structFieldType(field.Type) == nil {
+			return false
+		}
+		n++
+	}
+	return n > 0
+}
+
+// isSubcommandGroupStruct returns true if the struct only has subcommand
+// groups, which are structs that only have subcommands.
+func isSubcommandGroupStruct(rt reflect.Type) bool {
+	if !isSubcommandStruct(rt) {
+		return false
+	}
+	for _, field := range commandFields(rt) {
+		if !isSubcommandStruct(structFieldType(field.Type)) {
+			return false
+		}
+	}
+	return true
+}
+
+// structFieldType returns the struct type of the given field type or nil if
+// it's not a struct.
+func structFieldType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+type commandField struct {
+	reflect.StructField
+	name     string
+	required bool
+}
+
+// commandFields returns the fields of the struct that are options, following
+// the same rules as CommandInteractionOptions.Unmarshal.
+func commandFields(rt reflect.Type) []commandField {
+	fields := make([]commandField, 0, rt.NumField())
+
+	for i := 0; i < rt.NumField(); i++ {
+		fieldStruct := rt.Field(i)
+		if !fieldStruct.IsExported() {
+			continue
+		}
+
+		field := commandField{
+			StructField: fieldStruct,
+			name:        fieldStruct.Tag.Get("discord"),
+			required:    fieldStruct.Type.Kind() != reflect.Ptr,
+		}
+
+		switch field.name {
+		case "-":
+			continue
+		case "?":
+			field.name = fieldStruct.Name + "?"
+		case "":
+			field.name = fieldStruct.Name
+		}
+
+		if strings.HasSuffix(field.name, "?") {
+			field.name = strings.TrimSuffix(field.name, "?")
+			field.required = false
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func marshalSubcommands(rt reflect.Type, groups bool) (CommandOptions, error) {
+	fields := commandFields(rt)
+	options := make(CommandOptions, 0, len(fields))
+
+	for _, field := range fields {
+		ft := structFieldType(field.Type)
+
+		nameLocales, descLocales, err := fieldLocales(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if groups {
+			subs, err := marshalSubcommands(ft, false)
+			if err != nil {
+				return nil, errors.Wrapf(err, "subcommand group %q", field.name)
+			}
+
+			group := &SubcommandGroupOption{
+				OptionName:               field.name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              fieldDescription(field),
+				DescriptionLocalizations: descLocales,
+				Subcommands:              make([]*SubcommandOption, len(subs)),
+			}
+			for i, sub := range subs {
+				group.Subcommands[i] = sub.(*SubcommandOption)
+			}
+
+			options = append(options, group)
+			continue
+		}
+
+		values, err := marshalOptionValues(ft)
+		if err != nil {
+			return nil, errors.Wrapf(err, "subcommand %q", field.name)
+		}
+
+		options = append(options, &SubcommandOption{
+			OptionName:               field.name,
+			OptionNameLocalizations:  nameLocales,
+			Description:              fieldDescription(field),
+			DescriptionLocalizations: descLocales,
+			Options:                  values,
+		})
+	}
+
+	return options, nil
+}
+
+func marshalOptionValues(rt reflect.Type) ([]CommandOptionValue, error) {
+	fields := commandFields(rt)
+	values := make([]CommandOptionValue, 0, len(fields))
+
+	for _, field := range fields {
+		if structFieldType(field.Type) != nil {
+			return nil, fmt.Errorf(
+				"field %s (%q) is a subcommand mixed with other options", field.Name, field.name)
+		}
+
+		value, err := marshalOptionValue(field)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	// Discord requires required options to be placed first.
+	sort.SliceStable(values, func(i, j int) bool {
+		return optionRequired(values[i]) && !optionRequired(values[j])
+	})
+
+	return values, nil
+}
+
+func marshalOptionValue(field commandField) (CommandOptionValue, error) {
+	nameLocales, descLocales, err := fieldLocales(field)
+	if err != nil {
+		return nil, err
+	}
+
+	name := field.name
+	desc := fieldDescription(field)
+	tag := field.Tag
+
+	fieldt := field.Type
+	if fieldt.Kind() == reflect.Ptr {
+		fieldt = fieldt.Elem()
+	}
+
+	autocomplete := tag.Get("autocomplete") == "true"
+
+	if optType, ok := optionSupportedSnowflakeTypes[fieldt]; ok {
+		switch optType {
+		case ChannelOptionType:
+			channelTypes, err := parseChannelTypes(tag.Get("channel_types"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "option %q has invalid channel_types", name)
+			}
+			return &ChannelOption{
+				OptionName:               name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              desc,
+				DescriptionLocalizations: descLocales,
+				Required:                 field.required,
+				ChannelTypes:             channelTypes,
+			}, nil
+		case UserOptionType:
+			return &UserOption{
+				OptionName:               name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              desc,
+				DescriptionLocalizations: descLocales,
+				Required:                 field.required,
+			}, nil
+		case RoleOptionType:
+			return &RoleOption{
+				OptionName:               name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              desc,
+				DescriptionLocalizations: descLocales,
+				Required:                 field.required,
+			}, nil
+		case AttachmentOptionType:
+			return &AttachmentOption{
+				OptionName:               name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              desc,
+				DescriptionLocalizations: descLocales,
+				Required:                 field.required,
+			}, nil
+		case MentionableOptionType:
+			return &MentionableOption{
+				OptionName:               name,
+				OptionNameLocalizations:  nameLocales,
+				Description:              desc,
+				DescriptionLocalizations: descLocales,
+				Required:                 field.required,
+			}, nil
+		}
+	}
+
+	choices, err := parseTagPairs(tag.Get("choices"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "option %q has invalid choices", name)
+	}
+
+	switch optionKindMap[fieldt.Kind()] {
+	case StringOptionType:
+		opt := &StringOption{
+			OptionName:               name,
+			OptionNameLocalizations:  nameLocales,
+			Description:              desc,
+			DescriptionLocalizations: descLocales,
+			Required:                 field.required,
+			Autocomplete:             autocomplete,
+		}
+		if opt.MinLength, err = parseTagInt(tag, "min"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		if opt.MaxLength, err = parseTagInt(tag, "max"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		for _, choice := range choices {
+			opt.Choices = append(opt.Choices, StringChoice{
+				Name:  choice[0],
+				Value: choice[1],
+			})
+		}
+		return opt, nil
+
+	case IntegerOptionType:
+		opt := &IntegerOption{
+			OptionName:               name,
+			OptionNameLocalizations:  nameLocales,
+			Description:              desc,
+			DescriptionLocalizations: descLocales,
+			Required:                 field.required,
+			Autocomplete:             autocomplete,
+		}
+		if opt.Min, err = parseTagInt(tag, "min"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		if opt.Max, err = parseTagInt(tag, "max"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		for _, choice := range choices {
+			v, err := strconv.Atoi(choice[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "option %q has invalid choice %q", name, choice[0])
+			}
+			opt.Choices = append(opt.Choices, IntegerChoice{
+				Name:  choice[0],
+				Value: v,
+			})
+		}
+		return opt, nil
+
+	case NumberOptionType:
+		opt := &NumberOption{
+			OptionName:               name,
+			OptionNameLocalizations:  nameLocales,
+			Description:              desc,
+			DescriptionLocalizations: descLocales,
+			Required:                 field.required,
+			Autocomplete:             autocomplete,
+		}
+		if opt.Min, err = parseTagFloat(tag, "min"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		if opt.Max, err = parseTagFloat(tag, "max"); err != nil {
+			return nil, errors.Wrapf(err, "option %q", name)
+		}
+		for _, choice := range choices {
+			v, err := strconv.ParseFloat(choice[1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "option %q has invalid choice %q", name, choice[0])
+			}
+			opt.Choices = append(opt.Choices, NumberChoice{
+				Name:  choice[0],
+				Value: v,
+			})
+		}
+		return opt, nil
+
+	case BooleanOptionType:
+		return &BooleanOption{
+			OptionName:               name,
+			OptionNameLocalizations:  nameLocales,
+			Description:              desc,
+			DescriptionLocalizations: descLocales,
+			Required:                 field.required,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("field %s (%q) has unknown type %s", field.Name, name, fieldt)
+}
+
+func optionRequired(opt CommandOptionValue) bool {
+	switch opt := opt.(type) {
+	case *StringOption:
+		return opt.Required
+	case *IntegerOption:
+		return opt.Required
+	case *BooleanOption:
+		return opt.Required
+	case *UserOption:
+		return opt.Required
+	case *ChannelOption:
+		return opt.Required
+	case *RoleOption:
+		return opt.Required
+	case *MentionableOption:
+		return opt.Required
+	case *NumberOption:
+		return opt.Required
+	case *AttachmentOption:
+		return opt.Required
+	default:
+		return false
+	}
+}
+
+func fieldDescription(field commandField) string {
+	if desc := field.Tag.Get("description"); desc != "" {
+		return desc
+	}
+	return field.Name
+}
+
+func fieldLocales(field commandField) (name, desc StringLocales, err error) {
+	name, err = parseLocales(field.Tag.Get("name_localizations"))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "option %q has invalid name_localizations", field.name)
+	}
+
+	desc, err = parseLocales(field.Tag.Get("description_localizations"))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "option %q has invalid description_localizations", field.name)
+	}
+
+	return name, desc, nil
+}
+
+func parseLocales(tag string) (StringLocales, error) {
+	pairs, err := parseTagPairs(tag)
+	if err != nil || len(pairs) == 0 {
+		return nil, err
+	}
+
+	locales := make(StringLocales, len(pairs))
+	for _, pair := range pairs {
+		if pair[0] == pair[1] {
+			return nil, fmt.Errorf("missing language in %q", pair[0])
+		}
+		locales[Language(pair[0])] = pair[1]
+	}
+
+	return locales, nil
+}
+
+// parseTagPairs parses "name=value;name=value" into pairs of name and value. A
+// pair without a name uses its value as the name.
+func parseTagPairs(tag string) ([][2]string, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(tag, ";")
+	pairs := make([][2]string, 0, len(parts))
+
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("empty pair in %q", tag)
+		}
+
+		name, value := part, part
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], part[i+1:]
+		}
+
+		pairs = append(pairs, [2]string{name, value})
+	}
+
+	return pairs, nil
+}
+
+func parseTagInt(tag reflect.StructTag, key string) (option.Int, error) {
+	v := tag.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", key)
+	}
+
+	return option.NewInt(i), nil
+}
+
+func parseTagFloat(tag reflect.StructTag, key string) (option.Float, error) {
+	v := tag.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", key)
+	}
+
+	return option.NewFloat(f), nil
+}
+
+var channelTypeNames = map[string]ChannelType{
+	"text":           GuildText,
+	"dm":             DirectMessage,
+	"voice":          GuildVoice,
+	"group_dm":       GroupDM,
+	"category":       GuildCategory,
+	"news":           GuildNews,
+	"store":          GuildStore,
+	"news_thread":    GuildNewsThread,
+	"public_thread":  GuildPublicThread,
+	"private_thread": GuildPrivateThread,
+	"stage":          GuildStageVoice,
+	"directory":      GuildDirectory,
+	"forum":          GuildForum,
+}
+
+func parseChannelTypes(tag string) ([]ChannelType, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(tag, ",")
+	types := make([]ChannelType, len(parts))
+
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+
+		if t, ok := channelTypeNames[part]; ok {
+			types[i] = t
+			continue
+		}
+
+		t, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("unknown channel type %q", part)
+		}
+
+		types[i] = ChannelType(t)
+	}
+
+	return types, nil
+}
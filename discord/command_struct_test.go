@@ -0,0 +1,197 @@
+package discord
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+func TestMarshalCommandOptions(t *testing.T) {
+	type banOptions struct {
+		Reason  *string   `discord:"reason" description:"Why?" min:"1" max:"100"`
+		User    UserID    `discord:"user" description:"The user to ban." name_localizations:"fr=utilisateur"`
+		Days    int       `discord:"days?" min:"0" max:"7" choices:"None=0;Week=7"`
+		Log     ChannelID `discord:"log?" channel_types:"text,5"`
+		Color   string    `discord:"color?" choices:"red;Blue=blue" autocomplete:"true"`
+		Scale   float64   `discord:"scale?" min:"0.5"`
+		Ignored string    `discord:"-"`
+		Silent  bool
+	}
+
+	options, err := MarshalCommandOptions(banOptions{})
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+
+	expect := CommandOptions{
+		&UserOption{
+			OptionName:              "user",
+			OptionNameLocalizations: StringLocales{"fr": "utilisateur"},
+			Description:             "The user to ban.",
+			Required:                true,
+		},
+		&BooleanOption{
+			OptionName:  "Silent",
+			Description: "Silent",
+			Required:    true,
+		},
+		&StringOption{
+			OptionName:  "reason",
+			Description: "Why?",
+			MinLength:   option.NewInt(1),
+			MaxLength:   option.NewInt(100),
+		},
+		&IntegerOption{
+			OptionName:  "days",
+			Description: "Days",
+			Min:         option.NewInt(0),
+			Max:         option.NewInt(7),
+			Choices: []IntegerChoice{
+				{Name: "None", Value: 0},
+				{Name: "Week", Value: 7},
+			},
+		},
+		&ChannelOption{
+			OptionName:   "log",
+			Description:  "Log",
+			ChannelTypes: []ChannelType{GuildText, GuildNews},
+		},
+		&StringOption{
+			OptionName:  "color",
+			Description: "Color",
+			Choices: []StringChoice{
+				{Name: "red", Value: "red"},
+				{Name: "Blue", Value: "blue"},
+			},
+			Autocomplete: true,
+		},
+		&NumberOption{
+			OptionName:  "scale",
+			Description: "Scale",
+			Min:         option.NewFloat(0.5),
+		},
+	}
+
+	assertJSONEqual(t, expect, options)
+}
+
+func TestMarshalCommandOptionsSubcommands(t *testing.T) {
+	type roleOptions struct {
+		Add *struct {
+			Role RoleID `discord:"role"`
+		} `discord:"add" description:"Add a role."`
+		Remove *struct {
+			Role RoleID `discord:"role"`
+		} `discord:"remove"`
+	}
+
+	var groups struct {
+		Role roleOptions `discord:"role" description:"Manage roles."`
+	}
+
+	options, err := MarshalCommandOptions(&groups)
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+
+	role := &RoleOption{OptionName: "role", Description: "Role", Required: true}
+
+	expect := CommandOptions{
+		&SubcommandGroupOption{
+			OptionName:  "role",
+			Description: "Manage roles.",
+			Subcommands: []*SubcommandOption{
+				{
+					OptionName:  "add",
+					Description: "Add a role.",
+					Options:     []CommandOptionValue{role},
+				},
+				{
+					OptionName:  "remove",
+					Description: "Remove",
+					Options:     []CommandOptionValue{role},
+				},
+			},
+		},
+	}
+
+	assertJSONEqual(t, expect, options)
+
+	// Ensure that the same struct can be used to unmarshal the invocation.
+	invocation := CommandInteractionOptions{{
+		Type: SubcommandGroupOptionType,
+		Name: "role",
+		Options: CommandInteractionOptions{{
+			Type: SubcommandOptionType,
+			Name: "remove",
+			Options: CommandInteractionOptions{{
+				Type:  RoleOptionType,
+				Name:  "role",
+				Value: []byte(`"2"`),
+			}},
+		}},
+	}}
+
+	if err := invocation.Unmarshal(&groups); err != nil {
+		t.Fatal("failed to unmarshal:", err)
+	}
+
+	if groups.Role.Add != nil || groups.Role.Remove == nil || groups.Role.Remove.Role != 2 {
+		t.Fatalf("unexpected unmarshaled options %#v", groups.Role)
+	}
+}
+
+func TestMarshalCommandOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not struct", 1},
+		{"unknown type", struct{ U uint }{}},
+		{"bad min", struct {
+			I int `min:"a"`
+		}{}},
+		{"bad choice", struct {
+			I int `choices:"one=1;two=b"`
+		}{}},
+		{"bad channel type", struct {
+			C ChannelID `channel_types:"text,garden"`
+		}{}},
+		{"mixed subcommand", struct {
+			S   string
+			Sub struct{}
+		}{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := MarshalCommandOptions(test.v); err == nil {
+				t.Fatal("unexpected nil error")
+			}
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, expect, got interface{}) {
+	t.Helper()
+
+	expectJSON, err := json.Marshal(expect)
+	if err != nil {
+		t.Fatal("failed to marshal expected value:", err)
+	}
+
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal("failed to marshal value:", err)
+	}
+
+	var expectV, gotV interface{}
+	json.Unmarshal(expectJSON, &expectV)
+	json.Unmarshal(gotJSON, &gotV)
+
+	if !reflect.DeepEqual(expectV, gotV) {
+		t.Fatalf("unexpected options\nexpected: %s\ngot:      %s", expectJSON, gotJSON)
+	}
+}
@@ -81,6 +81,12 @@ func (c *ContainerComponents) Find(customID ComponentID) Component {
 //
 // Pointer types to any of the above types are also supported and will also
 // implicitly imply optionality.
+//
+// Optional text inputs that are left blank are treated as not found, so their
+// fields are left untouched or set to nil.
+//
+// MarshalTextInputs does the reverse for modals and derives the text inputs
+// from the same struct.
 func (c *ContainerComponents) Unmarshal(v interface{}) error {
 	rv, rt, err := rfutil.StructValue(v)
 	if err != nil {
@@ -108,6 +114,13 @@ func (c *ContainerComponents) Unmarshal(v interface{}) error {
 		fieldv := rv.Field(i)
 		fieldt := fieldStruct.Type
 
+		// Discord submits every text input of a modal, so optional inputs that
+		// are left blank are treated as not found.
+		optional := strings.HasSuffix(name, "?") || fieldt.Kind() == reflect.Ptr
+		if input, ok := component.(*TextInputComponent); ok && optional && input.Value.Val == "" {
+			component = nil
+		}
+
 		if strings.HasSuffix(name, "?") {
 			name = strings.TrimSuffix(name, "?")
 			if component == nil {
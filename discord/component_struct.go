@@ -0,0 +1,156 @@
+package discord
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// MarshalTextInputs derives the components of a modal from the given struct
+// or struct pointer. Each field becomes a TextInputComponent in its own action
+// row. It is the reverse of ContainerComponents.Unmarshal, so the same struct
+// can be used both to send a modal and to read its submission.
+//
+// The custom ID and optionality follow the same rules as Unmarshal: the
+// "discord" struct tag is the custom ID, a "?" suffix or a pointer type makes
+// the input optional, and all other inputs are required. If v is a pointer,
+// then the non-zero field values are used to pre-fill the inputs.
+//
+// There is no struct tag to make an input required or optional, since it could
+// contradict the field's type: Unmarshal would reject the submission of an
+// optional input for a required field that's left blank.
+//
+// Struct Tags
+//
+// The following struct tags are supported:
+//
+//    - label:"..." sets the label of the input. The field name is used if
+//      it's missing.
+//    - style:"short" or style:"paragraph" sets the style of the input. The
+//      default is short.
+//    - min:"n" and max:"n" set the minimum and maximum length of the input.
+//    - placeholder:"..." sets the placeholder of the input.
+//
+// For example:
+//
+//    type ReportModal struct {
+//        Title   string  `discord:"title" label:"Title" max:"100"`
+//        Details *string `discord:"details" style:"paragraph"`
+//        Age     int     `discord:"age?" placeholder:"Optional"`
+//    }
+//
+// Supported types are strings and numbers as well as pointers to them.
+func MarshalTextInputs(v interface{}) (ContainerComponents, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	} else if rv.Kind() == reflect.Ptr {
+		rv = reflect.Zero(rv.Type().Elem())
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("v is not a struct or a pointer to a struct")
+	}
+
+	rt := rv.Type()
+	fields := commandFields(rt)
+	components := make(ContainerComponents, 0, len(fields))
+
+	for _, field := range fields {
+		input, err := marshalTextInput(field, rv.FieldByIndex(field.Index))
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, &ActionRowComponent{input})
+	}
+
+	return components, nil
+}
+
+func marshalTextInput(field commandField, fieldv reflect.Value) (*TextInputComponent, error) {
+	input := &TextInputComponent{
+		CustomID: ComponentID(field.name),
+		Style:    TextInputShortStyle,
+		Label:    field.Tag.Get("label"),
+		Required: field.required,
+	}
+
+	if input.Label == "" {
+		input.Label = field.Name
+	}
+
+	switch style := field.Tag.Get("style"); style {
+	case "", "short":
+	case "paragraph":
+		input.Style = TextInputParagraphStyle
+	default:
+		return nil, fmt.Errorf("input %q has unknown style %q", field.name, style)
+	}
+
+	if placeholder := field.Tag.Get("placeholder"); placeholder != "" {
+		input.Placeholder = option.NewNullableString(placeholder)
+	}
+
+	min, err := parseTagInt(field.Tag, "min")
+	if err != nil {
+		return nil, errors.Wrapf(err, "input %q", field.name)
+	}
+	max, err := parseTagInt(field.Tag, "max")
+	if err != nil {
+		return nil, errors.Wrapf(err, "input %q", field.name)
+	}
+	if min != nil || max != nil {
+		input.LengthLimits = [2]int{0, 4000}
+		if min != nil {
+			input.LengthLimits[0] = *min
+		}
+		if max != nil {
+			input.LengthLimits[1] = *max
+		}
+	}
+
+	if fieldv.Kind() == reflect.Ptr {
+		if fieldv.IsNil() {
+			fieldv = reflect.Zero(fieldv.Type().Elem())
+		} else {
+			fieldv = fieldv.Elem()
+		}
+	}
+
+	var value string
+
+	switch fieldv.Kind() {
+	case reflect.String:
+		value = fieldv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := fieldv.Int(); i != 0 {
+			value = strconv.FormatInt(i, 10)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := fieldv.Uint(); u != 0 {
+			value = strconv.FormatUint(u, 10)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := fieldv.Float(); f != 0 {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	default:
+		return nil, fmt.Errorf("field %s (%q) has unsupported type %s", field.Name, field.name, field.Type)
+	}
+
+	if value != "" {
+		input.Value = option.NewNullableString(value)
+	}
+
+	return input, nil
+}
+
+// Unmarshal unmarshals the submitted values of the modal into the struct
+// pointer v. It is a shortcut for m.Components.Unmarshal(v).
+func (m *ModalInteraction) Unmarshal(v interface{}) error {
+	return m.Components.Unmarshal(v)
+}
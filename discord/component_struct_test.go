@@ -0,0 +1,131 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+type reportModal struct {
+	Title    string  `discord:"title" label:"Title" max:"100" placeholder:"Short summary"`
+	Details  *string `discord:"details" style:"paragraph" min:"10"`
+	Age      int     `discord:"age?" label:"Age"`
+	Internal string  `discord:"-"`
+}
+
+func TestMarshalTextInputs(t *testing.T) {
+	components, err := MarshalTextInputs(&reportModal{Title: "Spam", Age: 20})
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+
+	expect := ContainerComponents{
+		&ActionRowComponent{&TextInputComponent{
+			CustomID:     "title",
+			Style:        TextInputShortStyle,
+			Label:        "Title",
+			LengthLimits: [2]int{0, 100},
+			Required:     true,
+			Value:        option.NewNullableString("Spam"),
+			Placeholder:  option.NewNullableString("Short summary"),
+		}},
+		&ActionRowComponent{&TextInputComponent{
+			CustomID:     "details",
+			Style:        TextInputParagraphStyle,
+			Label:        "Details",
+			LengthLimits: [2]int{10, 4000},
+		}},
+		&ActionRowComponent{&TextInputComponent{
+			CustomID: "age",
+			Style:    TextInputShortStyle,
+			Label:    "Age",
+			Value:    option.NewNullableString("20"),
+		}},
+	}
+
+	assertJSONEqual(t, expect, components)
+}
+
+func TestModalInteractionUnmarshal(t *testing.T) {
+	components, err := MarshalTextInputs(reportModal{})
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+
+	// Simulate the user filling out the modal.
+	values := map[ComponentID]string{
+		"title":   "Spam",
+		"details": "Lots of spam",
+		"age":     "42",
+	}
+	for _, row := range components {
+		for _, c := range *row.(*ActionRowComponent) {
+			input := c.(*TextInputComponent)
+			input.Value = option.NewNullableString(values[input.CustomID])
+		}
+	}
+
+	submission := ModalInteraction{CustomID: "report", Components: components}
+
+	var report reportModal
+	if err := submission.Unmarshal(&report); err != nil {
+		t.Fatal("failed to unmarshal:", err)
+	}
+
+	if report.Title != "Spam" || report.Details == nil || *report.Details != "Lots of spam" || report.Age != 42 {
+		t.Fatalf("unexpected unmarshaled modal %#v", report)
+	}
+}
+
+func TestModalInteractionUnmarshalBlank(t *testing.T) {
+	components, err := MarshalTextInputs(reportModal{})
+	if err != nil {
+		t.Fatal("failed to marshal:", err)
+	}
+
+	// Discord submits the optional inputs that are left blank as well.
+	for _, row := range components {
+		for _, c := range *row.(*ActionRowComponent) {
+			input := c.(*TextInputComponent)
+			input.Value = option.NewNullableString("")
+			if input.CustomID == "title" {
+				input.Value = option.NewNullableString("Spam")
+			}
+		}
+	}
+
+	submission := ModalInteraction{CustomID: "report", Components: components}
+
+	report := reportModal{Age: 1}
+	if err := submission.Unmarshal(&report); err != nil {
+		t.Fatal("failed to unmarshal:", err)
+	}
+
+	if report.Title != "Spam" || report.Details != nil || report.Age != 1 {
+		t.Fatalf("unexpected unmarshaled modal %#v", report)
+	}
+}
+
+func TestMarshalTextInputsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not struct", "a"},
+		{"unsupported type", struct{ B bool }{}},
+		{"unknown style", struct {
+			S string `style:"huge"`
+		}{}},
+		{"bad max", struct {
+			S string `max:"a"`
+		}{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := MarshalTextInputs(test.v); err == nil {
+				t.Fatal("unexpected nil error")
+			}
+		})
+	}
+}
@@ -403,29 +403,37 @@ type CommandInteractionOption struct {
 }
 
 var optionSupportedSnowflakeTypes = map[reflect.Type]CommandOptionType{
-	reflect.TypeOf(ChannelID(0)): ChannelOptionType,
-	reflect.TypeOf(UserID(0)):    UserOptionType,
-	reflect.TypeOf(RoleID(0)):    RoleOptionType,
-	reflect.TypeOf(Snowflake(0)): MentionableOptionType,
+	reflect.TypeOf(ChannelID(0)):    ChannelOptionType,
+	reflect.TypeOf(UserID(0)):       UserOptionType,
+	reflect.TypeOf(RoleID(0)):       RoleOptionType,
+	reflect.TypeOf(AttachmentID(0)): AttachmentOptionType,
+	reflect.TypeOf(Snowflake(0)):    MentionableOptionType,
 }
 
 var optionKindMap = map[reflect.Kind]CommandOptionType{
-	reflect.Int:     NumberOptionType,
-	reflect.Int8:    NumberOptionType,
-	reflect.Int16:   NumberOptionType,
-	reflect.Int32:   NumberOptionType,
-	reflect.Int64:   NumberOptionType,
-	reflect.Uint:    NumberOptionType,
-	reflect.Uint8:   NumberOptionType,
-	reflect.Uint16:  NumberOptionType,
-	reflect.Uint32:  NumberOptionType,
-	reflect.Uint64:  NumberOptionType,
+	reflect.Int:     IntegerOptionType,
+	reflect.Int8:    IntegerOptionType,
+	reflect.Int16:   IntegerOptionType,
+	reflect.Int32:   IntegerOptionType,
+	reflect.Int64:   IntegerOptionType,
 	reflect.Float32: NumberOptionType,
 	reflect.Float64: NumberOptionType,
 	reflect.String:  StringOptionType,
 	reflect.Bool:    BooleanOptionType,
 }
 
+// optionTypeMatches returns true if an option of type got can be unmarshaled
+// into a field that expects the option type expect. Integers and numbers are
+// interchangeable.
+func optionTypeMatches(expect, got CommandOptionType) bool {
+	switch expect {
+	case IntegerOptionType, NumberOptionType:
+		return got == IntegerOptionType || got == NumberOptionType
+	default:
+		return expect == got
+	}
+}
+
 // Unmarshal unmarshals the options into the struct pointer v. Each struct field
 // must be exported and is of a supported type.
 //
@@ -440,10 +448,11 @@ var optionKindMap = map[reflect.Kind]CommandOptionType{
 //    - ChannelID (ChannelOptionType)
 //    - UserID (UserOptionType)
 //    - RoleID (RoleOptionType)
+//    - AttachmentID (AttachmentOptionType)
 //    - Snowflake (MentionableOptionType)
 //    - string (StringOptionType)
 //    - bool (BooleanOptionType)
-//    - int* (int, int8, int16, int32, int64) (NumberOptionType)
+//    - int* (int, int8, int16, int32, int64) (IntegerOptionType)
 //    - float* (float32, float64) (NumberOptionType)
 //    - (any struct and struct pointer) (not Discord-type-checked)
 //
@@ -452,6 +461,9 @@ var optionKindMap = map[reflect.Kind]CommandOptionType{
 //
 // Pointer types to any of the above types are also supported and will also
 // implicitly imply optionality.
+//
+// MarshalCommandOptions does the reverse and derives the command options from
+// the same struct.
 func (o CommandInteractionOptions) Unmarshal(v interface{}) error {
 	return o.unmarshal(reflect.ValueOf(v))
 }
@@ -521,7 +533,7 @@ func (o CommandInteractionOptions) unmarshal(rv reflect.Value) error {
 
 		fieldk := fieldt.Kind()
 		if expectType, ok := optionKindMap[fieldk]; ok {
-			if option.Type != expectType {
+			if !optionTypeMatches(expectType, option.Type) {
 				return fmt.Errorf("option %q expecting type %v, got %v", name, expectType, option.Type)
 			}
 		}
@@ -538,11 +550,11 @@ func (o CommandInteractionOptions) unmarshal(rv reflect.Value) error {
 			}
 			fieldv.Set(reflect.ValueOf(i64).Convert(fieldt))
 		case reflect.Float32, reflect.Float64:
-			i64, err := option.IntValue()
+			f64, err := option.FloatValue()
 			if err != nil {
-				return errors.Wrapf(err, "option %q is not a valid int", name)
+				return errors.Wrapf(err, "option %q is not a valid float64", name)
 			}
-			fieldv.Set(reflect.ValueOf(i64).Convert(fieldt))
+			fieldv.Set(reflect.ValueOf(f64).Convert(fieldt))
 		case reflect.Bool:
 			b, err := option.BoolValue()
 			if err != nil {
@@ -9,7 +9,6 @@ package gateway
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"net/url"
 	"sync"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/internal/lazytime"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 	"github.com/diamondburned/arikawa/v3/utils/ws"
 	"github.com/pkg/errors"
 )
@@ -78,8 +78,10 @@ type State struct {
 // basically an abstracted concurrent event loop that the user could signal to
 // start connecting to the Discord gateway server.
 type Gateway struct {
-	gateway *ws.Gateway
-	state   State
+	gateway         *ws.Gateway
+	state           State
+	instrumenter    instrument.Instrumenter
+	zombieThreshold int
 
 	// non-mutex-guarded states
 	// TODO: make lastBeat part of ws.Gateway so it can keep track of whether or
@@ -87,6 +89,7 @@ type Gateway struct {
 	beatMutex  sync.Mutex
 	sentBeat   time.Time
 	echoBeat   time.Time
+	latencies  latencyRing
 	retryTimer lazytime.Timer
 }
 
@@ -137,10 +140,10 @@ func NewCustom(gatewayURL, token string) *Gateway {
 // DefaultGatewayOpts contains the default options to be used for connecting to
 // the gateway.
 var DefaultGatewayOpts = ws.GatewayOpts{
-	ReconnectDelay: func(try int) time.Duration {
-		// minimum 4 seconds
-		return time.Duration(4+(2*try)) * time.Second
-	},
+	ReconnectDelay: ws.ExponentialBackoff(4*time.Second, 2*time.Minute),
+	// ZombieThreshold allows 2 heartbeats to go unacknowledged before the
+	// connection is reconnected.
+	ZombieThreshold: 2,
 	// FatalCloseCodes contains the default gateway close codes that will cause
 	// the gateway to exit. In other words, it's a list of unrecoverable close
 	// codes.
@@ -172,9 +175,15 @@ func NewFromState(gatewayURL string, state State, opts *ws.GatewayOpts) *Gateway
 
 	gw := ws.NewGateway(ws.NewWebsocket(ws.NewCodec(OpUnmarshalers), gatewayURL), opts)
 
+	zombieThreshold := opts.ZombieThreshold
+	if zombieThreshold <= 0 {
+		zombieThreshold = 2
+	}
+
 	return &Gateway{
-		gateway: gw,
-		state:   state,
+		gateway:         gw,
+		state:           state,
+		zombieThreshold: zombieThreshold,
 	}
 }
 
@@ -199,6 +208,14 @@ func (g *Gateway) AddIntents(i Intents) {
 	g.state.Identifier.AddIntents(i)
 }
 
+// SetInstrumenter sets the instrumenter that receives the connection events of
+// the gateway, such as identifies, resumes, reconnections and heartbeats. It
+// panics if the gateway is running.
+func (g *Gateway) SetInstrumenter(i instrument.Instrumenter) {
+	g.gateway.SetInstrumenter(i)
+	g.instrumenter = i
+}
+
 // SentBeat returns the last time that the heart was beaten. If the gateway has
 // never connected, then a zero-value time is returned.
 func (g *Gateway) SentBeat() time.Time {
@@ -226,6 +243,15 @@ func (g *Gateway) Latency() time.Duration {
 	return g.echoBeat.Sub(g.sentBeat)
 }
 
+// Latencies returns the latencies of the latest heartbeats from oldest to
+// latest. Up to 16 latencies are kept, and they're kept across reconnections.
+func (g *Gateway) Latencies() []time.Duration {
+	g.beatMutex.Lock()
+	defer g.beatMutex.Unlock()
+
+	return g.latencies.slice()
+}
+
 // LastError returns the last error that the gateway has received. It only
 // returns a valid error if the gateway's event loop as exited. If the event
 // loop hasn't been started AND stopped, the function will panic.
@@ -233,11 +259,20 @@ func (g *Gateway) LastError() error {
 	return g.gateway.LastError()
 }
 
-// Send is a function to send an Op payload to the Gateway.
+// Send is a function to send an Op payload to the Gateway. If the send rate
+// limit is exceeded, then commands are queued by priority: heartbeats,
+// identifies and resumes are sent first, followed by voice state updates and
+// then everything else. Cancelling ctx removes the command from the queue.
 func (g *Gateway) Send(ctx context.Context, data ws.Event) error {
 	return g.gateway.Send(ctx, data)
 }
 
+// SendQueueLen returns the number of commands that are queued because of the
+// send rate limit.
+func (g *Gateway) SendQueueLen() int {
+	return g.gateway.SendQueueLen()
+}
+
 // Connect starts the background goroutine that tries its best to maintain a
 // stable connection to the Discord gateway. To the user, the gateway should
 // appear to be working seamlessly.
@@ -265,6 +300,13 @@ func (g *Gateway) Send(ctx context.Context, data ws.Event) error {
 //        log.Println("gateway error:", data.Error)
 //    }
 //
+// The gateway also sends lifecycle events that follow the state of the
+// connection: ws.ConnectingEvent and ws.ConnectedEvent around every dial,
+// ws.ResumedEvent once a session is resumed, ws.ZombiedEvent if heartbeats
+// stop being acknowledged and ws.GaveUpEvent right before the gateway exits
+// because it can't reconnect. Like BackgroundErrorEvent, they aren't sent by
+// Discord and have the Op -1.
+//
 // Closing
 //
 // As outlined in the first paragraph, closing the gateway would involve
@@ -310,6 +352,11 @@ type gatewayImpl struct {
 	*Gateway
 	heartrate    time.Duration
 	lastSentBeat time.Time
+	// missedBeats is the number of heartbeats sent since the last
+	// acknowledgement.
+	missedBeats int
+	// resumedFrom is the sequence of the last Resume command.
+	resumedFrom int64
 }
 
 func (g *gatewayImpl) invalidate() {
@@ -324,15 +371,39 @@ func (g *gatewayImpl) sendIdentify(ctx context.Context) error {
 		return errors.Wrap(err, "can't wait for identify()")
 	}
 
-	return g.gateway.Send(ctx, &g.state.Identifier.IdentifyCommand)
+	if err := g.gateway.Send(ctx, &g.state.Identifier.IdentifyCommand); err != nil {
+		return err
+	}
+
+	if g.instrumenter != nil {
+		var ev instrument.GatewayIdentify
+		if shard := g.state.Identifier.Shard; shard != nil {
+			ev.Shard = *shard
+		}
+		g.instrumenter.Observe(ev)
+	}
+
+	return nil
 }
 
 func (g *gatewayImpl) sendResume(ctx context.Context) error {
-	return g.gateway.Send(ctx, &ResumeCommand{
+	err := g.gateway.Send(ctx, &ResumeCommand{
 		Token:     g.state.Identifier.Token,
 		SessionID: g.state.SessionID,
 		Sequence:  g.state.Sequence,
 	})
+	if err != nil {
+		return err
+	}
+
+	g.resumedFrom = g.state.Sequence
+
+	instrument.Observe(g.instrumenter, instrument.GatewayResume{
+		SessionID: g.state.SessionID,
+		Sequence:  g.state.Sequence,
+	})
+
+	return nil
 }
 
 func (g *gatewayImpl) OnOp(ctx context.Context, op ws.Op) bool {
@@ -359,6 +430,8 @@ func (g *gatewayImpl) OnOp(ctx context.Context, op ws.Op) bool {
 		g.sentBeat = time.Time{}
 		g.beatMutex.Unlock()
 
+		g.missedBeats = 0
+
 		g.heartrate = data.HeartbeatInterval.Duration()
 		g.gateway.ResetHeartbeat(g.heartrate)
 
@@ -408,49 +481,58 @@ func (g *gatewayImpl) OnOp(ctx context.Context, op ws.Op) bool {
 	case *HeartbeatAckEvent:
 		now := time.Now()
 
+		latency := now.Sub(g.lastSentBeat)
+
 		g.beatMutex.Lock()
 		g.sentBeat = g.lastSentBeat
 		g.echoBeat = now
+		g.latencies.add(latency)
 		g.beatMutex.Unlock()
 
+		g.missedBeats = 0
+
+		instrument.Observe(g.instrumenter, instrument.GatewayHeartbeat{
+			Latency: latency,
+		})
+
 	case *ReconnectEvent:
 		g.gateway.QueueReconnect()
 
 	case *ReadyEvent:
 		g.state.SessionID = data.SessionID
+
+	case *ResumedEvent:
+		g.gateway.SendEvent(&ws.ResumedEvent{
+			SessionID: g.state.SessionID,
+			Sequence:  g.resumedFrom,
+		})
 	}
 
 	return true
 }
 
+// isDead returns true if the last zombieThreshold heartbeats weren't
+// acknowledged.
 func (g *gatewayImpl) isDead() bool {
-	if g.heartrate == 0 {
-		return false
-	}
-
-	g.beatMutex.Lock()
-	defer g.beatMutex.Unlock()
-
-	if g.echoBeat.IsZero() {
-		// No ack received yet. We wait for a bit.
-		return false
-	}
-
-	// Allow 2 beats to miss before we declare dead.
-	return g.lastSentBeat.Sub(g.echoBeat) > 2*g.heartrate
+	return g.heartrate != 0 && g.missedBeats >= g.zombieThreshold
 }
 
 // SendHeartbeat sends a heartbeat with the gateway's current sequence.
 func (g *gatewayImpl) SendHeartbeat(ctx context.Context) {
-	g.lastSentBeat = time.Now()
-
 	// TODO: move this to ws.Gateway
 	if g.isDead() {
-		g.gateway.SendError(fmt.Errorf("heartbeat timed out"))
+		g.gateway.SendError(errors.New("heartbeat timed out"))
+		g.gateway.SendEvent(&ws.ZombiedEvent{
+			Missed:  g.missedBeats,
+			LastAck: g.EchoBeat(),
+		})
 		g.gateway.QueueReconnect()
 		return
 	}
 
+	g.lastSentBeat = time.Now()
+	g.missedBeats++
+
 	sequence := HeartbeatCommand(g.state.Sequence)
 	if err := g.gateway.Send(ctx, &sequence); err != nil {
 		g.gateway.SendErrorWrap(err, "heartbeat error")
@@ -0,0 +1,388 @@
+package gatewaytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+// ErrClosed is returned if the connection is closed.
+var ErrClosed = errors.New("connection is closed")
+
+// Conn is the connection of a client to the Server. Its methods are
+// thread-safe.
+type Conn struct {
+	// Identify is the Identify command sent by the client. It is nil if the
+	// client resumed.
+	Identify *gateway.IdentifyCommand
+	// Resume is the Resume command sent by the client. It is nil if the client
+	// identified.
+	Resume *gateway.ResumeCommand
+
+	server  *Server
+	conn    *websocket.Conn
+	session *serverSession
+	codec   ws.Codec
+	buf     ws.DecodeBuffer
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	received []ws.Event
+	notify   chan struct{}
+	dropAcks bool
+
+	done chan struct{}
+}
+
+func newConn(s *Server, conn *websocket.Conn) *Conn {
+	return &Conn{
+		server: s,
+		conn:   conn,
+		codec:  ws.NewCodec(gateway.OpUnmarshalers),
+		buf:    ws.NewDecodeBuffer(1 << 12), // 4KB
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// SessionID returns the ID of the session of the connection.
+func (c *Conn) SessionID() string {
+	return c.session.id
+}
+
+// Sequence returns the sequence of the last event dispatched in the session.
+func (c *Conn) Sequence() int64 {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+
+	return int64(len(c.session.history))
+}
+
+// Done returns a channel that is closed once the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// DropHeartbeatAcks sets whether heartbeats are left unacknowledged, which
+// simulates a zombied connection. Heartbeats are acknowledged by default.
+func (c *Conn) DropHeartbeatAcks(drop bool) {
+	c.mu.Lock()
+	c.dropAcks = drop
+	c.mu.Unlock()
+}
+
+// Receive returns the next command sent by the client after it has identified
+// or resumed, including heartbeats. Commands are queued until they're
+// received. ErrClosed is returned once the connection is closed and all
+// commands are received.
+func (c *Conn) Receive(ctx context.Context) (ws.Event, error) {
+	for {
+		c.mu.Lock()
+		if len(c.received) > 0 {
+			ev := c.received[0]
+			c.received = c.received[1:]
+			c.mu.Unlock()
+			return ev, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-c.done:
+			// Check the queue once more, since the connection may have
+			// received commands right before it closed.
+			c.mu.Lock()
+			empty := len(c.received) == 0
+			c.mu.Unlock()
+
+			if empty {
+				return nil, ErrClosed
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Send sends the given event to the client. Dispatch events (Op 0) are given
+// the next sequence of the session and are replayed if the client resumes
+// from an earlier sequence.
+func (c *Conn) Send(ev ws.Event) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.send(ev)
+}
+
+// Reconnect sends a Reconnect event, which makes the client reconnect and
+// resume.
+func (c *Conn) Reconnect() error {
+	return c.Send(&gateway.ReconnectEvent{})
+}
+
+// InvalidSession sends an Invalid Session event. If resumable is false, then
+// the session is also invalidated, so the client must identify again.
+func (c *Conn) InvalidSession(resumable bool) error {
+	if !resumable && c.session != nil {
+		c.server.InvalidateSession(c.session.id)
+	}
+
+	ev := gateway.InvalidSessionEvent(resumable)
+	return c.Send(&ev)
+}
+
+// Close closes the connection with the given close code and reason.
+func (c *Conn) Close(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.close(code, reason)
+}
+
+func (c *Conn) close(code int, reason string) error {
+	err := c.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(time.Second),
+	)
+	c.conn.Close()
+
+	if err != nil {
+		return errors.Wrap(err, "failed to send close frame")
+	}
+	return nil
+}
+
+func (c *Conn) send(ev ws.Event) error {
+	op := ws.Op{
+		Code: ev.Op(),
+		Type: ev.EventType(),
+		Data: ev,
+	}
+
+	if op.Code != 0 {
+		b, err := json.Marshal(op)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode event")
+		}
+		return c.write(b)
+	}
+
+	if c.session == nil {
+		return errors.New("cannot dispatch before the client is authenticated")
+	}
+
+	c.server.mu.Lock()
+	op.Sequence = int64(len(c.session.history)) + 1
+
+	b, err := json.Marshal(op)
+	if err == nil {
+		c.session.history = append(c.session.history, b)
+	}
+	c.server.mu.Unlock()
+
+	if err != nil {
+		return errors.Wrap(err, "failed to encode event")
+	}
+
+	return c.write(b)
+}
+
+func (c *Conn) write(b []byte) error {
+	if err := c.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+	return nil
+}
+
+// run handles the connection until it's closed.
+func (c *Conn) run() {
+	err := c.Send(&gateway.HelloEvent{
+		HeartbeatInterval: discord.DurationToMilliseconds(c.server.HeartbeatInterval),
+	})
+	if err != nil {
+		return
+	}
+
+	for c.session == nil {
+		ev, ok := c.read()
+		if !ok {
+			return
+		}
+
+		switch ev := ev.(type) {
+		case *gateway.HeartbeatCommand:
+			c.Send(&gateway.HeartbeatAckEvent{})
+		case *gateway.IdentifyCommand:
+			if !c.identify(ev) {
+				return
+			}
+		case *gateway.ResumeCommand:
+			if !c.resume(ev) {
+				return
+			}
+		default:
+			c.Close(CodeNotAuthenticated, "Not authenticated.")
+			return
+		}
+	}
+
+	c.server.mu.Lock()
+	c.server.conns[c] = true
+	c.server.mu.Unlock()
+
+	c.server.accepted <- c
+
+	for {
+		ev, ok := c.read()
+		if !ok {
+			return
+		}
+
+		switch ev.(type) {
+		case *gateway.HeartbeatCommand:
+			c.mu.Lock()
+			drop := c.dropAcks
+			c.mu.Unlock()
+
+			if !drop {
+				c.Send(&gateway.HeartbeatAckEvent{})
+			}
+		case *gateway.IdentifyCommand, *gateway.ResumeCommand:
+			c.Close(CodeAlreadyAuthenticated, "Already authenticated.")
+			return
+		}
+
+		c.mu.Lock()
+		c.received = append(c.received, ev)
+		c.mu.Unlock()
+
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// read reads the next command. If the command is invalid, then the connection
+// is closed with the appropriate close code.
+func (c *Conn) read() (ws.Event, bool) {
+	_, r, err := c.conn.NextReader()
+	if err != nil {
+		return nil, false
+	}
+
+	// The codec may send a RawEvent before the event itself.
+	opCh := make(chan ws.Op, 2)
+	c.codec.DecodeInto(context.Background(), r, &c.buf, opCh)
+
+	var op ws.Op
+	for len(opCh) > 0 {
+		if op = <-opCh; op.Code >= 0 {
+			break
+		}
+		if _, ok := op.Data.(*ws.BackgroundErrorEvent); ok {
+			break
+		}
+	}
+
+	if errEv, ok := op.Data.(*ws.BackgroundErrorEvent); ok {
+		var unknown ws.UnknownEventError
+		if errors.As(errEv.Err, &unknown) {
+			c.Close(CodeUnknownOpcode, "Unknown opcode.")
+		} else {
+			c.Close(CodeDecodeError, "Decode error.")
+		}
+		return nil, false
+	}
+
+	return op.Data, true
+}
+
+func (c *Conn) checkToken(token string) bool {
+	if c.server.Token != "" && token != c.server.Token {
+		c.Close(CodeAuthenticationFailed, "Authentication failed.")
+		return false
+	}
+	return true
+}
+
+func (c *Conn) identify(cmd *gateway.IdentifyCommand) bool {
+	if !c.checkToken(cmd.Token) {
+		return false
+	}
+
+	if shard := cmd.Shard; shard != nil {
+		if shard.NumShards() < 1 || shard.ShardID() < 0 || shard.ShardID() >= shard.NumShards() {
+			c.Close(CodeInvalidShard, "Invalid shard.")
+			return false
+		}
+	}
+
+	if cmd.Intents != nil && gateway.Intents(*cmd.Intents)&c.server.DisallowedIntents != 0 {
+		c.Close(CodeDisallowedIntents, "Disallowed intent(s).")
+		return false
+	}
+
+	c.Identify = cmd
+	c.session = c.server.newSession()
+
+	ready := c.server.Ready
+	ready.SessionID = c.session.id
+	ready.Shard = cmd.Shard
+
+	return c.Send(&ready) == nil
+}
+
+func (c *Conn) resume(cmd *gateway.ResumeCommand) bool {
+	if !c.checkToken(cmd.Token) {
+		return false
+	}
+
+	ss := c.server.session(cmd.SessionID)
+	if ss == nil {
+		// The client is expected to reconnect and identify.
+		ev := gateway.InvalidSessionEvent(false)
+		return c.Send(&ev) == nil
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.server.mu.Lock()
+	history := ss.history
+	c.server.mu.Unlock()
+
+	if cmd.Sequence < 0 || cmd.Sequence > int64(len(history)) {
+		c.close(CodeInvalidSequence, "Invalid seq.")
+		return false
+	}
+
+	missed := history[cmd.Sequence:]
+
+	for _, b := range missed {
+		if err := c.write(b); err != nil {
+			return false
+		}
+	}
+
+	c.Resume = cmd
+	c.session = ss
+
+	return c.send(&gateway.ResumedEvent{}) == nil
+}
+
+// finish marks the connection as closed.
+func (c *Conn) finish() {
+	c.conn.Close()
+	close(c.done)
+}
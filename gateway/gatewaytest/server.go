@@ -0,0 +1,259 @@
+// Package gatewaytest provides a fake Discord gateway server for integration
+// tests. It speaks enough of the gateway protocol for a gateway.Gateway,
+// session.Session or state.State to connect to it: it sends Hello, validates
+// Identify and Resume commands, acknowledges heartbeats and numbers dispatched
+// events with sequences, which are replayed on Resume.
+//
+// Usage
+//
+// Tests accept each connection after it has identified or resumed, script the
+// events sent to the client and assert on the commands that it sent:
+//
+//    srv := gatewaytest.NewServer()
+//    defer srv.Close()
+//
+//    s := session.NewWithGateway(srv.NewGateway(id), handler.New())
+//    go s.Open(ctx)
+//
+//    conn, err := srv.Accept(ctx)
+//    if err != nil {
+//        t.Fatal(err)
+//    }
+//
+//    t.Log("client intents:", conn.Identify.Intents)
+//
+//    conn.Send(&gateway.MessageCreateEvent{...})
+//    conn.Reconnect()
+//
+//    conn, err = srv.Accept(ctx) // the client resumes
+//
+package gatewaytest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+// DefaultHeartbeatInterval is the heartbeat interval sent in Hello if the
+// Server doesn't have one. It is the same as Discord's.
+const DefaultHeartbeatInterval = 41250 * time.Millisecond
+
+// Close codes sent by the Server.
+//
+// https://discord.com/developers/docs/topics/opcodes-and-status-codes#gateway-gateway-close-event-codes
+const (
+	CodeUnknownError         = 4000
+	CodeUnknownOpcode        = 4001
+	CodeDecodeError          = 4002
+	CodeNotAuthenticated     = 4003
+	CodeAuthenticationFailed = 4004
+	CodeAlreadyAuthenticated = 4005
+	CodeInvalidSequence      = 4007
+	CodeInvalidShard         = 4010
+	CodeDisallowedIntents    = 4014
+)
+
+// GatewayOpts are the options of gateways created by NewGateway. They are the
+// same as gateway.DefaultGatewayOpts, except that failed reconnections are
+// retried almost immediately.
+var GatewayOpts = func() ws.GatewayOpts {
+	opts := gateway.DefaultGatewayOpts
+	opts.ReconnectDelay = func(try int) time.Duration {
+		return 100 * time.Millisecond
+	}
+	return opts
+}()
+
+// Server is a fake Discord gateway server. The options must be set before
+// Start is called.
+type Server struct {
+	// URL is the websocket URL of the server, such as ws://127.0.0.1:1234.
+	URL string
+
+	// Token, if not empty, is the only token that is accepted. Clients that
+	// identify or resume with any other token are closed with
+	// CodeAuthenticationFailed.
+	Token string
+	// DisallowedIntents contains the intents that clients may not identify
+	// with. Clients that do are closed with CodeDisallowedIntents.
+	DisallowedIntents gateway.Intents
+	// HeartbeatInterval is the heartbeat interval sent in Hello. If it's 0,
+	// then DefaultHeartbeatInterval is used.
+	HeartbeatInterval time.Duration
+	// Ready is the Ready event sent to clients once they identify. Its
+	// SessionID and Shard fields are filled in by the Server.
+	Ready gateway.ReadyEvent
+
+	srv      *httptest.Server
+	accepted chan *Conn
+
+	mu       sync.Mutex
+	sessions map[string]*serverSession
+	conns    map[*Conn]bool // true if authenticated
+	nextID   int
+}
+
+// serverSession is a gateway session, which survives across connections when
+// the client resumes.
+type serverSession struct {
+	id string
+	// history contains the encoded dispatch events; the event at index i has
+	// the sequence i+1.
+	history [][]byte
+}
+
+// NewServer creates and starts a new Server.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer creates a new Server that isn't started. Start must be
+// called once its options are set.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		Ready: gateway.ReadyEvent{
+			Version: 9,
+			User: discord.User{
+				ID:       1,
+				Username: "gatewaytest",
+				Bot:      true,
+			},
+		},
+		accepted: make(chan *Conn, 16),
+		sessions: make(map[string]*serverSession),
+		conns:    make(map[*Conn]bool),
+	}
+	s.srv = httptest.NewUnstartedServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Start starts the server.
+func (s *Server) Start() {
+	if s.HeartbeatInterval == 0 {
+		s.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	s.srv.Start()
+	s.URL = "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// Close closes all connections and shuts down the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.srv.Close()
+}
+
+// NewGateway creates a new gateway.Gateway that connects to the server using
+// GatewayOpts. The identify rate limiters of the given Identifier are removed.
+func (s *Server) NewGateway(id gateway.Identifier) *gateway.Gateway {
+	id.IdentifyShortLimit = nil
+	id.IdentifyGlobalLimit = nil
+
+	opts := GatewayOpts
+	return gateway.NewCustomWithIdentifier(s.URL, id, &opts)
+}
+
+// Accept waits for the next connection that has successfully identified or
+// resumed and returns it. Connections are queued until they're accepted.
+func (s *Server) Accept(ctx context.Context) (*Conn, error) {
+	select {
+	case c := <-s.accepted:
+		return c, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Dispatch sends the given event to all connections that have identified or
+// resumed. The first error is returned, but the event is still sent to all
+// connections.
+func (s *Server) Dispatch(ev ws.Event) error {
+	s.mu.Lock()
+	conns := make([]*Conn, 0, len(s.conns))
+	for c, authenticated := range s.conns {
+		if authenticated {
+			conns = append(conns, c)
+		}
+	}
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, c := range conns {
+		if err := c.Send(ev); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// InvalidateSession removes the session with the given ID, so that clients
+// can't resume it anymore.
+func (s *Server) InvalidateSession(id string) {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+func (s *Server) newSession() *serverSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	ss := &serverSession{id: "gatewaytest-" + strconv.Itoa(s.nextID)}
+	s.sessions[ss.id] = ss
+
+	return ss
+}
+
+func (s *Server) session(id string) *serverSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sessions[id]
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newConn(s, wsConn)
+
+	s.mu.Lock()
+	s.conns[c] = false
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+
+		c.finish()
+	}()
+
+	c.run()
+}
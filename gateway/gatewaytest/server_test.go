@@ -0,0 +1,391 @@
+package gatewaytest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/session"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+func newServer(t *testing.T, token string) *Server {
+	srv := NewUnstartedServer()
+	srv.Token = token
+	srv.DisallowedIntents = gateway.IntentGuildPresences
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newSession(t *testing.T, srv *Server, token string, intents gateway.Intents) *session.Session {
+	id := gateway.DefaultIdentifier(token)
+	id.Intents = option.NewUint(uint(intents))
+	id.Presence = &gateway.UpdatePresenceCommand{Status: discord.IdleStatus}
+
+	s := session.NewWithGateway(srv.NewGateway(id), handler.New())
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	srv := newServer(t, "Bot token")
+	s := newSession(t, srv, "Bot token", gateway.IntentGuildMessages)
+
+	messages := make(chan *gateway.MessageCreateEvent, 1)
+	s.AddHandler(messages)
+
+	if err := s.Open(ctx); err != nil {
+		t.Fatal("failed to open:", err)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	if conn.Identify == nil {
+		t.Fatal("client did not identify")
+	}
+	if intents := conn.Identify.Intents; intents == nil || *intents != uint(gateway.IntentGuildMessages) {
+		t.Errorf("unexpected intents %v", intents)
+	}
+	if p := conn.Identify.Presence; p == nil || p.Status != discord.IdleStatus {
+		t.Errorf("unexpected presence %v", p)
+	}
+
+	if err := conn.Send(&gateway.MessageCreateEvent{
+		Message: discord.Message{ID: 1, ChannelID: 2, Content: "hello"},
+	}); err != nil {
+		t.Fatal("failed to send message:", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.Content != "hello" {
+			t.Errorf("unexpected message content %q", msg.Content)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for message")
+	}
+
+	err = s.Gateway().Send(ctx, &gateway.UpdatePresenceCommand{Status: discord.DoNotDisturbStatus})
+	if err != nil {
+		t.Fatal("failed to update presence:", err)
+	}
+
+	ev, err := conn.Receive(ctx)
+	if err != nil {
+		t.Fatal("failed to receive:", err)
+	}
+
+	if p, ok := ev.(*gateway.UpdatePresenceCommand); !ok || p.Status != discord.DoNotDisturbStatus {
+		t.Fatalf("unexpected command %#v", ev)
+	}
+
+	if conn.Sequence() != 2 {
+		t.Errorf("expected sequence 2, got %d", conn.Sequence())
+	}
+}
+
+func TestSessionReconnect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("reconnecting waits for the dial rate limiter")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	srv := newServer(t, "")
+	s := newSession(t, srv, "Bot token", 0)
+
+	resumed := make(chan *ws.ResumedEvent, 1)
+	s.AddHandler(resumed)
+
+	if err := s.Open(ctx); err != nil {
+		t.Fatal("failed to open:", err)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	if err := conn.Reconnect(); err != nil {
+		t.Fatal("failed to send reconnect:", err)
+	}
+
+	conn2, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept after reconnecting:", err)
+	}
+
+	if conn2.Resume == nil || conn2.Resume.SessionID != conn.SessionID() {
+		t.Fatalf("client did not resume session %q: %#v", conn.SessionID(), conn2.Resume)
+	}
+
+	select {
+	case ev := <-resumed:
+		if ev.SessionID != conn.SessionID() || ev.Sequence != 1 {
+			t.Errorf("unexpected resumed event %#v", ev)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for resumed")
+	}
+}
+
+func TestSessionAuthenticationFailed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	srv := newServer(t, "Bot token")
+	s := newSession(t, srv, "Bot bad token", 0)
+
+	err := s.Open(ctx)
+	if err == nil || !strings.Contains(err.Error(), "4004") {
+		t.Fatal("expected authentication failure, got", err)
+	}
+}
+
+// testClient is a raw websocket client used to test the protocol directly.
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dial(t *testing.T, srv *Server) *testClient {
+	conn, _, err := websocket.DefaultDialer.Dial(srv.URL, nil)
+	if err != nil {
+		t.Fatal("failed to dial:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testClient{t: t, conn: conn}
+	if op := c.read(); op.Code != 10 {
+		t.Fatalf("expected Hello, got op %d", op.Code)
+	}
+	return c
+}
+
+type testOp struct {
+	Code     ws.OpCode    `json:"op"`
+	Type     ws.EventType `json:"t"`
+	Sequence int64        `json:"s"`
+	Data     json.Raw     `json:"d"`
+}
+
+func (c *testClient) send(ev ws.Event) {
+	c.t.Helper()
+
+	if err := c.conn.WriteJSON(ws.Op{Code: ev.Op(), Data: ev}); err != nil {
+		c.t.Fatal("failed to send:", err)
+	}
+}
+
+func (c *testClient) read() testOp {
+	c.t.Helper()
+
+	var op testOp
+	if err := c.conn.ReadJSON(&op); err != nil {
+		c.t.Fatal("failed to read:", err)
+	}
+	return op
+}
+
+func (c *testClient) expectClose(code int) {
+	c.t.Helper()
+
+	_, _, err := c.conn.ReadMessage()
+	if !websocket.IsCloseError(err, code) {
+		c.t.Fatalf("expected close code %d, got %v", code, err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	srv := newServer(t, "")
+
+	c := dial(t, srv)
+	c.send(&gateway.IdentifyCommand{Token: "token"})
+
+	ready := c.read()
+	if ready.Type != "READY" || ready.Sequence != 1 {
+		t.Fatalf("expected READY with sequence 1, got %s %d", ready.Type, ready.Sequence)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	conn.Send(&gateway.TypingStartEvent{ChannelID: 1})
+	conn.Send(&gateway.TypingStartEvent{ChannelID: 2})
+
+	c.send(newHeartbeat(3))
+	for _, expect := range []string{"TYPING_START", "TYPING_START", ""} {
+		if op := c.read(); op.Type != ws.EventType(expect) {
+			t.Fatalf("expected %q, got op %d %q", expect, op.Code, op.Type)
+		}
+	}
+
+	if ev, err := conn.Receive(ctx); err != nil || *ev.(*gateway.HeartbeatCommand) != 3 {
+		t.Fatalf("expected heartbeat 3, got %v (%v)", ev, err)
+	}
+
+	c.conn.Close()
+
+	// Resume having only seen READY.
+	c = dial(t, srv)
+	c.send(&gateway.ResumeCommand{SessionID: conn.SessionID(), Sequence: 1})
+
+	for i, expect := range []string{"TYPING_START", "TYPING_START", "RESUMED"} {
+		op := c.read()
+		if op.Type != ws.EventType(expect) || op.Sequence != int64(i+2) {
+			t.Fatalf("expected %q with sequence %d, got %q %d", expect, i+2, op.Type, op.Sequence)
+		}
+	}
+
+	// Sequences from the future are invalid.
+	c = dial(t, srv)
+	c.send(&gateway.ResumeCommand{SessionID: conn.SessionID(), Sequence: 10})
+	c.expectClose(CodeInvalidSequence)
+
+	// Unknown sessions can't be resumed.
+	c = dial(t, srv)
+	c.send(&gateway.ResumeCommand{SessionID: "unknown"})
+	if op := c.read(); op.Code != 9 || string(op.Data) != "false" {
+		t.Fatalf("expected Invalid Session, got op %d %s", op.Code, op.Data)
+	}
+}
+
+func newHeartbeat(seq int) *gateway.HeartbeatCommand {
+	h := gateway.HeartbeatCommand(seq)
+	return &h
+}
+
+func TestIdentifyValidation(t *testing.T) {
+	srv := newServer(t, "token")
+
+	tests := []struct {
+		name string
+		ev   ws.Event
+		code int
+	}{
+		{
+			name: "not authenticated",
+			ev:   &gateway.UpdatePresenceCommand{Status: discord.OnlineStatus},
+			code: CodeNotAuthenticated,
+		},
+		{
+			name: "bad token",
+			ev:   &gateway.IdentifyCommand{Token: "bad"},
+			code: CodeAuthenticationFailed,
+		},
+		{
+			name: "invalid shard",
+			ev:   &gateway.IdentifyCommand{Token: "token", Shard: &gateway.Shard{2, 2}},
+			code: CodeInvalidShard,
+		},
+		{
+			name: "disallowed intents",
+			ev: &gateway.IdentifyCommand{
+				Token:   "token",
+				Intents: option.NewUint(uint(gateway.IntentGuildPresences)),
+			},
+			code: CodeDisallowedIntents,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := dial(t, srv)
+			c.send(test.ev)
+			c.expectClose(test.code)
+		})
+	}
+
+	t.Run("unknown opcode", func(t *testing.T) {
+		c := dial(t, srv)
+		c.conn.WriteMessage(websocket.TextMessage, []byte(`{"op":42,"d":null}`))
+		c.expectClose(CodeUnknownOpcode)
+	})
+
+	t.Run("already authenticated", func(t *testing.T) {
+		c := dial(t, srv)
+		c.send(&gateway.IdentifyCommand{Token: "token", Shard: &gateway.Shard{1, 2}})
+		if op := c.read(); op.Type != "READY" {
+			t.Fatalf("expected READY, got %q", op.Type)
+		}
+		c.send(&gateway.IdentifyCommand{Token: "token"})
+		c.expectClose(CodeAlreadyAuthenticated)
+	})
+}
+
+func TestZombie(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	srv := NewUnstartedServer()
+	srv.HeartbeatInterval = 50 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	s := newSession(t, srv, "Bot token", 0)
+
+	zombied := make(chan *ws.ZombiedEvent, 1)
+	s.AddHandler(zombied)
+
+	if err := s.Open(ctx); err != nil {
+		t.Fatal("failed to open:", err)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	// Wait for a few acknowledged heartbeats.
+	for i := 0; i < 3; i++ {
+		if _, err := conn.Receive(ctx); err != nil {
+			t.Fatal("failed to receive heartbeat:", err)
+		}
+	}
+
+	conn.DropHeartbeatAcks(true)
+
+	select {
+	case ev := <-zombied:
+		if ev.Missed != gateway.DefaultGatewayOpts.ZombieThreshold {
+			t.Errorf("expected %d missed heartbeats, got %d",
+				gateway.DefaultGatewayOpts.ZombieThreshold, ev.Missed)
+		}
+		if ev.LastAck.IsZero() {
+			t.Error("expected the time of the last acknowledgement")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for zombied")
+	}
+
+	if latencies := s.Gateway().Latencies(); len(latencies) < 2 {
+		t.Errorf("expected latencies, got %v", latencies)
+	}
+
+	select {
+	case <-conn.Done():
+	case <-ctx.Done():
+		t.Fatal("zombied connection was not closed")
+	}
+}
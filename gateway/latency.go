@@ -0,0 +1,34 @@
+package gateway
+
+import "time"
+
+// latencyHistory is the number of heartbeat latencies kept by the Gateway.
+const latencyHistory = 16
+
+// latencyRing is a ring buffer of the latest heartbeat latencies.
+type latencyRing struct {
+	buf [latencyHistory]time.Duration
+	len int
+	pos int // next write position
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	r.buf[r.pos] = d
+	r.pos = (r.pos + 1) % len(r.buf)
+	if r.len < len(r.buf) {
+		r.len++
+	}
+}
+
+// slice returns the latencies from oldest to latest.
+func (r *latencyRing) slice() []time.Duration {
+	latencies := make([]time.Duration, r.len)
+	start := r.pos - r.len
+	if start < 0 {
+		start += len(r.buf)
+	}
+	for i := range latencies {
+		latencies[i] = r.buf[(start+i)%len(r.buf)]
+	}
+	return latencies
+}
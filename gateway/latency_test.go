@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLatencyRing(t *testing.T) {
+	var r latencyRing
+
+	if latencies := r.slice(); len(latencies) != 0 {
+		t.Fatalf("expected no latencies, got %v", latencies)
+	}
+
+	var expect []time.Duration
+	for i := 1; i <= latencyHistory+4; i++ {
+		r.add(time.Duration(i))
+		expect = append(expect, time.Duration(i))
+
+		if len(expect) > latencyHistory {
+			expect = expect[1:]
+		}
+
+		if latencies := r.slice(); !reflect.DeepEqual(latencies, expect) {
+			t.Fatalf("after %d: expected %v, got %v", i, expect, latencies)
+		}
+	}
+}
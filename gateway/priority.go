@@ -0,0 +1,26 @@
+package gateway
+
+import "github.com/diamondburned/arikawa/v3/utils/ws"
+
+// Commands that keep the connection alive are never delayed by other
+// commands, and voice state updates are sent before member requests and
+// presence updates. See ws.SendPriority.
+
+var (
+	_ ws.PrioritizedEvent = (*HeartbeatCommand)(nil)
+	_ ws.PrioritizedEvent = (*IdentifyCommand)(nil)
+	_ ws.PrioritizedEvent = (*ResumeCommand)(nil)
+	_ ws.PrioritizedEvent = (*UpdateVoiceStateCommand)(nil)
+)
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*HeartbeatCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*IdentifyCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*ResumeCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*UpdateVoiceStateCommand) SendPriority() ws.SendPriority { return ws.SendPriorityHigh }
@@ -74,16 +74,16 @@ func NewBackoff(min, max time.Duration) Backoff {
 
 // Next returns the next backoff duration.
 func (b *Backoff) Next() time.Duration {
-	return b.forAttempt(atomic.AddInt32(&b.attempt, 1) - 1)
+	return b.ForAttempt(atomic.AddInt32(&b.attempt, 1) - 1)
 }
 
 const maxInt64 = float64(math.MaxInt64 - 512)
 
-// forAttempt returns the duration for a specific attempt. This is useful if
+// ForAttempt returns the duration for a specific attempt. This is useful if
 // you have a large number of independent Backoffs, but don't want use
 // unnecessary memory storing the Backoff parameters per Backoff. The first
 // attempt should be 0.
-func (b *Backoff) forAttempt(attempt int32) time.Duration {
+func (b *Backoff) ForAttempt(attempt int32) time.Duration {
 	if b.min >= b.max {
 		// short-circuit
 		return duration(b.max)
@@ -6,6 +6,7 @@ package session
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/diamondburned/arikawa/v3/api/webhook"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
 	"github.com/diamondburned/arikawa/v3/utils/ws/ophandler"
 )
 
@@ -26,6 +29,10 @@ var ErrClosed = errors.New("Session is closed")
 
 // Session manages both the API and Gateway. As such, Session inherits all of
 // API's methods, as well has the Handler used for Gateway.
+//
+// The Instrumenter field inherited from the API client is also given to the
+// gateway once Open is called, and it receives an instrument.EventDispatch
+// event for every gateway event handled.
 type Session struct {
 	*api.Client
 	*handler.Handler
@@ -251,8 +258,14 @@ func (s *Session) Open(ctx context.Context) error {
 	rm := s.AddHandler(evCh)
 	defer rm()
 
+	var caller ophandler.Caller = s.Handler
+	if s.Client.Instrumenter != nil {
+		s.state.gateway.SetInstrumenter(s.Client.Instrumenter)
+		caller = instrumentedCaller{s.Handler, s.Client.Instrumenter}
+	}
+
 	opCh := s.state.gateway.Connect(s.state.ctx)
-	s.state.doneCh = ophandler.Loop(opCh, s.Handler)
+	s.state.doneCh = ophandler.Loop(opCh, caller)
 
 	for {
 		select {
@@ -269,6 +282,12 @@ func (s *Session) Open(ctx context.Context) error {
 			return s.state.gateway.LastError()
 
 		case ev := <-evCh:
+			switch ev.(type) {
+			case *ws.ConnectingEvent, *ws.ConnectedEvent:
+				// Sent by the gateway itself, not Discord.
+				continue
+			}
+
 			if s.DontWaitForReady {
 				return nil
 			}
@@ -309,6 +328,22 @@ func (s *Session) Wait(ctx context.Context) error {
 	}
 }
 
+// Done returns a channel that's closed once the event loop of the session
+// stops, either because the session is closed or because the gateway failed.
+// If the session isn't open, then the returned channel is already closed.
+func (s *Session) Done() <-chan struct{} {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	if s.state.doneCh == nil {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+
+	return s.state.doneCh
+}
+
 // WithContext returns a shallow copy of Session with the context replaced in
 // the API client. All methods called on the returned Session will use this
 // given context.
@@ -364,3 +399,24 @@ func (s *Session) close() error {
 
 	return s.state.gateway.LastError()
 }
+
+// instrumentedCaller times the dispatching of each event.
+type instrumentedCaller struct {
+	handler      *handler.Handler
+	instrumenter instrument.Instrumenter
+}
+
+func (c instrumentedCaller) Call(ev interface{}) {
+	start := time.Now()
+	c.handler.Call(ev)
+
+	var typ string
+	if ev, ok := ev.(ws.Event); ok {
+		typ = string(ev.EventType())
+	}
+
+	c.instrumenter.Observe(instrument.EventDispatch{
+		Type:     typ,
+		Duration: time.Since(start),
+	})
+}
@@ -0,0 +1,268 @@
+package state
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+// DefaultChunkConcurrency is the number of guilds that are chunked at the same
+// time if ChunkLargeGuilds is true and ChunkConcurrency is 0.
+const DefaultChunkConcurrency = 2
+
+// chunkTimeout is the time that chunking a guild automatically may take, not
+// counting the time it waits for other guilds to be chunked. It's a variable
+// for testing.
+var chunkTimeout = 2 * time.Minute
+
+// MembersResult is the result of a members request made over the gateway.
+type MembersResult struct {
+	Members []discord.Member
+	// Presences is only filled if presences were requested.
+	Presences []discord.Presence
+	// NotFound contains the requested user IDs that aren't members of the
+	// guild.
+	NotFound []discord.UserID
+}
+
+// memberRequests keeps track of the members requests awaiting their chunks.
+type memberRequests struct {
+	mu        sync.Mutex
+	requests  map[string]*memberRequest
+	nextNonce uint64
+
+	chunkOnce sync.Once
+	chunkSema chan struct{}
+}
+
+// memberRequest buffers the chunks of a members request, so that the event
+// handler never waits for the requester.
+type memberRequest struct {
+	mu       sync.Mutex
+	chunks   []*gateway.GuildMembersChunkEvent
+	received int
+	count    int // 0 until the first chunk
+	notify   chan struct{}
+}
+
+func newMemberRequests() *memberRequests {
+	return &memberRequests{
+		requests: make(map[string]*memberRequest),
+	}
+}
+
+// RequestMembers requests the members of the guild whose usernames start with
+// query over the gateway and waits for all of them. If query is empty and limit
+// is 0, then all members are requested, which requires the GUILD_MEMBERS
+// intent. The members and presences are also added into the store.
+func (s *State) RequestMembers(
+	ctx context.Context,
+	guildID discord.GuildID, query string, limit uint, presences bool) (*MembersResult, error) {
+
+	return s.requestMembers(ctx, gateway.RequestGuildMembersCommand{
+		GuildIDs:  []discord.GuildID{guildID},
+		Query:     option.NewString(query),
+		Limit:     limit,
+		Presences: presences,
+	})
+}
+
+// RequestMembersByID is similar to RequestMembers, except the members are
+// requested by their user IDs. Users that aren't members of the guild are
+// listed in the result's NotFound field.
+func (s *State) RequestMembersByID(
+	ctx context.Context,
+	guildID discord.GuildID, userIDs []discord.UserID, presences bool) (*MembersResult, error) {
+
+	return s.requestMembers(ctx, gateway.RequestGuildMembersCommand{
+		GuildIDs:  []discord.GuildID{guildID},
+		UserIDs:   userIDs,
+		Presences: presences,
+	})
+}
+
+func (s *State) requestMembers(
+	ctx context.Context, cmd gateway.RequestGuildMembersCommand) (*MembersResult, error) {
+
+	ch, err := s.RequestMembersChunks(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var result MembersResult
+	var received, count int
+
+	for chunk := range ch {
+		received++
+		count = chunk.ChunkCount
+
+		result.Members = append(result.Members, chunk.Members...)
+		result.Presences = append(result.Presences, chunk.Presences...)
+
+		for _, id := range chunk.NotFound {
+			sf, err := discord.ParseSnowflake(id)
+			if err == nil {
+				result.NotFound = append(result.NotFound, discord.UserID(sf))
+			}
+		}
+	}
+
+	if count == 0 || received < count {
+		return nil, errors.Wrap(ctx.Err(), "failed to wait for member chunks")
+	}
+
+	return &result, nil
+}
+
+// RequestMembersChunks sends the given Request Guild Members command and
+// returns a channel that receives its Guild Members Chunk events. The command
+// is given a nonce to tell its chunks apart from the others, unless it already
+// has one. The channel is closed once all chunks are received or once ctx
+// expires, so the caller should check ctx.Err().
+//
+// Like all Guild Members Chunk events, the chunks are also added into the store
+// and given to the handlers.
+func (s *State) RequestMembersChunks(
+	ctx context.Context,
+	cmd gateway.RequestGuildMembersCommand) (<-chan *gateway.GuildMembersChunkEvent, error) {
+
+	if s.memberRequests == nil {
+		return nil, errors.New("state has no gateway")
+	}
+
+	req := &memberRequest{notify: make(chan struct{}, 1)}
+
+	rs := s.memberRequests
+	rs.mu.Lock()
+	if cmd.Nonce == "" {
+		rs.nextNonce++
+		cmd.Nonce = "arikawa-" + strconv.FormatUint(rs.nextNonce, 36)
+	}
+	rs.requests[cmd.Nonce] = req
+	rs.mu.Unlock()
+
+	if err := s.Gateway().Send(ctx, &cmd); err != nil {
+		rs.remove(cmd.Nonce)
+		return nil, errors.Wrap(err, "failed to request members")
+	}
+
+	ch := make(chan *gateway.GuildMembersChunkEvent)
+
+	go func() {
+		defer close(ch)
+		defer rs.remove(cmd.Nonce)
+
+		for {
+			chunks, done := req.take()
+
+			for _, chunk := range chunks {
+				select {
+				case ch <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if done {
+				return
+			}
+
+			select {
+			case <-req.notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// take takes the buffered chunks. done is true if all chunks are taken.
+func (r *memberRequest) take() (chunks []*gateway.GuildMembersChunkEvent, done bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chunks = r.chunks
+	r.chunks = nil
+
+	return chunks, r.count > 0 && r.received >= r.count
+}
+
+func (rs *memberRequests) remove(nonce string) {
+	rs.mu.Lock()
+	delete(rs.requests, nonce)
+	rs.mu.Unlock()
+}
+
+// handleMembersChunk gives the chunk to the request with the same nonce, if
+// any.
+func (s *State) handleMembersChunk(ev *gateway.GuildMembersChunkEvent) {
+	if s.memberRequests == nil || ev.Nonce == "" {
+		return
+	}
+
+	rs := s.memberRequests
+	rs.mu.Lock()
+	req, ok := rs.requests[ev.Nonce]
+	rs.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	req.mu.Lock()
+	req.chunks = append(req.chunks, ev)
+	req.received++
+	req.count = ev.ChunkCount
+	req.mu.Unlock()
+
+	select {
+	case req.notify <- struct{}{}:
+	default:
+	}
+}
+
+// chunkGuild requests all members of the guild if ChunkLargeGuilds is true and
+// the guild is large. At most ChunkConcurrency guilds are chunked at once.
+func (s *State) chunkGuild(ev *gateway.GuildCreateEvent) {
+	if !s.ChunkLargeGuilds || !ev.Large || s.memberRequests == nil {
+		return
+	}
+
+	rs := s.memberRequests
+	rs.chunkOnce.Do(func() {
+		n := s.ChunkConcurrency
+		if n <= 0 {
+			n = DefaultChunkConcurrency
+		}
+		rs.chunkSema = make(chan struct{}, n)
+	})
+
+	guildID := ev.ID
+
+	go func() {
+		// Waiting for the other guilds doesn't count toward the timeout, so
+		// only stop waiting if the session is closed.
+		select {
+		case rs.chunkSema <- struct{}{}:
+			defer func() { <-rs.chunkSema }()
+		case <-s.Session.Done():
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), chunkTimeout)
+		defer cancel()
+
+		if _, err := s.RequestMembers(ctx, guildID, "", 0, false); err != nil {
+			s.stateErr(err, "failed to chunk guild "+guildID.String())
+		}
+	}()
+}
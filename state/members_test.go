@@ -0,0 +1,251 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/gateway/gatewaytest"
+	"github.com/diamondburned/arikawa/v3/session"
+	"github.com/diamondburned/arikawa/v3/state/store/defaultstore"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+func newTestState(t *testing.T) (*State, *gatewaytest.Server) {
+	srv := gatewaytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	id := gateway.DefaultIdentifier("Bot token")
+	id.Intents = option.NewUint(uint(gateway.IntentGuilds | gateway.IntentGuildMembers))
+
+	s := NewFromSession(session.NewWithGateway(srv.NewGateway(id), handler.New()), defaultstore.New())
+	t.Cleanup(func() { s.Close() })
+
+	return s, srv
+}
+
+func openTestState(t *testing.T, ctx context.Context, s *State, srv *gatewaytest.Server) *gatewaytest.Conn {
+	if err := s.Open(ctx); err != nil {
+		t.Fatal("failed to open:", err)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatal("failed to accept:", err)
+	}
+
+	return conn
+}
+
+// receiveMembersRequest receives the next Request Guild Members command,
+// skipping heartbeats.
+func receiveMembersRequest(t *testing.T, ctx context.Context, conn *gatewaytest.Conn) *gateway.RequestGuildMembersCommand {
+	t.Helper()
+
+	for {
+		ev, err := conn.Receive(ctx)
+		if err != nil {
+			t.Fatal("failed to receive members request:", err)
+		}
+		if cmd, ok := ev.(*gateway.RequestGuildMembersCommand); ok {
+			return cmd
+		}
+	}
+}
+
+func TestRequestMembers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	s, srv := newTestState(t)
+	conn := openTestState(t, ctx, s, srv)
+
+	type result struct {
+		*MembersResult
+		err error
+	}
+
+	resultCh := make(chan result, 1)
+	go func() {
+		r, err := s.RequestMembersByID(ctx, 1, []discord.UserID{2, 3, 4}, false)
+		resultCh <- result{r, err}
+	}()
+
+	cmd := receiveMembersRequest(t, ctx, conn)
+	if cmd.Nonce == "" {
+		t.Fatal("request has no nonce")
+	}
+	if len(cmd.UserIDs) != 3 {
+		t.Fatalf("unexpected user IDs %v", cmd.UserIDs)
+	}
+
+	chunks := []*gateway.GuildMembersChunkEvent{
+		// A chunk of another request, which must be ignored.
+		{
+			GuildID:    1,
+			Members:    []discord.Member{{User: discord.User{ID: 5}}},
+			ChunkCount: 1,
+			Nonce:      "other",
+		},
+		{
+			GuildID:    1,
+			Members:    []discord.Member{{User: discord.User{ID: 3}}},
+			ChunkIndex: 1,
+			ChunkCount: 2,
+			NotFound:   []string{"4"},
+			Nonce:      cmd.Nonce,
+		},
+		{
+			GuildID:    1,
+			Members:    []discord.Member{{User: discord.User{ID: 2}}},
+			ChunkIndex: 0,
+			ChunkCount: 2,
+			Nonce:      cmd.Nonce,
+		},
+	}
+
+	for _, chunk := range chunks {
+		if err := conn.Send(chunk); err != nil {
+			t.Fatal("failed to send chunk:", err)
+		}
+	}
+
+	r := <-resultCh
+	if r.err != nil {
+		t.Fatal("failed to request members:", r.err)
+	}
+
+	if len(r.Members) != 2 || r.Members[0].User.ID != 3 || r.Members[1].User.ID != 2 {
+		t.Errorf("unexpected members %v", r.Members)
+	}
+	if len(r.NotFound) != 1 || r.NotFound[0] != 4 {
+		t.Errorf("unexpected not found %v", r.NotFound)
+	}
+
+	if _, err := s.Cabinet.Member(1, 2); err != nil {
+		t.Error("requested member is not in the store:", err)
+	}
+}
+
+func TestRequestMembersCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	s, srv := newTestState(t)
+	conn := openTestState(t, ctx, s, srv)
+
+	reqCtx, reqCancel := context.WithCancel(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.RequestMembers(reqCtx, 1, "a", 10, false)
+		errCh <- err
+	}()
+
+	cmd := receiveMembersRequest(t, ctx, conn)
+	if cmd.Query == nil || *cmd.Query != "a" || cmd.Limit != 10 {
+		t.Fatalf("unexpected request %#v", cmd)
+	}
+
+	// Only send the first of two chunks.
+	conn.Send(&gateway.GuildMembersChunkEvent{GuildID: 1, ChunkCount: 2, Nonce: cmd.Nonce})
+	reqCancel()
+
+	if err := <-errCh; err == nil {
+		t.Fatal("expected an error after cancelling")
+	}
+
+	s.memberRequests.mu.Lock()
+	n := len(s.memberRequests.requests)
+	s.memberRequests.mu.Unlock()
+
+	if n != 0 {
+		t.Fatalf("%d requests are still registered", n)
+	}
+}
+
+func TestChunkLargeGuilds(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	s, srv := newTestState(t)
+	s.ChunkLargeGuilds = true
+
+	chunked := make(chan *gateway.GuildMembersChunkEvent, 1)
+	s.AddHandler(chunked)
+
+	conn := openTestState(t, ctx, s, srv)
+
+	// Small guilds aren't chunked.
+	conn.Send(&gateway.GuildCreateEvent{Guild: discord.Guild{ID: 1}})
+	conn.Send(&gateway.GuildCreateEvent{Guild: discord.Guild{ID: 2}, Large: true})
+
+	cmd := receiveMembersRequest(t, ctx, conn)
+	if len(cmd.GuildIDs) != 1 || cmd.GuildIDs[0] != 2 {
+		t.Fatalf("unexpected guilds %v", cmd.GuildIDs)
+	}
+
+	conn.Send(&gateway.GuildMembersChunkEvent{
+		GuildID:    2,
+		Members:    []discord.Member{{User: discord.User{ID: 10}}},
+		ChunkCount: 1,
+		Nonce:      cmd.Nonce,
+	})
+
+	select {
+	case <-chunked:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for chunk")
+	}
+
+	if _, err := s.Cabinet.Member(2, 10); err != nil {
+		t.Error("chunked member is not in the store:", err)
+	}
+}
+
+func TestChunkLargeGuildsQueued(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	timeout := chunkTimeout
+	chunkTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { chunkTimeout = timeout })
+
+	s, srv := newTestState(t)
+	s.ChunkLargeGuilds = true
+	s.ChunkConcurrency = 1
+
+	errs := make(chan error, 3)
+	s.StateLog = func(err error) { errs <- err }
+
+	conn := openTestState(t, ctx, s, srv)
+
+	// The last guild waits for longer than the timeout before it's requested.
+	for id := discord.GuildID(1); id <= 3; id++ {
+		conn.Send(&gateway.GuildCreateEvent{Guild: discord.Guild{ID: id}, Large: true})
+	}
+
+	for i := 0; i < 3; i++ {
+		reqCtx, cancel := context.WithTimeout(ctx, time.Second)
+		cmd := receiveMembersRequest(t, reqCtx, conn)
+		cancel()
+
+		time.Sleep(150 * time.Millisecond)
+
+		conn.Send(&gateway.GuildMembersChunkEvent{
+			GuildID:    cmd.GuildIDs[0],
+			Members:    []discord.Member{{User: discord.User{ID: 10}}},
+			ChunkCount: 1,
+			Nonce:      cmd.Nonce,
+		})
+	}
+
+	select {
+	case err := <-errs:
+		t.Fatal("unexpected state error:", err)
+	default:
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/diamondburned/arikawa/v3/state/store"
 	"github.com/diamondburned/arikawa/v3/state/store/defaultstore"
 	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 
 	"github.com/pkg/errors"
 )
@@ -71,6 +72,12 @@ func NewShardFunc(opts func(*shard.Manager, *State)) shard.NewShardFunc {
 // The Message Create and Message Update events with the Member field provided
 // will have the User field copied from Author. This is because the User field
 // will be empty, while the Member structure expects it to be there.
+//
+// Instrumentation
+//
+// The Instrumenter field inherited from the API client receives an
+// instrument.CacheLookup event everytime a getter checks the store, as well as
+// an instrument.StateError event for every error given to StateLog.
 type State struct {
 	*session.Session
 	*store.Cabinet
@@ -95,6 +102,20 @@ type State struct {
 	// with the State.
 	*handler.Handler
 
+	// ChunkLargeGuilds, if true, makes the State request all members of every
+	// large guild once it receives its Guild Create event, since Discord only
+	// sends the online members of these guilds. This requires the
+	// GUILD_MEMBERS intent. See RequestMembers.
+	ChunkLargeGuilds bool
+	// ChunkConcurrency is the maximum number of guilds that are chunked at the
+	// same time if ChunkLargeGuilds is true. If it's 0, then
+	// DefaultChunkConcurrency is used. It must be set before the State is
+	// opened.
+	ChunkConcurrency int
+
+	// memberRequests keeps track of the requests made by RequestMembers.
+	memberRequests *memberRequests
+
 	// List of channels with few messages, so it doesn't bother hitting the API
 	// again.
 	fewMessages map[discord.ChannelID]struct{}
@@ -148,6 +169,7 @@ func NewFromSession(s *session.Session, cabinet *store.Cabinet) *State {
 		unavailableGuilds: make(map[discord.GuildID]struct{}),
 		unreadyGuilds:     make(map[discord.GuildID]struct{}),
 		guildMutex:        new(sync.Mutex),
+		memberRequests:    newMemberRequests(),
 	}
 	state.hookSession()
 	return state
@@ -345,6 +367,7 @@ func (s *State) Permissions(
 
 func (s *State) Me() (*discord.User, error) {
 	u, err := s.Cabinet.Me()
+	s.cacheLookup("me", err == nil)
 	if err == nil {
 		return u, nil
 	}
@@ -364,8 +387,10 @@ func (s *State) Me() (*discord.User, error) {
 func (s *State) Channel(id discord.ChannelID) (c *discord.Channel, err error) {
 	c, err = s.Cabinet.Channel(id)
 	if err == nil && s.tracksChannel(c) {
+		s.cacheLookup("channel", true)
 		return
 	}
+	s.cacheLookup("channel", false)
 
 	c, err = s.Session.Channel(id)
 	if err != nil {
@@ -383,9 +408,11 @@ func (s *State) Channels(guildID discord.GuildID) (cs []discord.Channel, err err
 	if s.HasIntents(gateway.IntentGuilds) {
 		cs, err = s.Cabinet.Channels(guildID)
 		if err == nil {
+			s.cacheLookup("channels", true)
 			return
 		}
 	}
+	s.cacheLookup("channels", false)
 
 	cs, err = s.Session.Channels(guildID)
 	if err != nil {
@@ -403,6 +430,7 @@ func (s *State) Channels(guildID discord.GuildID) (cs []discord.Channel, err err
 
 func (s *State) CreatePrivateChannel(recipient discord.UserID) (*discord.Channel, error) {
 	c, err := s.Cabinet.CreatePrivateChannel(recipient)
+	s.cacheLookup("private_channel", err == nil)
 	if err == nil {
 		return c, nil
 	}
@@ -421,6 +449,7 @@ func (s *State) CreatePrivateChannel(recipient discord.UserID) (*discord.Channel
 // This is not supported for bots.
 func (s *State) PrivateChannels() ([]discord.Channel, error) {
 	cs, err := s.Cabinet.PrivateChannels()
+	s.cacheLookup("private_channels", err == nil)
 	if err == nil {
 		return cs, nil
 	}
@@ -444,10 +473,12 @@ func (s *State) Emoji(
 
 	if s.HasIntents(gateway.IntentGuildEmojis) {
 		e, err = s.Cabinet.Emoji(guildID, emojiID)
+		s.cacheLookup("emoji", err == nil)
 		if err == nil {
 			return
 		}
 	} else { // Fast path
+		s.cacheLookup("emoji", false)
 		return s.Session.Emoji(guildID, emojiID)
 	}
 
@@ -471,9 +502,11 @@ func (s *State) Emojis(guildID discord.GuildID) (es []discord.Emoji, err error)
 	if s.HasIntents(gateway.IntentGuildEmojis) {
 		es, err = s.Cabinet.Emojis(guildID)
 		if err == nil {
+			s.cacheLookup("emojis", true)
 			return
 		}
 	}
+	s.cacheLookup("emojis", false)
 
 	es, err = s.Session.Emojis(guildID)
 	if err != nil {
@@ -493,9 +526,11 @@ func (s *State) Guild(id discord.GuildID) (*discord.Guild, error) {
 	if s.HasIntents(gateway.IntentGuilds) {
 		c, err := s.Cabinet.Guild(id)
 		if err == nil {
+			s.cacheLookup("guild", true)
 			return c, nil
 		}
 	}
+	s.cacheLookup("guild", false)
 
 	return s.fetchGuild(id)
 }
@@ -505,9 +540,11 @@ func (s *State) Guilds() (gs []discord.Guild, err error) {
 	if s.HasIntents(gateway.IntentGuilds) {
 		gs, err = s.Cabinet.Guilds()
 		if err == nil {
+			s.cacheLookup("guilds", true)
 			return
 		}
 	}
+	s.cacheLookup("guilds", false)
 
 	gs, err = s.Session.Guilds(MaxFetchGuilds)
 	if err != nil {
@@ -529,9 +566,11 @@ func (s *State) Member(guildID discord.GuildID, userID discord.UserID) (*discord
 	if s.HasIntents(gateway.IntentGuildMembers) {
 		m, err := s.Cabinet.Member(guildID, userID)
 		if err == nil {
+			s.cacheLookup("member", true)
 			return m, nil
 		}
 	}
+	s.cacheLookup("member", false)
 
 	return s.fetchMember(guildID, userID)
 }
@@ -540,9 +579,11 @@ func (s *State) Members(guildID discord.GuildID) (ms []discord.Member, err error
 	if s.HasIntents(gateway.IntentGuildMembers) {
 		ms, err = s.Cabinet.Members(guildID)
 		if err == nil {
+			s.cacheLookup("members", true)
 			return
 		}
 	}
+	s.cacheLookup("members", false)
 
 	ms, err = s.Session.Members(guildID, MaxFetchMembers)
 	if err != nil {
@@ -565,8 +606,10 @@ func (s *State) Message(
 
 	m, err := s.Cabinet.Message(channelID, messageID)
 	if err == nil && s.tracksMessage(m) {
+		s.cacheLookup("message", true)
 		return m, nil
 	}
+	s.cacheLookup("message", false)
 
 	var (
 		wg sync.WaitGroup
@@ -623,6 +666,7 @@ func (s *State) Messages(channelID discord.ChannelID, limit uint) ([]discord.Mes
 		s.fewMutex.Lock()
 		if _, ok := s.fewMessages[channelID]; ok {
 			s.fewMutex.Unlock()
+			s.cacheLookup("messages", true)
 			return storeMessages, nil
 		}
 
@@ -636,8 +680,10 @@ func (s *State) Messages(channelID discord.ChannelID, limit uint) ([]discord.Mes
 
 	// Store already has enough messages.
 	if len(storeMessages) >= int(limit) && limit > 0 {
+		s.cacheLookup("messages", true)
 		return storeMessages[:limit], nil
 	}
+	s.cacheLookup("messages", false)
 
 	// Decrease the limit, if we aren't fetching all messages.
 	if limit > 0 {
@@ -737,9 +783,11 @@ func (s *State) Role(guildID discord.GuildID, roleID discord.RoleID) (target *di
 	if s.HasIntents(gateway.IntentGuilds) {
 		target, err = s.Cabinet.Role(guildID, roleID)
 		if err == nil {
+			s.cacheLookup("role", true)
 			return
 		}
 	}
+	s.cacheLookup("role", false)
 
 	rs, err := s.Session.Roles(guildID)
 	if err != nil {
@@ -766,6 +814,7 @@ func (s *State) Role(guildID discord.GuildID, roleID discord.RoleID) (target *di
 
 func (s *State) Roles(guildID discord.GuildID) ([]discord.Role, error) {
 	rs, err := s.Cabinet.Roles(guildID)
+	s.cacheLookup("roles", err == nil)
 	if err == nil {
 		return rs, nil
 	}
@@ -802,6 +851,14 @@ func (s *State) fetchMember(gID discord.GuildID, uID discord.UserID) (m *discord
 	return
 }
 
+// cacheLookup sends an instrument.CacheLookup event if the state is
+// instrumented.
+func (s *State) cacheLookup(kind string, hit bool) {
+	if s.Session != nil && s.Client != nil && s.Instrumenter != nil {
+		s.Instrumenter.Observe(instrument.CacheLookup{Kind: kind, Hit: hit})
+	}
+}
+
 // tracksMessage reports whether the state would track the passed message and
 // messages from the same channel.
 func (s *State) tracksMessage(m *discord.Message) bool {
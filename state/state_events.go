@@ -6,6 +6,7 @@ import (
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state/store"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 )
 
 func (s *State) hookSession() {
@@ -25,6 +26,10 @@ func (s *State) hookSession() {
 		case *gateway.GuildCreateEvent:
 			s.Handler.Call(event)
 			s.handleGuildCreate(event)
+			s.chunkGuild(event)
+		case *gateway.GuildMembersChunkEvent:
+			s.Handler.Call(event)
+			s.handleMembersChunk(event)
 		case *gateway.GuildDeleteEvent:
 			s.Handler.Call(event)
 			s.handleGuildDelete(event)
@@ -375,12 +380,20 @@ func (s *State) onEvent(iface interface{}) {
 }
 
 func (s *State) stateErr(err error, wrap string) {
-	s.StateLog(errors.Wrap(err, wrap))
+	s.logErr(errors.Wrap(err, wrap))
 }
 
 func (s *State) batchLog(errors []error) {
 	for _, err := range errors {
-		s.StateLog(err)
+		s.logErr(err)
+	}
+}
+
+func (s *State) logErr(err error) {
+	s.StateLog(err)
+
+	if s.Instrumenter != nil {
+		s.Instrumenter.Observe(instrument.StateError{Err: err})
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"sync"
 
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/utils/ws"
 )
@@ -113,6 +114,10 @@ type MethodContext struct {
 
 	Description string
 
+	// DescriptionLocalizations contains translations of Description. It is
+	// used by (*Context).HelpLocale and for slash commands.
+	DescriptionLocalizations discord.StringLocales
+
 	// MethodName is the name of the method. This field should NOT be changed.
 	MethodName string
 
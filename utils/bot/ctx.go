@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/session"
 	"github.com/diamondburned/arikawa/v3/session/shard"
@@ -100,6 +101,23 @@ func NewShardFunc(fn func(*state.State) (*Context, error)) shard.NewShardFunc {
 //        log.Println("Someone's typing!")
 //        return nil
 //    }
+//
+// Slash Commands
+//
+// If SlashCommand is set, then the commands can also be invoked as application
+// commands. The context's commands become subcommands of that application
+// command, and each Subcommand becomes a subcommand group:
+//
+//    ctx.SlashCommand = "bot"
+//    ctx.RegisterSlashCommand(appID, 0)
+//    // "~example 123" can now also be ran as "/bot example i:123".
+//
+// Interactions go through the same global and command middlewares as messages
+// do, using a MessageCreateEvent made from the interaction, but not through
+// other MessageCreate event handlers. The returned data is sent as the
+// interaction response. Note that the event has no message ID or content.
+// Interactions of commands returning no data are acknowledged without a
+// message.
 type Context struct {
 	*Subcommand
 	*state.State
@@ -165,6 +183,17 @@ type Context struct {
 	// to quickly edit a message and re-execute the command.
 	EditableCommands bool
 
+	// Catalog contains the templates of the help message and of the error
+	// replies, keyed by language. The language of a reply is chosen by Locale.
+	// Use Catalog.Set to override or translate messages.
+	Catalog *Catalog
+
+	// SlashCommand, if not empty, is the name of the application command that
+	// the commands are registered under. InteractionCreate events for that
+	// command will be dispatched to the command methods. Refer to
+	// SlashCommandData and RegisterSlashCommand.
+	SlashCommand string
+
 	// Subcommands contains all the registered subcommands. This is not
 	// exported, as it shouldn't be used directly.
 	subcommands []*Subcommand
@@ -294,6 +323,7 @@ func New(s *state.State, cmd interface{}) (*Context, error) {
 			log.Println("Bot error:", err)
 		},
 		ReplyError: true,
+		Catalog:    NewCatalog(),
 	}
 
 	if err := ctx.InitCommands(ctx); err != nil {
@@ -452,14 +482,20 @@ func (ctx *Context) Help() string {
 // for people to reimplement and change. If showHidden is true, then hidden
 // subcommands and commands will be shown.
 func (ctx *Context) HelpGenerate(showHidden bool) string {
+	return ctx.HelpLocale("", showHidden)
+}
+
+// HelpLocale generates a full Help message in the given language, using the
+// headings from Catalog and the localized descriptions of the commands. Use
+// Locale to get the language of an event. If showHidden is true, then hidden
+// subcommands and commands will be shown.
+func (ctx *Context) HelpLocale(lang discord.Language, showHidden bool) string {
+	catalog := ctx.catalog()
+
 	// Generate the header.
 	buf := strings.Builder{}
-	buf.WriteString("__Help__")
+	buf.WriteString(catalog.Render(lang, MessageHelpHeader, ctx))
 
-	// Name an
-	if ctx.Name != "" {
-		buf.WriteString(": " + ctx.Name)
-	}
 	if ctx.Description != "" {
 		buf.WriteString("\n" + IndentLines(ctx.Description))
 	}
@@ -468,8 +504,9 @@ func (ctx *Context) HelpGenerate(showHidden bool) string {
 	buf.WriteString("\n---\n")
 
 	// Generate all commands
-	if help := ctx.Subcommand.Help(); help != "" {
-		buf.WriteString("__Commands__\n")
+	if help := ctx.Subcommand.helpLocale(lang, false); help != "" {
+		buf.WriteString(catalog.Render(lang, MessageHelpCommands, nil))
+		buf.WriteByte('\n')
 		buf.WriteString(IndentLines(help))
 		buf.WriteByte('\n')
 	}
@@ -482,7 +519,7 @@ func (ctx *Context) HelpGenerate(showHidden bool) string {
 			continue
 		}
 
-		help := sub.HelpShowHidden(showHidden)
+		help := sub.helpLocale(lang, showHidden)
 		if help == "" {
 			continue
 		}
@@ -501,9 +538,9 @@ func (ctx *Context) HelpGenerate(showHidden bool) string {
 			builder.WriteString("**")
 		}
 
-		if sub.Description != "" {
+		if desc := localizedDescription(sub.Description, sub.DescriptionLocalizations, lang); desc != "" {
 			builder.WriteString(": ")
-			builder.WriteString(sub.Description)
+			builder.WriteString(desc)
 		}
 
 		builder.WriteByte('\n')
@@ -514,7 +551,8 @@ func (ctx *Context) HelpGenerate(showHidden bool) string {
 
 	if len(subhelps) > 0 {
 		buf.WriteString("---\n")
-		buf.WriteString("__Subcommands__\n")
+		buf.WriteString(catalog.Render(lang, MessageHelpSubcommands, nil))
+		buf.WriteByte('\n')
 		buf.WriteString(IndentLines(strings.Join(subhelps, "\n")))
 	}
 
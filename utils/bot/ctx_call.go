@@ -29,10 +29,9 @@ func (ctx *Context) filterEventType(evT reflect.Type) (callers [][]caller) {
 	return
 }
 
-func (ctx *Context) callCmd(ev interface{}) (bottomError error) {
-	evV := reflect.ValueOf(ev)
-	evT := evV.Type()
-
+// callEventCallers calls all event handlers and middlewares of the given event
+// type with evV.
+func (ctx *Context) callEventCallers(evT reflect.Type, evV reflect.Value) (bottomError error) {
 	var callers [][]caller
 
 	// Hit the cache
@@ -59,8 +58,15 @@ func (ctx *Context) callCmd(ev interface{}) (bottomError error) {
 		}
 	}
 
-	if bottomError != nil {
-		return bottomError
+	return
+}
+
+func (ctx *Context) callCmd(ev interface{}) error {
+	evV := reflect.ValueOf(ev)
+	evT := evV.Type()
+
+	if err := ctx.callEventCallers(evT, evV); err != nil {
+		return err
 	}
 
 	var msc *gateway.MessageCreateEvent
@@ -100,6 +106,9 @@ func (ctx *Context) callCmd(ev interface{}) (bottomError error) {
 		// Update the reflect value as well.
 		evV = reflect.ValueOf(msc)
 
+	case evT == typeInteractionCreate && ctx.SlashCommand != "":
+		return ctx.callInteraction(ev.(*gateway.InteractionCreateEvent))
+
 	default:
 		// Unknown event, return.
 		return nil
@@ -128,7 +137,7 @@ func (ctx *Context) callMessageCreate(
 		if ctx.ErrorReplier != nil {
 			data = ctx.ErrorReplier(err, mc)
 		} else {
-			data.Content = ctx.FormatError(err)
+			data.Content = ctx.FormatError(ctx.LocalizeError(ctx.Locale(mc), err))
 		}
 	} else {
 		switch v := v.(type) {
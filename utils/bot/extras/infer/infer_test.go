@@ -51,6 +51,44 @@ func TestReflectChannelID(t *testing.T) {
 	})
 }
 
+type hasAuthor struct {
+	Member *hasUser
+	Author discord.User
+}
+
+type hasUser struct {
+	User discord.User
+}
+
+func TestUser(t *testing.T) {
+	t.Run("Author", func(t *testing.T) {
+		s := &hasAuthor{Author: discord.User{ID: 1}}
+		if u := User(s); u == nil || u.ID != 1 {
+			t.Fatal("unexpected user:", u)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		s := &hasAuthor{Member: &hasUser{User: discord.User{ID: 2}}}
+		if u := User(s); u == nil || u.ID != 2 {
+			t.Fatal("unexpected user:", u)
+		}
+	})
+
+	t.Run("sender", func(t *testing.T) {
+		s := &discord.InteractionEvent{User: &discord.User{ID: 3}}
+		if u := User(s); u == nil || u.ID != 3 {
+			t.Fatal("unexpected user:", u)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		if u := User(&hasID{}); u != nil {
+			t.Fatal("unexpected user:", u)
+		}
+	})
+}
+
 func BenchmarkReflectChannelID_1Level(b *testing.B) {
 	var s = &hasID{
 		ChannelID: 69420,
@@ -85,3 +123,21 @@ func BenchmarkReflectChannelID_5Level(b *testing.B) {
 		}
 	}
 }
+
+type hasSender struct {
+	Author discord.User
+	sender discord.UserID
+}
+
+func (h *hasSender) SenderID() discord.UserID { return h.sender }
+
+func TestReflectUserIDSender(t *testing.T) {
+	var s = &hasSender{
+		Author: discord.User{ID: 1},
+		sender: 69420,
+	}
+
+	if id := UserID(s); id != 69420 {
+		t.Fatal("unexpected userID:", id)
+	}
+}
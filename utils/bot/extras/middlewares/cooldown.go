@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/bot"
+	"github.com/diamondburned/arikawa/v3/utils/bot/extras/infer"
+)
+
+// CooldownBucket determines what a cooldown is counted against.
+type CooldownBucket uint8
+
+const (
+	// UserBucket counts uses per user.
+	UserBucket CooldownBucket = iota
+	// ChannelBucket counts uses per channel.
+	ChannelBucket
+	// GuildBucket counts uses per guild. Events outside of guilds are counted
+	// per channel.
+	GuildBucket
+)
+
+// String returns the name of the bucket.
+func (b CooldownBucket) String() string {
+	switch b {
+	case UserBucket:
+		return "user"
+	case ChannelBucket:
+		return "channel"
+	case GuildBucket:
+		return "guild"
+	default:
+		return "unknown"
+	}
+}
+
+// Cooldown limits the given bucket to the given number of uses within the
+// sliding window. It returns a *CooldownError once the bucket is used up.
+//
+// Each call to Cooldown creates its own set of buckets, so the returned
+// middleware should be shared between the commands that share the cooldown.
+func Cooldown(ctx *bot.Context, bucket CooldownBucket, uses int, window time.Duration) func(interface{}) error {
+	if uses < 1 {
+		uses = 1
+	}
+
+	c := &cooldown{
+		uses:   uses,
+		window: window,
+		hits:   make(map[discord.Snowflake][]time.Time),
+	}
+
+	return func(ev interface{}) error {
+		var id discord.Snowflake
+
+		switch bucket {
+		case UserBucket:
+			id = discord.Snowflake(infer.UserID(ev))
+		case ChannelBucket:
+			id = discord.Snowflake(infer.ChannelID(ev))
+		case GuildBucket:
+			if id = discord.Snowflake(inferGuildID(ctx, ev)); !id.IsValid() {
+				id = discord.Snowflake(infer.ChannelID(ev))
+			}
+		}
+
+		if !id.IsValid() {
+			return bot.Break
+		}
+
+		if remaining := c.take(id, time.Now()); remaining > 0 {
+			return &CooldownError{
+				Bucket:    bucket,
+				ID:        id,
+				Remaining: remaining,
+			}
+		}
+
+		return nil
+	}
+}
+
+type cooldown struct {
+	uses   int
+	window time.Duration
+
+	mut       sync.Mutex
+	hits      map[discord.Snowflake][]time.Time
+	lastSweep time.Time
+}
+
+// take records a use of the bucket with the given ID. If the bucket is used up,
+// then nothing is recorded and the time until the next use is returned.
+func (c *cooldown) take(id discord.Snowflake, now time.Time) time.Duration {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.sweep(now)
+
+	hits := expire(c.hits[id], now.Add(-c.window))
+
+	if len(hits) >= c.uses {
+		c.hits[id] = hits
+		return hits[0].Add(c.window).Sub(now)
+	}
+
+	c.hits[id] = append(hits, now)
+	return 0
+}
+
+// sweep removes buckets that have no uses within the window, so that buckets
+// of inactive users don't pile up. It runs at most once per window.
+func (c *cooldown) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.window {
+		return
+	}
+	c.lastSweep = now
+
+	for id, hits := range c.hits {
+		if hits = expire(hits, now.Add(-c.window)); len(hits) == 0 {
+			delete(c.hits, id)
+		} else {
+			c.hits[id] = hits
+		}
+	}
+}
+
+// expire drops the hits that happened before the given time. Hits are ordered
+// from oldest to newest.
+func expire(hits []time.Time, before time.Time) []time.Time {
+	for len(hits) > 0 && !hits[0].After(before) {
+		hits = hits[1:]
+	}
+	return hits
+}
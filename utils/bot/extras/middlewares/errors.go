@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// PermissionsError is returned by RequirePermissions and RequireSelfPermissions
+// if the user or the bot lacks any of the required permissions.
+type PermissionsError struct {
+	// UserID is the user that lacks the permissions.
+	UserID discord.UserID
+	// ChannelID is the channel that the permissions were checked in.
+	ChannelID discord.ChannelID
+	// Self is true if the user is the bot itself.
+	Self bool
+	// Missing contains the missing permissions.
+	Missing discord.Permissions
+}
+
+func (err *PermissionsError) Error() string {
+	if err.Self {
+		return fmt.Sprintf("the bot is missing permissions (%d) in this channel", err.Missing)
+	}
+	return fmt.Sprintf("you are missing permissions (%d) to use this", err.Missing)
+}
+
+// NSFWError is returned by NSFWOnly if the channel is not marked as NSFW.
+type NSFWError struct {
+	ChannelID discord.ChannelID
+}
+
+func (err *NSFWError) Error() string {
+	return "this can only be used in NSFW channels"
+}
+
+// OwnerError is returned by OwnerOnly if the user doesn't own the bot.
+type OwnerError struct {
+	UserID discord.UserID
+}
+
+func (err *OwnerError) Error() string {
+	return "this can only be used by the bot owners"
+}
+
+// RolesError is returned by RolesOnly if the user has none of the allowed
+// roles.
+type RolesError struct {
+	UserID  discord.UserID
+	GuildID discord.GuildID
+	// Roles contains the allowed roles.
+	Roles []discord.RoleID
+}
+
+func (err *RolesError) Error() string {
+	return "you don't have any of the roles needed to use this"
+}
+
+// CooldownError is returned by Cooldown if the bucket has been used up.
+type CooldownError struct {
+	Bucket CooldownBucket
+	// ID is the ID of the user, channel or guild, depending on Bucket.
+	ID discord.Snowflake
+	// Remaining is the time until the bucket can be used again.
+	Remaining time.Duration
+}
+
+func (err *CooldownError) Error() string {
+	remaining := err.Remaining.Round(time.Second)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+
+	return fmt.Sprintf("this is on cooldown, try again in %s", remaining)
+}
@@ -1,9 +1,13 @@
 package middlewares
 
 import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/utils/bot"
 	"github.com/diamondburned/arikawa/v3/utils/bot/extras/infer"
-	"github.com/diamondburned/arikawa/v3/discord"
 )
 
 func AdminOnly(ctx *bot.Context) func(interface{}) error {
@@ -47,3 +51,209 @@ func GuildOnly(ctx *bot.Context) func(interface{}) error {
 		return nil
 	}
 }
+
+// RequirePermissions makes sure that the user has all the given permissions in
+// the channel. It returns a *PermissionsError if the user lacks any of them,
+// including when the channel is not in a guild.
+func RequirePermissions(ctx *bot.Context, perms discord.Permissions) func(interface{}) error {
+	return func(ev interface{}) error {
+		var channelID = infer.ChannelID(ev)
+		if !channelID.IsValid() {
+			return bot.Break
+		}
+
+		var userID = infer.UserID(ev)
+		if !userID.IsValid() {
+			return bot.Break
+		}
+
+		return checkPermissions(ctx, channelID, userID, perms, false)
+	}
+}
+
+// RequireSelfPermissions makes sure that the bot has all the given permissions
+// in the channel. It returns a *PermissionsError with Self set to true if the
+// bot lacks any of them.
+func RequireSelfPermissions(ctx *bot.Context, perms discord.Permissions) func(interface{}) error {
+	return func(ev interface{}) error {
+		var channelID = infer.ChannelID(ev)
+		if !channelID.IsValid() {
+			return bot.Break
+		}
+
+		me, err := ctx.Me()
+		if err != nil {
+			return errors.Wrap(err, "failed to get the bot user")
+		}
+
+		return checkPermissions(ctx, channelID, me.ID, perms, true)
+	}
+}
+
+func checkPermissions(
+	ctx *bot.Context,
+	channelID discord.ChannelID, userID discord.UserID,
+	perms discord.Permissions, self bool) error {
+
+	permErr := &PermissionsError{
+		UserID:    userID,
+		ChannelID: channelID,
+		Self:      self,
+		Missing:   perms,
+	}
+
+	c, err := ctx.Channel(channelID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get channel")
+	}
+	if !c.GuildID.IsValid() {
+		return permErr
+	}
+
+	p, err := ctx.Permissions(channelID, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get permissions")
+	}
+
+	if p.Has(discord.PermissionAdministrator) {
+		return nil
+	}
+
+	if permErr.Missing = perms &^ p; permErr.Missing != 0 {
+		return permErr
+	}
+
+	return nil
+}
+
+// NSFWOnly makes sure that the channel, or the parent channel of a thread, is
+// marked as NSFW. It returns a *NSFWError otherwise.
+func NSFWOnly(ctx *bot.Context) func(interface{}) error {
+	return func(ev interface{}) error {
+		var channelID = infer.ChannelID(ev)
+		if !channelID.IsValid() {
+			return bot.Break
+		}
+
+		c, err := ctx.Channel(channelID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get channel")
+		}
+
+		switch c.Type {
+		case discord.GuildNewsThread, discord.GuildPublicThread, discord.GuildPrivateThread:
+			if c, err = ctx.Channel(c.ParentID); err != nil {
+				return errors.Wrap(err, "failed to get parent channel")
+			}
+		}
+
+		if !c.NSFW {
+			return &NSFWError{ChannelID: channelID}
+		}
+
+		return nil
+	}
+}
+
+// OwnerOnly makes sure that the user owns the bot's application. If the
+// application belongs to a team, then all members who have accepted the team
+// invite are considered owners. The application is fetched once on first use.
+// It returns an *OwnerError if the user isn't an owner.
+func OwnerOnly(ctx *bot.Context) func(interface{}) error {
+	var mut sync.Mutex
+	var owners map[discord.UserID]struct{}
+
+	return func(ev interface{}) error {
+		var userID = infer.UserID(ev)
+		if !userID.IsValid() {
+			return bot.Break
+		}
+
+		mut.Lock()
+		defer mut.Unlock()
+
+		if owners == nil {
+			app, err := ctx.CurrentApplication()
+			if err != nil {
+				return errors.Wrap(err, "failed to get current application")
+			}
+
+			owners = make(map[discord.UserID]struct{}, 1)
+
+			if app.Team != nil {
+				for _, member := range app.Team.Members {
+					if member.MembershipState == discord.MembershipAccepted {
+						owners[member.User.ID] = struct{}{}
+					}
+				}
+			} else if app.Owner != nil {
+				owners[app.Owner.ID] = struct{}{}
+			}
+		}
+
+		if _, ok := owners[userID]; !ok {
+			return &OwnerError{UserID: userID}
+		}
+
+		return nil
+	}
+}
+
+// RolesOnly makes sure that the user has at least one of the given roles. It
+// returns a *RolesError if the user has none of them, including when the event
+// is not from a guild.
+func RolesOnly(ctx *bot.Context, roles ...discord.RoleID) func(interface{}) error {
+	return func(ev interface{}) error {
+		var userID = infer.UserID(ev)
+		if !userID.IsValid() {
+			return bot.Break
+		}
+
+		var guildID = inferGuildID(ctx, ev)
+
+		rolesErr := &RolesError{
+			UserID:  userID,
+			GuildID: guildID,
+			Roles:   roles,
+		}
+
+		if !guildID.IsValid() {
+			return rolesErr
+		}
+
+		m, err := ctx.Member(guildID, userID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get member")
+		}
+
+		for _, has := range m.RoleIDs {
+			for _, role := range roles {
+				if has == role {
+					return nil
+				}
+			}
+		}
+
+		return rolesErr
+	}
+}
+
+// inferGuildID infers the guild ID from the event, falling back to the guild
+// of the event's channel.
+func inferGuildID(ctx *bot.Context, ev interface{}) discord.GuildID {
+	if guildID := infer.GuildID(ev); guildID.IsValid() {
+		return guildID
+	}
+
+	var channelID = infer.ChannelID(ev)
+	if !channelID.IsValid() {
+		return 0
+	}
+
+	c, err := ctx.Channel(channelID)
+	if err != nil {
+		return 0
+	}
+
+	return c.GuildID
+}
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
@@ -93,6 +94,142 @@ func TestGuildOnly(t *testing.T) {
 	})
 }
 
+func newMockContext() *bot.Context {
+	return &bot.Context{
+		State: &state.State{
+			Session: session.New(""),
+			Cabinet: mockCabinet(),
+		},
+	}
+}
+
+func TestRequirePermissions(t *testing.T) {
+	var ctx = newMockContext()
+
+	message := func(channelID discord.ChannelID, userID discord.UserID) *gateway.MessageCreateEvent {
+		return &gateway.MessageCreateEvent{
+			Message: discord.Message{
+				ChannelID: channelID,
+				Author:    discord.User{ID: userID},
+			},
+		}
+	}
+
+	t.Run("allow", func(t *testing.T) {
+		var middleware = RequirePermissions(ctx, discord.PermissionSendMessages)
+		expectNil(t, middleware(message(69420, 100)))
+		// Administrators have all permissions.
+		expectNil(t, middleware(message(69420, 69420)))
+	})
+
+	t.Run("allow interaction", func(t *testing.T) {
+		var middleware = RequirePermissions(ctx, discord.PermissionSendMessages)
+		expectNil(t, middleware(&gateway.InteractionCreateEvent{
+			InteractionEvent: discord.InteractionEvent{
+				ChannelID: 69420,
+				Member:    &discord.Member{User: discord.User{ID: 100}},
+			},
+		}))
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		var middleware = RequirePermissions(ctx,
+			discord.PermissionSendMessages|discord.PermissionManageMessages)
+
+		var permErr *PermissionsError
+		if !errors.As(middleware(message(69420, 100)), &permErr) {
+			t.Fatal("expected PermissionsError")
+		}
+		if permErr.Missing != discord.PermissionManageMessages {
+			t.Fatal("unexpected missing permissions:", permErr.Missing)
+		}
+
+		// Channels outside of guilds have no permissions.
+		if !errors.As(middleware(message(12, 100)), &permErr) {
+			t.Fatal("expected PermissionsError in DM")
+		}
+	})
+}
+
+func TestNSFWOnly(t *testing.T) {
+	var middleware = NSFWOnly(newMockContext())
+
+	expectNil(t, middleware(&gateway.MessageCreateEvent{Message: discord.Message{ChannelID: 18}}))
+	expectNil(t, middleware(&gateway.MessageCreateEvent{Message: discord.Message{ChannelID: 19}}))
+
+	var nsfwErr *NSFWError
+	if !errors.As(middleware(&gateway.TypingStartEvent{ChannelID: 69420}), &nsfwErr) {
+		t.Fatal("expected NSFWError")
+	}
+}
+
+func TestRolesOnly(t *testing.T) {
+	var middleware = RolesOnly(newMockContext(), 100, 101)
+
+	expectNil(t, middleware(&gateway.MessageCreateEvent{
+		Message: discord.Message{ChannelID: 69420, Author: discord.User{ID: 100}},
+	}))
+
+	var rolesErr *RolesError
+	if !errors.As(middleware(&gateway.TypingStartEvent{ChannelID: 69420, UserID: 102}), &rolesErr) {
+		t.Fatal("expected RolesError")
+	}
+	if rolesErr.GuildID != 1337 {
+		t.Fatal("unexpected guild ID:", rolesErr.GuildID)
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	var middleware = Cooldown(newMockContext(), UserBucket, 2, time.Hour)
+
+	message := func(userID discord.UserID) *gateway.MessageCreateEvent {
+		return &gateway.MessageCreateEvent{
+			Message: discord.Message{ChannelID: 69420, Author: discord.User{ID: userID}},
+		}
+	}
+
+	expectNil(t, middleware(message(1)))
+	expectNil(t, middleware(message(1)))
+	expectNil(t, middleware(message(2)))
+
+	var cooldownErr *CooldownError
+	if !errors.As(middleware(message(1)), &cooldownErr) {
+		t.Fatal("expected CooldownError")
+	}
+	if cooldownErr.Bucket != UserBucket || cooldownErr.ID != 1 {
+		t.Fatalf("unexpected cooldown error %#v", cooldownErr)
+	}
+	if cooldownErr.Remaining <= 0 || cooldownErr.Remaining > time.Hour {
+		t.Fatal("unexpected remaining time:", cooldownErr.Remaining)
+	}
+}
+
+func TestCooldownWindow(t *testing.T) {
+	c := &cooldown{
+		uses:   1,
+		window: time.Minute,
+		hits:   make(map[discord.Snowflake][]time.Time),
+	}
+
+	now := time.Now()
+
+	if remaining := c.take(1, now); remaining != 0 {
+		t.Fatal("unexpected cooldown on first use:", remaining)
+	}
+	if remaining := c.take(1, now.Add(20*time.Second)); remaining != 40*time.Second {
+		t.Fatal("unexpected remaining time:", remaining)
+	}
+	if remaining := c.take(1, now.Add(time.Minute+time.Second)); remaining != 0 {
+		t.Fatal("unexpected cooldown after window:", remaining)
+	}
+
+	// Inactive buckets are swept after a window.
+	c.take(2, now.Add(3*time.Minute))
+	if _, ok := c.hits[1]; ok {
+		t.Fatal("inactive bucket was not swept")
+	}
+}
+
 func expectNil(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
@@ -181,6 +318,9 @@ func (s *mockStore) Guild(id discord.GuildID) (*discord.Guild, error) {
 		Roles: []discord.Role{{
 			ID:          69420,
 			Permissions: discord.PermissionAdministrator,
+		}, {
+			ID:          100,
+			Permissions: discord.PermissionSendMessages,
 		}},
 	}, nil
 }
@@ -192,12 +332,27 @@ func (s *mockStore) Member(_ discord.GuildID, userID discord.UserID) (*discord.M
 	}, nil
 }
 
-// Channel returns a channel with a guildID for #69420.
+// Channel returns a channel with a guildID for #69420, an NSFW channel for #18
+// and a thread in #18 for #19.
 func (s *mockStore) Channel(id discord.ChannelID) (*discord.Channel, error) {
-	if id == 69420 {
+	switch id {
+	case 69420:
+		return &discord.Channel{
+			ID:      id,
+			GuildID: 1337,
+		}, nil
+	case 18:
 		return &discord.Channel{
 			ID:      id,
 			GuildID: 1337,
+			NSFW:    true,
+		}, nil
+	case 19:
+		return &discord.Channel{
+			ID:       id,
+			Type:     discord.GuildPublicThread,
+			GuildID:  1337,
+			ParentID: 18,
 		}, nil
 	}
 
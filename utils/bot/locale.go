@@ -0,0 +1,317 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+)
+
+// Message IDs of the messages in a Catalog.
+const (
+	// MessageHelpHeader is the first line of the help message. Its data is
+	// the *Context.
+	MessageHelpHeader = "help_header"
+	// MessageHelpCommands is the heading above the commands in the help
+	// message. It has no data.
+	MessageHelpCommands = "help_commands"
+	// MessageHelpSubcommands is the heading above the subcommands in the help
+	// message. It has no data.
+	MessageHelpSubcommands = "help_subcommands"
+
+	// MessageUnknownCommand is the reply to an unknown command. Its data is
+	// the *UnknownCommandError.
+	MessageUnknownCommand = "unknown_command"
+	// MessageUnknownSubcommand is the reply to an unknown command of a known
+	// subcommand. Its data is the *UnknownCommandError.
+	MessageUnknownSubcommand = "unknown_subcommand"
+
+	// MessageInvalidUsage is the reply to a command with an invalid argument.
+	// Its data is an InvalidUsageData.
+	MessageInvalidUsage = "invalid_usage"
+	// MessageInvalidUsageError is the reply to a command with invalid
+	// arguments that can't be pointed at. Its data is an InvalidUsageData.
+	MessageInvalidUsageError = "invalid_usage_error"
+	// MessageMissingArguments is the reply to a command without enough
+	// arguments. Its data is an InvalidUsageData.
+	MessageMissingArguments = "missing_arguments"
+)
+
+// DefaultMessages contains the default English templates of all messages.
+// They render the same text as the non-localized functions, such as
+// UnknownCommandString and InvalidUsageString.
+var DefaultMessages = map[string]string{
+	MessageHelpHeader:      "__Help__{{with .Name}}: {{.}}{{end}}",
+	MessageHelpCommands:    "__Commands__",
+	MessageHelpSubcommands: "__Subcommands__",
+
+	MessageUnknownCommand:    "unknown command: {{index .Parts 0}}.",
+	MessageUnknownSubcommand: "unknown {{index .Parts 0}} subcommand: {{index .Parts 1}}.",
+
+	MessageInvalidUsage: "Invalid usage at {{.Prefix}}{{.Before}} __{{.Wrong}}__ {{.After}}" +
+		"{{with .Error}}\nError: {{.}}.{{end}}",
+	MessageInvalidUsageError: "invalid usage, error: {{.Error}}.",
+	MessageMissingArguments:  "missing arguments. Refer to help.",
+}
+
+// InvalidUsageData is the template data of the invalid usage messages.
+type InvalidUsageData struct {
+	*InvalidUsageError
+	// Before contains the arguments before the invalid one.
+	Before string
+	// Wrong is the invalid argument.
+	Wrong string
+	// After contains the arguments after the invalid one.
+	After string
+	// Error is the error of the invalid argument, if any.
+	Error string
+}
+
+// Catalog contains localized message templates keyed by language. The
+// templates use text/template. A Catalog is safe to use concurrently.
+type Catalog struct {
+	// Fallback is the language used when a message doesn't exist for the
+	// requested language. If the message doesn't exist for Fallback either,
+	// then DefaultMessages is used.
+	Fallback discord.Language // default: discord.EnglishUS
+
+	mut       sync.RWMutex
+	templates map[discord.Language]map[string]*template.Template
+	defaults  map[string]*template.Template // the templates of DefaultMessages
+}
+
+var defaultCatalog = NewCatalog()
+
+// NewCatalog creates a new Catalog with DefaultMessages as its English
+// messages.
+func NewCatalog() *Catalog {
+	c := &Catalog{
+		Fallback:  discord.EnglishUS,
+		templates: make(map[discord.Language]map[string]*template.Template),
+		defaults:  make(map[string]*template.Template, len(DefaultMessages)),
+	}
+
+	for id, text := range DefaultMessages {
+		c.MustSet(discord.EnglishUS, id, text)
+		c.defaults[id] = c.templates[discord.EnglishUS][id]
+	}
+
+	return c
+}
+
+// Set parses the template of the message with the given ID in the given
+// language.
+func (c *Catalog) Set(lang discord.Language, id, text string) error {
+	tmpl, err := template.New(id).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	messages, ok := c.templates[lang]
+	if !ok {
+		messages = make(map[string]*template.Template)
+		c.templates[lang] = messages
+	}
+
+	messages[id] = tmpl
+	return nil
+}
+
+// MustSet calls Set and panics on an error.
+func (c *Catalog) MustSet(lang discord.Language, id, text string) {
+	if err := c.Set(lang, id, text); err != nil {
+		panic(err)
+	}
+}
+
+// SetMessages sets multiple messages of the given language at once.
+func (c *Catalog) SetMessages(lang discord.Language, messages map[string]string) error {
+	for id, text := range messages {
+		if err := c.Set(lang, id, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDefault returns true if the message with the given ID would be rendered
+// from DefaultMessages in the given language.
+func (c *Catalog) isDefault(lang discord.Language, id string) bool {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	tmpl := c.templates[lang][id]
+	if tmpl == nil {
+		tmpl = c.templates[c.Fallback][id]
+	}
+
+	return tmpl == nil || tmpl == c.defaults[id]
+}
+
+// Render renders the message with the given ID in the given language. It
+// falls back to the Fallback language and then to DefaultMessages if the
+// message doesn't exist or fails to render.
+func (c *Catalog) Render(lang discord.Language, id string, data interface{}) string {
+	c.mut.RLock()
+	tmpls := [2]*template.Template{
+		c.templates[lang][id],
+		c.templates[c.Fallback][id],
+	}
+	c.mut.RUnlock()
+
+	var buf strings.Builder
+
+	for _, tmpl := range tmpls {
+		if tmpl == nil {
+			continue
+		}
+
+		buf.Reset()
+		if err := tmpl.Execute(&buf, data); err == nil {
+			return buf.String()
+		}
+	}
+
+	if c != defaultCatalog {
+		return defaultCatalog.Render(discord.EnglishUS, id, data)
+	}
+
+	return ""
+}
+
+// LocalizedError is an error with a message rendered from a Catalog. It is
+// given to FormatError in place of the original error.
+type LocalizedError struct {
+	Err      error
+	Language discord.Language
+	Message  string
+}
+
+func (err *LocalizedError) Error() string {
+	return err.Message
+}
+
+func (err *LocalizedError) Unwrap() error {
+	return err.Err
+}
+
+func (ctx *Context) catalog() *Catalog {
+	if ctx.Catalog != nil {
+		return ctx.Catalog
+	}
+	return defaultCatalog
+}
+
+// Locale returns the language to reply to the given event in. For interaction
+// events, the user's locale is used, falling back to the guild's. For other
+// events, the guild's preferred locale is used if the guild is known. An empty
+// string is returned if the locale can't be determined, in which case the
+// catalog's fallback is used.
+func (ctx *Context) Locale(ev interface{}) discord.Language {
+	var guildID discord.GuildID
+
+	switch ev := ev.(type) {
+	case *gateway.InteractionCreateEvent:
+		return interactionLocale(&ev.InteractionEvent)
+	case *discord.InteractionEvent:
+		return interactionLocale(ev)
+	case *gateway.MessageCreateEvent:
+		guildID = ev.GuildID
+	case *gateway.MessageUpdateEvent:
+		guildID = ev.GuildID
+	default:
+		return ""
+	}
+
+	if !guildID.IsValid() || ctx.State == nil {
+		return ""
+	}
+
+	g, err := ctx.Cabinet.Guild(guildID)
+	if err != nil {
+		return ""
+	}
+
+	return discord.Language(g.PreferredLocale)
+}
+
+func interactionLocale(ev *discord.InteractionEvent) discord.Language {
+	if ev.Locale != "" {
+		return ev.Locale
+	}
+	return discord.Language(ev.GuildLocale)
+}
+
+// LocalizeError renders the given error in the given language if it's an
+// UnknownCommandError or an InvalidUsageError. Other errors are returned as-is.
+//
+// If the message would be rendered from DefaultMessages, then the error is
+// also returned as-is, so that its message still comes from
+// UnknownCommandString or InvalidUsageString if those are overridden.
+func (ctx *Context) LocalizeError(lang discord.Language, err error) error {
+	var (
+		unknown *UnknownCommandError
+		usage   *InvalidUsageError
+
+		id   string
+		data interface{}
+	)
+
+	switch {
+	case errors.As(err, &unknown):
+		data = unknown
+		id = MessageUnknownCommand
+		if unknown.Subcmd.StructName != "" && len(unknown.Parts) >= 2 {
+			id = MessageUnknownSubcommand
+		}
+
+	case errors.As(err, &usage):
+		usageData := InvalidUsageData{InvalidUsageError: usage}
+		if usage.Wrap != nil {
+			usageData.Error = usage.Wrap.Error()
+		}
+
+		switch {
+		case usage.Index == 0 && usage.Wrap != nil:
+			id = MessageInvalidUsageError
+		case usage.Index == 0 || len(usage.Args) == 0:
+			id = MessageMissingArguments
+		default:
+			id = MessageInvalidUsage
+			usageData.Before = strings.Join(usage.Args[:usage.Index], " ")
+			usageData.Wrong = usage.Args[usage.Index]
+			usageData.After = strings.Join(usage.Args[usage.Index+1:], " ")
+		}
+
+		data = usageData
+
+	default:
+		return err
+	}
+
+	catalog := ctx.catalog()
+	if catalog.isDefault(lang, id) {
+		return err
+	}
+
+	return &LocalizedError{
+		Err:      err,
+		Language: lang,
+		Message:  catalog.Render(lang, id, data),
+	}
+}
+
+// localizedDescription returns the description in the given language if
+// there's one.
+func localizedDescription(desc string, locales discord.StringLocales, lang discord.Language) string {
+	if localized, ok := locales[lang]; ok && localized != "" {
+		return localized
+	}
+	return desc
+}
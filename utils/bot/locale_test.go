@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/state/store/defaultstore"
+)
+
+func TestCatalogRender(t *testing.T) {
+	c := NewCatalog()
+	c.MustSet(discord.French, MessageHelpCommands, "__Commandes__")
+
+	tests := []struct {
+		lang   discord.Language
+		id     string
+		expect string
+	}{
+		{discord.French, MessageHelpCommands, "__Commandes__"},
+		{discord.German, MessageHelpCommands, "__Commands__"},
+		{"", MessageHelpSubcommands, "__Subcommands__"},
+		{discord.French, "nonexistent", ""},
+	}
+
+	for _, test := range tests {
+		if got := c.Render(test.lang, test.id, nil); got != test.expect {
+			t.Errorf("%s %s: expected %q, got %q", test.lang, test.id, test.expect, got)
+		}
+	}
+
+	// Broken templates fall back to the defaults.
+	c.MustSet(discord.French, MessageUnknownCommand, "{{.Nonexistent}}")
+	err := &UnknownCommandError{Parts: []string{"ping"}, Subcmd: &Subcommand{}}
+	if got := c.Render(discord.French, MessageUnknownCommand, err); got != err.Error() {
+		t.Errorf("expected fallback %q, got %q", err.Error(), got)
+	}
+
+	if err := c.Set(discord.French, MessageHelpHeader, "{{"); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	ctx := &Context{Catalog: NewCatalog()}
+
+	errs := []error{
+		&UnknownCommandError{Parts: []string{"ping"}, Subcmd: &Subcommand{}},
+		&UnknownCommandError{Parts: []string{"sub", "ping"}, Subcmd: &Subcommand{StructName: "Sub"}},
+		&InvalidUsageError{Prefix: "~", Args: []string{"add", "1", "a"}, Index: 2, Wrap: ErrTooManyArgs},
+		&InvalidUsageError{Args: []string{"add"}, Index: 0, Wrap: ErrNotEnoughArgs},
+		&InvalidUsageError{Args: []string{"add"}},
+	}
+
+	// The default messages must match the non-localized strings.
+	for _, err := range errs {
+		localized := ctx.LocalizeError(discord.EnglishUS, err)
+		if localized.Error() != err.Error() {
+			t.Errorf("expected %q, got %q", err.Error(), localized.Error())
+		}
+		if !errors.Is(localized, err) {
+			t.Errorf("localized error doesn't wrap %v", err)
+		}
+	}
+
+	ctx.Catalog.MustSet(discord.French, MessageUnknownCommand, "commande inconnue : {{index .Parts 0}}.")
+
+	localized := ctx.LocalizeError(discord.French, errs[0])
+	if localized.Error() != "commande inconnue : ping." {
+		t.Errorf("unexpected French error %q", localized.Error())
+	}
+
+	other := errors.New("other")
+	if ctx.LocalizeError(discord.French, other) != other {
+		t.Error("unrelated error was changed")
+	}
+}
+
+func TestLocalizeErrorOverride(t *testing.T) {
+	unknownCommandString := UnknownCommandString
+	t.Cleanup(func() { UnknownCommandString = unknownCommandString })
+
+	UnknownCommandString = func(err *UnknownCommandError) string {
+		return "what is " + err.Parts[0] + "?"
+	}
+
+	ctx := &Context{Catalog: NewCatalog()}
+	err := &UnknownCommandError{Parts: []string{"ping"}, Subcmd: &Subcommand{}}
+
+	// Languages without their own messages use the overridden string.
+	for _, lang := range []discord.Language{"", discord.EnglishUS, discord.German} {
+		if msg := ctx.LocalizeError(lang, err).Error(); msg != "what is ping?" {
+			t.Errorf("%q: expected the overridden message, got %q", lang, msg)
+		}
+	}
+
+	ctx.Catalog.MustSet(discord.French, MessageUnknownCommand, "commande inconnue : {{index .Parts 0}}.")
+
+	if msg := ctx.LocalizeError(discord.French, err).Error(); msg != "commande inconnue : ping." {
+		t.Errorf("unexpected French error %q", msg)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	cabinet := defaultstore.New()
+	cabinet.GuildSet(&discord.Guild{ID: 1, PreferredLocale: string(discord.German)}, false)
+
+	ctx := &Context{State: &state.State{Cabinet: cabinet}}
+
+	tests := []struct {
+		name   string
+		ev     interface{}
+		expect discord.Language
+	}{
+		{
+			name:   "guild message",
+			ev:     &gateway.MessageCreateEvent{Message: discord.Message{GuildID: 1}},
+			expect: discord.German,
+		},
+		{
+			name:   "direct message",
+			ev:     &gateway.MessageCreateEvent{},
+			expect: "",
+		},
+		{
+			name: "interaction",
+			ev: &gateway.InteractionCreateEvent{InteractionEvent: discord.InteractionEvent{
+				Locale:      discord.French,
+				GuildLocale: string(discord.German),
+			}},
+			expect: discord.French,
+		},
+		{
+			name: "interaction guild locale",
+			ev: &gateway.InteractionCreateEvent{InteractionEvent: discord.InteractionEvent{
+				GuildLocale: string(discord.German),
+			}},
+			expect: discord.German,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if lang := ctx.Locale(test.ev); lang != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, lang)
+			}
+		})
+	}
+}
+
+func TestHelpLocale(t *testing.T) {
+	ctx, err := New(&state.State{Cabinet: defaultstore.New()}, &slashc{})
+	if err != nil {
+		t.Fatal("failed to create context:", err)
+	}
+	ctx.Name = "Bot"
+
+	ctx.FindCommand("", "AddNumbers").DescriptionLocalizations = discord.StringLocales{
+		discord.French: "Additionne deux nombres.",
+	}
+	ctx.Catalog.MustSet(discord.French, MessageHelpCommands, "__Commandes__")
+
+	if help := ctx.Help(); !strings.Contains(help, "__Help__: Bot") ||
+		!strings.Contains(help, "__Commands__") ||
+		!strings.Contains(help, "Adds two numbers.") {
+
+		t.Fatalf("unexpected English help:\n%s", help)
+	}
+
+	if help := ctx.HelpLocale(discord.French, false); !strings.Contains(help, "__Commandes__") ||
+		!strings.Contains(help, "Additionne deux nombres.") {
+
+		t.Fatalf("unexpected French help:\n%s", help)
+	}
+}
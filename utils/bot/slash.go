@@ -0,0 +1,450 @@
+package bot
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+)
+
+var typeInteractionCreate = reflect.TypeOf((*gateway.InteractionCreateEvent)(nil))
+
+// maxDescriptionLength is the maximum length of a command or option
+// description.
+const maxDescriptionLength = 100
+
+// SlashName returns the application command name of the given command or
+// subcommand name. Application command names must be lower case, so
+// "getCounter" becomes "get-counter".
+func SlashName(command string) string {
+	var buf strings.Builder
+	buf.Grow(len(command) + 2)
+
+	for i, r := range command {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				buf.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+
+		if r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) {
+			buf.WriteRune(r)
+		}
+	}
+
+	return buf.String()
+}
+
+// SlashCommandData derives an application command from the context's commands
+// and subcommands. The command is named after ctx.SlashCommand, which must not
+// be empty.
+//
+// Commands of the context become subcommands of the application command, and
+// each Subcommand becomes a subcommand group containing its commands. Hidden
+// commands and subcommands are skipped. Method arguments are mapped to typed
+// options: strings to StringOption, integers to IntegerOption, floats to
+// NumberOption and bools to BooleanOption. Arguments that implement Parser,
+// ManualParser or CustomParser, as well as variadic arguments, are taken as a
+// single string option.
+func (ctx *Context) SlashCommandData() (api.CreateCommandData, error) {
+	if ctx.SlashCommand == "" {
+		return api.CreateCommandData{}, errors.New("SlashCommand is empty")
+	}
+
+	description := ctx.Description
+	if description == "" {
+		description = ctx.Name
+	}
+
+	data := api.CreateCommandData{
+		Name:        ctx.SlashCommand,
+		Description: slashDescription(description, ctx.SlashCommand),
+		Type:        discord.ChatInputCommand,
+	}
+
+	for _, cmd := range ctx.Commands {
+		if cmd.Hidden {
+			continue
+		}
+
+		data.Options = append(data.Options, slashSubcommand(cmd))
+	}
+
+	for _, sub := range ctx.subcommands {
+		if sub.Hidden {
+			continue
+		}
+
+		group := &discord.SubcommandGroupOption{
+			OptionName:               SlashName(sub.Command),
+			Description:              slashDescription(sub.Description, sub.Command),
+			DescriptionLocalizations: slashDescriptionLocales(sub.DescriptionLocalizations),
+		}
+
+		for _, cmd := range sub.Commands {
+			if !cmd.Hidden {
+				group.Subcommands = append(group.Subcommands, slashSubcommand(cmd))
+			}
+		}
+
+		// Discord doesn't allow empty groups.
+		if len(group.Subcommands) > 0 {
+			data.Options = append(data.Options, group)
+		}
+	}
+
+	return data, nil
+}
+
+// RegisterSlashCommand creates or overwrites the application command derived
+// by SlashCommandData. If guildID is valid, then the command is only created
+// in that guild, which is useful for testing.
+func (ctx *Context) RegisterSlashCommand(
+	appID discord.AppID, guildID discord.GuildID) (*discord.Command, error) {
+
+	data, err := ctx.SlashCommandData()
+	if err != nil {
+		return nil, err
+	}
+
+	if guildID.IsValid() {
+		return ctx.CreateGuildCommand(appID, guildID, data)
+	}
+
+	return ctx.CreateCommand(appID, data)
+}
+
+func slashDescription(description, fallback string) string {
+	if description == "" {
+		description = fallback
+	}
+
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		description = string(runes[:maxDescriptionLength-1]) + "…"
+	}
+
+	return description
+}
+
+func slashDescriptionLocales(locales discord.StringLocales) discord.StringLocales {
+	if len(locales) == 0 {
+		return nil
+	}
+
+	trimmed := make(discord.StringLocales, len(locales))
+	for lang, description := range locales {
+		trimmed[lang] = slashDescription(description, "")
+	}
+
+	return trimmed
+}
+
+func slashSubcommand(cmd *MethodContext) *discord.SubcommandOption {
+	opt := &discord.SubcommandOption{
+		OptionName:               SlashName(cmd.Command),
+		Description:              slashDescription(cmd.Description, cmd.Command),
+		DescriptionLocalizations: slashDescriptionLocales(cmd.DescriptionLocalizations),
+	}
+
+	names := slashArgumentNames(cmd)
+
+	for i, arg := range cmd.Arguments {
+		name := names[i]
+		description := slashDescription(arg.String, name)
+		required := !isRestArgument(cmd, i)
+
+		var value discord.CommandOptionValue
+
+		switch kind := arg.rtype.Kind(); {
+		case !isPrimitiveArgument(cmd, i):
+			value = &discord.StringOption{OptionName: name, Description: description, Required: required}
+		case kind == reflect.Bool:
+			value = &discord.BooleanOption{OptionName: name, Description: description, Required: required}
+		case kind == reflect.Float32 || kind == reflect.Float64:
+			value = &discord.NumberOption{OptionName: name, Description: description, Required: required}
+		case kind >= reflect.Int && kind <= reflect.Uint64:
+			value = &discord.IntegerOption{OptionName: name, Description: description, Required: required}
+		default:
+			value = &discord.StringOption{OptionName: name, Description: description, Required: required}
+		}
+
+		opt.Options = append(opt.Options, value)
+	}
+
+	return opt
+}
+
+// slashArgumentNames returns the option names of the command's arguments.
+// Names are derived from the argument usages and numbered if they collide.
+func slashArgumentNames(cmd *MethodContext) []string {
+	names := make([]string, len(cmd.Arguments))
+	count := make(map[string]int, len(cmd.Arguments))
+
+	for i, arg := range cmd.Arguments {
+		name := strings.ToLower(SlashName(arg.String))
+		if name == "" {
+			name = "argument"
+		}
+
+		count[name]++
+		if n := count[name]; n > 1 {
+			name += strconv.Itoa(n)
+		}
+
+		names[i] = name
+	}
+
+	return names
+}
+
+// isRestArgument returns true if the i-th argument consumes all remaining
+// arguments.
+func isRestArgument(cmd *MethodContext, i int) bool {
+	if i != len(cmd.Arguments)-1 {
+		return false
+	}
+
+	arg := cmd.Arguments[i]
+	return cmd.Variadic || arg.manual != nil || arg.custom != nil
+}
+
+// isPrimitiveArgument returns true if the i-th argument is a single primitive
+// value that can be mapped onto a typed option.
+func isPrimitiveArgument(cmd *MethodContext, i int) bool {
+	arg := cmd.Arguments[i]
+	return arg.fn != nil && !isRestArgument(cmd, i) && !arg.rtype.Implements(typeIParser)
+}
+
+// findSlashCommand finds the command method of the given command interaction
+// and its subcommand. It returns the options passed to the method.
+func (ctx *Context) findSlashCommand(
+	data *discord.CommandInteraction) (*MethodContext, *Subcommand, discord.CommandInteractionOptions) {
+
+	if len(data.Options) != 1 {
+		return nil, nil, nil
+	}
+
+	opt := data.Options[0]
+
+	switch opt.Type {
+	case discord.SubcommandOptionType:
+		for _, cmd := range ctx.Commands {
+			if !cmd.Hidden && SlashName(cmd.Command) == opt.Name {
+				return cmd, ctx.Subcommand, opt.Options
+			}
+		}
+
+	case discord.SubcommandGroupOptionType:
+		if len(opt.Options) != 1 {
+			return nil, nil, nil
+		}
+
+		for _, sub := range ctx.subcommands {
+			if sub.Hidden || SlashName(sub.Command) != opt.Name {
+				continue
+			}
+
+			for _, cmd := range sub.Commands {
+				if !cmd.Hidden && SlashName(cmd.Command) == opt.Options[0].Name {
+					return cmd, sub, opt.Options[0].Options
+				}
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// callInteraction calls the command method of the given interaction, going
+// through the same global and command middlewares as a MessageCreate event
+// would, and responds to the interaction with the method's return value.
+func (ctx *Context) callInteraction(ev *gateway.InteractionCreateEvent) error {
+	data, ok := ev.Data.(*discord.CommandInteraction)
+	if !ok || data.Name != ctx.SlashCommand {
+		return nil
+	}
+
+	cmd, sub, options := ctx.findSlashCommand(data)
+	if cmd == nil {
+		return nil
+	}
+
+	mc := interactionMessage(ev)
+	value := reflect.ValueOf(mc)
+
+	v, err := ctx.callSlashCommand(cmd, sub, options, value)
+	if err == nil && v == nil {
+		return ctx.acknowledgeInteraction(ev)
+	}
+
+	if err != nil && !ctx.ReplyError && ctx.ErrorReplier == nil {
+		return err
+	}
+
+	var send api.SendMessageData
+
+	if err != nil {
+		if ctx.ErrorReplier != nil {
+			send = ctx.ErrorReplier(err, mc)
+		} else {
+			send.Content = ctx.FormatError(ctx.LocalizeError(ctx.Locale(ev), err))
+		}
+	} else {
+		switch v := v.(type) {
+		case string:
+			send.Content = v
+		case *discord.Embed:
+			send.Embeds = []discord.Embed{*v}
+		case *api.SendMessageData:
+			send = *v
+		default:
+			return ctx.acknowledgeInteraction(ev)
+		}
+	}
+
+	if send.AllowedMentions == nil {
+		send.AllowedMentions = &api.AllowedMentions{
+			Users: []discord.UserID{mc.Author.ID},
+		}
+	}
+
+	resp := api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			TTS:             send.TTS,
+			AllowedMentions: send.AllowedMentions,
+			Files:           send.Files,
+		},
+	}
+
+	if send.Content != "" {
+		resp.Data.Content = option.NewNullableString(send.Content)
+	}
+	if len(send.Embeds) > 0 {
+		resp.Data.Embeds = &send.Embeds
+	}
+	if len(send.Components) > 0 {
+		resp.Data.Components = &send.Components
+	}
+
+	return ctx.RespondInteraction(ev.ID, ev.Token, resp)
+}
+
+// acknowledgeInteraction responds to the interaction without a message, which
+// is done if the command method has nothing to reply with. Otherwise, Discord
+// would show that the interaction failed.
+func (ctx *Context) acknowledgeInteraction(ev *gateway.InteractionCreateEvent) error {
+	resp := api.InteractionResponse{
+		Type: api.DeferredMessageInteractionWithSource,
+		Data: &api.InteractionResponseData{Flags: discord.EphemeralMessage},
+	}
+
+	if err := ctx.RespondInteraction(ev.ID, ev.Token, resp); err != nil {
+		return err
+	}
+
+	// Remove the "thinking" message of the deferred response.
+	return ctx.DeleteInteractionResponse(ev.AppID, ev.Token)
+}
+
+func (ctx *Context) callSlashCommand(
+	cmd *MethodContext, sub *Subcommand,
+	options discord.CommandInteractionOptions, value reflect.Value) (interface{}, error) {
+
+	// Only the middlewares are ran; the MessageCreate event handlers aren't
+	// called for interactions.
+	if err := ctx.Subcommand.walkMiddlewares(typeMessageCreate, value); err != nil {
+		return nil, errNoBreak(err)
+	}
+
+	if sub != ctx.Subcommand {
+		if err := sub.walkMiddlewares(typeMessageCreate, value); err != nil {
+			return nil, errNoBreak(err)
+		}
+	}
+
+	if err := cmd.walkMiddlewares(value); err != nil {
+		return nil, errNoBreak(err)
+	}
+
+	names := slashArgumentNames(cmd)
+	argv := make([]reflect.Value, 0, len(cmd.Arguments))
+
+	for i, arg := range cmd.Arguments {
+		input := options.Find(names[i]).String()
+
+		switch {
+		case arg.manual != nil || arg.custom != nil:
+			v := reflect.New(arg.rtype)
+			var err error
+
+			if arg.manual != nil {
+				var parts []string
+				if parts, err = ctx.ParseArgs(input); err == nil {
+					err = arg.manual(v.Interface().(ManualParser), parts)
+				}
+			} else {
+				err = arg.custom(v.Interface().(CustomParser), input)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			if arg.pointer {
+				v = v.Elem()
+			}
+
+			argv = append(argv, v)
+
+		case isRestArgument(cmd, i):
+			parts, err := ctx.ParseArgs(input)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, part := range parts {
+				v, err := arg.fn(part)
+				if err != nil {
+					return nil, err
+				}
+				argv = append(argv, v)
+			}
+
+		default:
+			v, err := arg.fn(input)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid %s", names[i])
+			}
+			argv = append(argv, v)
+		}
+	}
+
+	return cmd.call(value, argv...)
+}
+
+// interactionMessage creates a MessageCreate event from the given interaction,
+// so that command methods and their middlewares can be reused.
+func interactionMessage(ev *gateway.InteractionCreateEvent) *gateway.MessageCreateEvent {
+	mc := &gateway.MessageCreateEvent{
+		Message: discord.Message{
+			ChannelID: ev.ChannelID,
+			GuildID:   ev.GuildID,
+		},
+		Member: ev.Member,
+	}
+
+	if sender := ev.Sender(); sender != nil {
+		mc.Author = *sender
+	}
+
+	return mc
+}
@@ -0,0 +1,319 @@
+package bot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+)
+
+type slashc struct {
+	Ctx *Context
+
+	messageEvents int
+}
+
+// OnEvent counts the MessageCreate events that reach event handlers.
+func (s *slashc) OnEvent(ev interface{}) {
+	if _, ok := ev.(*gateway.MessageCreateEvent); ok {
+		s.messageEvents++
+	}
+}
+
+func (s *slashc) Setup(sub *Subcommand) {
+	sub.ChangeCommandInfo(s.AddNumbers, "", "Adds two numbers.")
+	sub.Hide(s.Secret)
+}
+
+func (s *slashc) AddNumbers(_ *gateway.MessageCreateEvent, a, b int) (string, error) {
+	return strconv.Itoa(a + b), nil
+}
+
+func (s *slashc) Echo(_ *gateway.MessageCreateEvent, words ...string) (*discord.Embed, error) {
+	return &discord.Embed{Description: ArgumentParts(words).String()}, nil
+}
+
+func (s *slashc) Secret(*gateway.MessageCreateEvent) {}
+
+type slashSub struct {
+	Ctx *Context
+}
+
+func (s *slashSub) Ping(*gateway.MessageCreateEvent) error {
+	return nil
+}
+
+func (s *slashSub) Toggle(ev *gateway.MessageCreateEvent, on bool) (string, error) {
+	if on {
+		return "on for " + ev.Author.Username, nil
+	}
+	return "off", nil
+}
+
+// newSlashContext creates a context whose interaction responses are sent into
+// responses and whose deleted interaction responses are sent into deletes.
+func newSlashContext(t *testing.T) (ctx *Context, responses chan api.InteractionResponse, deletes chan string) {
+	responses = make(chan api.InteractionResponse, 1)
+	deletes = make(chan string, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes <- r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+
+		var resp api.InteractionResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			t.Error("failed to decode response:", err)
+		}
+		responses <- resp
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint := api.EndpointInteractions
+	api.EndpointInteractions = srv.URL + "/"
+	t.Cleanup(func() { api.EndpointInteractions = endpoint })
+
+	webhooks := api.EndpointWebhooks
+	api.EndpointWebhooks = srv.URL + "/webhooks/"
+	t.Cleanup(func() { api.EndpointWebhooks = webhooks })
+
+	ctx, err := New(state.NewAPIOnlyState("", handler.New()), &slashc{})
+	if err != nil {
+		t.Fatal("failed to create context:", err)
+	}
+	ctx.Description = "Test bot."
+	ctx.SlashCommand = "bot"
+
+	if _, err := ctx.RegisterSubcommand(&slashSub{}, "settings"); err != nil {
+		t.Fatal("failed to register subcommand:", err)
+	}
+
+	return ctx, responses, deletes
+}
+
+func TestSlashCommandData(t *testing.T) {
+	ctx, _, _ := newSlashContext(t)
+
+	data, err := ctx.SlashCommandData()
+	if err != nil {
+		t.Fatal("failed to derive command:", err)
+	}
+
+	expect := api.CreateCommandData{
+		Name:        "bot",
+		Description: "Test bot.",
+		Type:        discord.ChatInputCommand,
+		Options: discord.CommandOptions{
+			&discord.SubcommandOption{
+				OptionName:  "add-numbers",
+				Description: "Adds two numbers.",
+				Options: []discord.CommandOptionValue{
+					&discord.IntegerOption{OptionName: "int", Description: "int", Required: true},
+					&discord.IntegerOption{OptionName: "int2", Description: "int", Required: true},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName:  "echo",
+				Description: "echo",
+				Options: []discord.CommandOptionValue{
+					&discord.StringOption{OptionName: "string", Description: "string"},
+				},
+			},
+			&discord.SubcommandGroupOption{
+				OptionName:  "settings",
+				Description: "settings",
+				Subcommands: []*discord.SubcommandOption{{
+					OptionName:  "ping",
+					Description: "ping",
+				}, {
+					OptionName:  "toggle",
+					Description: "toggle",
+					Options: []discord.CommandOptionValue{
+						&discord.BooleanOption{OptionName: "bool", Description: "bool", Required: true},
+					},
+				}},
+			},
+		},
+	}
+
+	expectJSON, _ := json.Marshal(expect)
+	gotJSON, _ := json.Marshal(data)
+
+	if string(expectJSON) != string(gotJSON) {
+		t.Fatalf("unexpected command:\nexpected %s\ngot      %s", expectJSON, gotJSON)
+	}
+}
+
+func TestSlashCommandCall(t *testing.T) {
+	ctx, responses, _ := newSlashContext(t)
+
+	var middlewareCalled bool
+	ctx.AddMiddleware("*", func(ev *gateway.MessageCreateEvent) error {
+		middlewareCalled = true
+		if ev.Author.ID != 1 {
+			return Break
+		}
+		return nil
+	})
+
+	call := func(t *testing.T, opt discord.CommandInteractionOption) api.InteractionResponse {
+		t.Helper()
+
+		err := ctx.Call(&gateway.InteractionCreateEvent{
+			InteractionEvent: discord.InteractionEvent{
+				ID:        1,
+				Token:     "token",
+				ChannelID: 2,
+				User:      &discord.User{ID: 1, Username: "astolfo"},
+				Data: &discord.CommandInteraction{
+					Name:    "bot",
+					Options: discord.CommandInteractionOptions{opt},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal("failed to call:", err)
+		}
+
+		select {
+		case resp := <-responses:
+			return resp
+		default:
+			t.Fatal("no interaction response")
+			return api.InteractionResponse{}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		option discord.CommandInteractionOption
+		expect string
+	}{
+		{
+			name: "integers",
+			option: discord.CommandInteractionOption{
+				Type: discord.SubcommandOptionType,
+				Name: "add-numbers",
+				Options: discord.CommandInteractionOptions{
+					{Type: discord.IntegerOptionType, Name: "int", Value: []byte("1")},
+					{Type: discord.IntegerOptionType, Name: "int2", Value: []byte("2")},
+				},
+			},
+			expect: "3",
+		},
+		{
+			name: "variadic",
+			option: discord.CommandInteractionOption{
+				Type: discord.SubcommandOptionType,
+				Name: "echo",
+				Options: discord.CommandInteractionOptions{
+					{Type: discord.StringOptionType, Name: "string", Value: []byte(`"hello world"`)},
+				},
+			},
+			expect: "hello world",
+		},
+		{
+			name: "group",
+			option: discord.CommandInteractionOption{
+				Type: discord.SubcommandGroupOptionType,
+				Name: "settings",
+				Options: discord.CommandInteractionOptions{{
+					Type: discord.SubcommandOptionType,
+					Name: "toggle",
+					Options: discord.CommandInteractionOptions{
+						{Type: discord.BooleanOptionType, Name: "bool", Value: []byte("true")},
+					},
+				}},
+			},
+			expect: "on for astolfo",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			middlewareCalled = false
+
+			resp := call(t, test.option)
+			if resp.Type != api.MessageInteractionWithSource {
+				t.Fatalf("unexpected response type %d", resp.Type)
+			}
+
+			var got string
+			if resp.Data.Embeds != nil {
+				got = (*resp.Data.Embeds)[0].Description
+			} else {
+				got = resp.Data.Content.Val
+			}
+
+			if got != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, got)
+			}
+
+			if !middlewareCalled {
+				t.Fatal("middleware was not called")
+			}
+		})
+	}
+
+	if n := ctx.command.(*slashc).messageEvents; n != 0 {
+		t.Fatalf("event handlers got %d MessageCreate events", n)
+	}
+}
+
+func TestSlashCommandAcknowledge(t *testing.T) {
+	ctx, responses, deletes := newSlashContext(t)
+
+	err := ctx.Call(&gateway.InteractionCreateEvent{
+		InteractionEvent: discord.InteractionEvent{
+			ID:    1,
+			AppID: 2,
+			Token: "token",
+			User:  &discord.User{ID: 1},
+			Data: &discord.CommandInteraction{
+				Name: "bot",
+				Options: discord.CommandInteractionOptions{{
+					Type: discord.SubcommandGroupOptionType,
+					Name: "settings",
+					Options: discord.CommandInteractionOptions{
+						{Type: discord.SubcommandOptionType, Name: "ping"},
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal("failed to call:", err)
+	}
+
+	select {
+	case resp := <-responses:
+		if resp.Type != api.DeferredMessageInteractionWithSource {
+			t.Fatalf("unexpected response type %d", resp.Type)
+		}
+	default:
+		t.Fatal("interaction was not acknowledged")
+	}
+
+	select {
+	case path := <-deletes:
+		if path != "/webhooks/2/token/messages/@original" {
+			t.Fatalf("unexpected deleted response %q", path)
+		}
+	default:
+		t.Fatal("deferred response was not deleted")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 )
 
@@ -63,6 +64,9 @@ type Subcommand struct {
 	// Description is a string that's appended after the subcommand name in
 	// (*Context).Help().
 	Description string
+	// DescriptionLocalizations contains translations of Description. It is
+	// used by (*Context).HelpLocale and for slash commands.
+	DescriptionLocalizations discord.StringLocales
 
 	// Hidden if true will not be shown by (*Context).Help(). It will
 	// also cause unknown command errors to be suppressed.
@@ -241,6 +245,18 @@ func (sub *Subcommand) HelpShowHidden(showHidden bool) string {
 // to override the Subcommand's help, else use Help(). This function will show
 // hidden commands if showHidden is true.
 func (sub *Subcommand) HelpGenerate(showHidden bool) string {
+	return sub.helpGenerate("", showHidden)
+}
+
+// helpLocale is HelpShowHidden with the descriptions in the given language.
+func (sub *Subcommand) helpLocale(lang discord.Language, showHidden bool) string {
+	if sub.helper != nil {
+		return sub.helper()
+	}
+	return sub.helpGenerate(lang, showHidden)
+}
+
+func (sub *Subcommand) helpGenerate(lang discord.Language, showHidden bool) string {
 	var buf strings.Builder
 
 	for i, cmd := range sub.Commands {
@@ -284,9 +300,9 @@ func (sub *Subcommand) HelpGenerate(showHidden bool) string {
 		}
 
 		// Write the description if there's any.
-		if cmd.Description != "" {
+		if desc := localizedDescription(cmd.Description, cmd.DescriptionLocalizations, lang); desc != "" {
 			buf.WriteString(": ")
-			buf.WriteString(cmd.Description)
+			buf.WriteString(desc)
 		}
 
 		// Add a new line if this isn't the last command.
@@ -446,6 +462,20 @@ func (sub *Subcommand) addMiddleware(mw *MiddlewareContext, methods []string) {
 	}
 }
 
+// walkMiddlewares calls the global middlewares of the given event type until
+// one of them returns an error.
+func (sub *Subcommand) walkMiddlewares(evT reflect.Type, ev reflect.Value) error {
+	for _, mw := range sub.globalmws {
+		if !mw.isEvent(evT) {
+			continue
+		}
+		if _, err := mw.call(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sub *Subcommand) eventCallers(evT reflect.Type) (callers []caller) {
 	// Search for global middlewares.
 	for _, mw := range sub.globalmws {
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	_ "embed"
+)
+
+type data struct {
+	Package string
+	Codes   []errorCode
+}
+
+type errorCode struct {
+	Code        int
+	Name        string
+	Description string
+}
+
+//go:embed template.tmpl
+var packageTmpl string
+
+var tmpl = template.Must(template.New("").Parse(packageTmpl))
+
+func main() {
+	var pkg string
+	var in string
+	var out string
+
+	log.SetFlags(0)
+
+	flag.Usage = func() {
+		log.Printf("usage: %s [-p package] [-o output] -i input.tsv", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.StringVar(&in, "i", "", "input tab-separated file of code, name and description")
+	flag.StringVar(&out, "o", "", "output, empty for stdout")
+	flag.StringVar(&pkg, "p", "api", "package name")
+	flag.Parse()
+
+	if in == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	codes, err := readCodes(in)
+	if err != nil {
+		log.Fatalln("failed to read codes:", err)
+	}
+
+	d := data{
+		Package: pkg,
+		Codes:   codes,
+	}
+
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, d); err != nil {
+		log.Fatalln("failed to execute template:", err)
+	}
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalln("failed to fmt:", err)
+	}
+
+	outFile := os.Stdout
+
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			log.Fatalln("failed to create output file:", err)
+		}
+		defer f.Close()
+
+		outFile = f
+	}
+
+	if _, err := outFile.Write(b); err != nil {
+		log.Fatalln("failed to write to file:", err)
+	}
+}
+
+func readCodes(path string) ([]errorCode, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var codes []errorCode
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected 3 fields, got %d", path, line, len(fields))
+		}
+
+		code, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid code: %v", path, line, err)
+		}
+
+		codes = append(codes, errorCode{
+			Code:        code,
+			Name:        fields[1],
+			Description: fields[2],
+		})
+	}
+
+	return codes, scanner.Err()
+}
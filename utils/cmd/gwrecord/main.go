@@ -0,0 +1,235 @@
+// Command gwrecord inspects and filters gateway recordings made by package
+// utils/ws/record.
+//
+// By default, every entry is printed on its own line. The -d flag also prints
+// the data of each entry, and -summary prints the number of entries of each
+// type instead. If -o is given, then the matching entries are written into a
+// new recording.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+	"github.com/diamondburned/arikawa/v3/utils/ws/record"
+)
+
+type filter struct {
+	types map[ws.EventType]bool
+	ops   map[ws.OpCode]bool
+	from  time.Duration
+	to    time.Duration
+}
+
+func (f filter) match(e record.Entry) bool {
+	if e.At < f.from || (f.to > 0 && e.At > f.to) {
+		return false
+	}
+	if len(f.ops) > 0 && !f.ops[e.Code] {
+		return false
+	}
+	if len(f.types) > 0 && !f.types[e.Type] {
+		return false
+	}
+	return true
+}
+
+func main() {
+	var (
+		types   string
+		ops     string
+		out     string
+		data    bool
+		summary bool
+		f       filter
+	)
+
+	log.SetFlags(0)
+
+	flag.Usage = func() {
+		log.Printf("usage: %s [flags] <recording>", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.StringVar(&types, "t", "", "comma-separated event types to keep, e.g. MESSAGE_CREATE")
+	flag.StringVar(&ops, "op", "", "comma-separated op codes to keep")
+	flag.DurationVar(&f.from, "from", 0, "skip entries recorded before this time")
+	flag.DurationVar(&f.to, "to", 0, "skip entries recorded after this time, 0 for no limit")
+	flag.StringVar(&out, "o", "", "write the matching entries into a new recording")
+	flag.BoolVar(&data, "d", false, "print the data of each entry")
+	flag.BoolVar(&summary, "summary", false, "print the number of entries of each type")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if types != "" {
+		f.types = make(map[ws.EventType]bool)
+		for _, t := range strings.Split(types, ",") {
+			f.types[ws.EventType(strings.TrimSpace(t))] = true
+		}
+	}
+
+	if ops != "" {
+		f.ops = make(map[ws.OpCode]bool)
+		for _, op := range strings.Split(ops, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(op))
+			if err != nil {
+				log.Fatalf("invalid op code %q", op)
+			}
+			f.ops[ws.OpCode(code)] = true
+		}
+	}
+
+	in, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalln("failed to open recording:", err)
+	}
+	defer in.Close()
+
+	r, err := record.NewReader(in)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	switch {
+	case out != "":
+		err = writeRecording(r, f, out)
+	case summary:
+		err = printSummary(r, f)
+	default:
+		err = printEntries(r, f, data)
+	}
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// each calls fn for every entry in r that matches f.
+func each(r *record.Reader, f filter, fn func(record.Entry) error) error {
+	for {
+		e, err := r.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if !f.match(e) {
+			continue
+		}
+
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+func printEntries(r *record.Reader, f filter, data bool) error {
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "# started", r.Header().Start.Format(time.RFC3339))
+
+	return each(r, f, func(e record.Entry) error {
+		fmt.Fprintf(w, "%-12s op=%-2d s=%-6d %-28s %6dB", e.At, e.Code, e.Sequence, e.Type, len(e.Data))
+		if data {
+			fmt.Fprintf(w, " %s", e.Data)
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	})
+}
+
+type summaryKey struct {
+	code ws.OpCode
+	typ  ws.EventType
+}
+
+func printSummary(r *record.Reader, f filter) error {
+	counts := make(map[summaryKey]int)
+	var total, size int
+	var last time.Duration
+
+	err := each(r, f, func(e record.Entry) error {
+		counts[summaryKey{e.Code, e.Type}]++
+		total++
+		size += len(e.Data)
+		last = e.At
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]summaryKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		if keys[i].code != keys[j].code {
+			return keys[i].code < keys[j].code
+		}
+		return keys[i].typ < keys[j].typ
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "started: ", r.Header().Start.Format(time.RFC3339))
+	fmt.Fprintln(w, "duration:", last)
+	fmt.Fprintln(w, "entries: ", total)
+	fmt.Fprintln(w, "data:    ", size, "bytes")
+	fmt.Fprintln(w)
+
+	for _, k := range keys {
+		typ := string(k.typ)
+		if typ == "" {
+			typ = "-"
+		}
+		fmt.Fprintf(w, "%8d  op=%-2d %s\n", counts[k], k.code, typ)
+	}
+
+	return nil
+}
+
+func writeRecording(r *record.Reader, f filter, out string) error {
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rec, err := record.NewRecorder(file)
+	if err != nil {
+		return err
+	}
+
+	if err := each(r, f, rec.WriteEntry); err != nil {
+		return err
+	}
+
+	if err := rec.Close(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,260 @@
+package components
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+)
+
+const (
+	testChannelID = discord.ChannelID(1)
+	testMessageID = discord.MessageID(10)
+	testUserID    = discord.UserID(5)
+)
+
+// fakeServer fakes the message and interaction callback endpoints.
+type fakeServer struct {
+	callbacks chan api.InteractionResponse
+	edits     chan api.EditMessageData
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/callback"):
+		var resp api.InteractionResponse
+		json.Unmarshal(body, &resp)
+		s.callbacks <- resp
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == "POST":
+		// Echo the sent message back.
+		var msg map[string]interface{}
+		json.Unmarshal(body, &msg)
+		msg["id"] = testMessageID.String()
+		msg["channel_id"] = testChannelID.String()
+		json.NewEncoder(w).Encode(msg)
+
+	case r.Method == "PATCH":
+		var data api.EditMessageData
+		json.Unmarshal(body, &data)
+		s.edits <- data
+		w.Write([]byte(`{"id":"10","channel_id":"1"}`))
+	}
+}
+
+func newTestManager(t *testing.T) (*fakeServer, *Manager, *handler.Handler) {
+	s := &fakeServer{
+		callbacks: make(chan api.InteractionResponse, 10),
+		edits:     make(chan api.EditMessageData, 10),
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	channels, interactions := api.EndpointChannels, api.EndpointInteractions
+	api.EndpointChannels = srv.URL + "/channels/"
+	api.EndpointInteractions = srv.URL + "/interactions/"
+	t.Cleanup(func() {
+		api.EndpointChannels, api.EndpointInteractions = channels, interactions
+	})
+
+	h := handler.New()
+	m := NewManager(state.NewAPIOnlyState("", h))
+	m.ErrorLog = func(err error) { t.Error("unexpected error:", err) }
+
+	return s, m, h
+}
+
+func click(h *handler.Handler, userID discord.UserID, data discord.ComponentInteraction) {
+	h.Call(&gateway.InteractionCreateEvent{
+		InteractionEvent: discord.InteractionEvent{
+			ID:      discord.InteractionID(discord.NewSnowflake(time.Now())),
+			Token:   "token",
+			Data:    data,
+			Message: &discord.Message{ID: testMessageID, ChannelID: testChannelID},
+			User:    &discord.User{ID: userID},
+		},
+	})
+}
+
+func waitFor(t *testing.T, ch <-chan api.InteractionResponse) api.InteractionResponse {
+	t.Helper()
+
+	select {
+	case resp := <-ch:
+		return resp
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for interaction response")
+		return api.InteractionResponse{}
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	s, m, h := newTestManager(t)
+
+	p := NewPaginator(
+		discord.Embed{Title: "One"},
+		discord.Embed{Title: "Two"},
+		discord.Embed{Title: "Three"},
+	)
+	p.UserID = testUserID
+
+	if _, err := p.Send(m, testChannelID); err != nil {
+		t.Fatal("failed to send paginator:", err)
+	}
+
+	click(h, testUserID, &discord.ButtonInteraction{CustomID: PaginatorNextID})
+	resp := waitFor(t, s.callbacks)
+	if resp.Type != api.UpdateMessage || (*resp.Data.Embeds)[0].Title != "Two" {
+		t.Fatalf("unexpected response after next %#v", resp)
+	}
+
+	click(h, testUserID, &discord.SelectInteraction{CustomID: PaginatorSelectID, Values: []string{"2"}})
+	resp = waitFor(t, s.callbacks)
+	if resp.Type != api.UpdateMessage || (*resp.Data.Embeds)[0].Title != "Three" {
+		t.Fatalf("unexpected response after select %#v", resp)
+	}
+
+	// Next is disabled on the last page.
+	next := (*(*resp.Data.Components)[0].(*discord.ActionRowComponent))[1].(*discord.ButtonComponent)
+	if !next.Disabled {
+		t.Fatal("next button is not disabled on the last page")
+	}
+
+	click(h, testUserID+1, &discord.ButtonInteraction{CustomID: PaginatorPrevID})
+	resp = waitFor(t, s.callbacks)
+	if resp.Type != api.DeferredMessageUpdate || p.Page() != 2 {
+		t.Fatalf("other user turned the page: %#v", resp)
+	}
+}
+
+func TestSessionOnExpireLate(t *testing.T) {
+	_, m, _ := newTestManager(t)
+
+	session := m.Attach(&discord.Message{ID: testMessageID, ChannelID: testChannelID}, time.Millisecond)
+	<-session.Done()
+
+	// Wait for expire to finish after closing Done.
+	for i := 0; ; i++ {
+		session.mut.Lock()
+		expired := session.expired
+		session.mut.Unlock()
+
+		if expired {
+			break
+		}
+		if i == 1000 {
+			t.Fatal("session did not expire")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var called bool
+	session.OnExpire(func() { called = true })
+
+	if !called {
+		t.Fatal("OnExpire set after expiring was not called")
+	}
+}
+
+func TestSessionExpire(t *testing.T) {
+	s, m, h := newTestManager(t)
+
+	expired := make(chan struct{})
+
+	session := m.Attach(&discord.Message{
+		ID:        testMessageID,
+		ChannelID: testChannelID,
+		Components: discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{Style: discord.PrimaryButtonStyle(), CustomID: "a"},
+				&discord.ButtonComponent{Style: discord.LinkButtonStyle("https://example.com")},
+			},
+		},
+	}, 50*time.Millisecond)
+	session.OnExpire(func() { close(expired) })
+	session.Handle("a", func(*Event) *api.InteractionResponse { return nil })
+
+	select {
+	case <-expired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session did not expire")
+	}
+
+	edit := <-s.edits
+	row := (*edit.Components)[0].(*discord.ActionRowComponent)
+	if !(*row)[0].(*discord.ButtonComponent).Disabled {
+		t.Fatal("button was not disabled")
+	}
+	if (*row)[1].(*discord.ButtonComponent).Disabled {
+		t.Fatal("link button was disabled")
+	}
+
+	if m.Session(testMessageID) != nil {
+		t.Fatal("expired session is still attached")
+	}
+
+	// Interactions on the expired session are ignored.
+	click(h, testUserID, &discord.ButtonInteraction{CustomID: "a"})
+	select {
+	case resp := <-s.callbacks:
+		t.Fatalf("unexpected response %#v", resp)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	t.Run("answer", func(t *testing.T) {
+		s, m, h := newTestManager(t)
+
+		type result struct {
+			yes bool
+			err error
+		}
+		done := make(chan result, 1)
+
+		go func() {
+			yes, err := Confirm{Content: "Sure?"}.Ask(context.Background(), m, testChannelID)
+			done <- result{yes, err}
+		}()
+
+		// Wait for the message to be sent.
+		for m.Session(testMessageID) == nil {
+			time.Sleep(time.Millisecond)
+		}
+
+		click(h, testUserID, &discord.ButtonInteraction{CustomID: ConfirmYesID})
+
+		resp := waitFor(t, s.callbacks)
+		row := (*resp.Data.Components)[0].(*discord.ActionRowComponent)
+		if !(*row)[0].(*discord.ButtonComponent).Disabled {
+			t.Fatal("buttons were not disabled after answering")
+		}
+
+		if r := <-done; r.err != nil || !r.yes {
+			t.Fatalf("unexpected result %v, %v", r.yes, r.err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, m, _ := newTestManager(t)
+
+		c := Confirm{Content: "Sure?", Timeout: 10 * time.Millisecond}
+		if _, err := c.Ask(context.Background(), m, testChannelID); err != ErrExpired {
+			t.Fatalf("expected ErrExpired, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Custom IDs of the Confirm's buttons.
+const (
+	ConfirmYesID discord.ComponentID = "confirm:yes"
+	ConfirmNoID  discord.ComponentID = "confirm:no"
+)
+
+// Confirm is a widget that asks the user a yes or no question using two
+// buttons. Once either button is clicked, both buttons are disabled.
+type Confirm struct {
+	// Content is the question to ask.
+	Content string
+	// Embeds are optional embeds sent along with the question.
+	Embeds []discord.Embed
+
+	// YesLabel is the label of the button that confirms.
+	YesLabel string // default: "Yes"
+	// NoLabel is the label of the button that cancels.
+	NoLabel string // default: "No"
+
+	// Timeout is how long to wait for an answer.
+	Timeout time.Duration // default: DefaultTimeout
+	// UserID, if valid, restricts the question to the given user.
+	UserID discord.UserID
+}
+
+// Ask sends the question to the given channel and blocks until the user
+// answers. ErrExpired is returned if the user doesn't answer in time. If ctx
+// expires first, then the buttons are disabled and the context's error is
+// returned.
+func (c Confirm) Ask(ctx context.Context, m *Manager, channelID discord.ChannelID) (bool, error) {
+	components := c.components()
+
+	s, err := m.Send(channelID, api.SendMessageData{
+		Content:    c.Content,
+		Embeds:     c.Embeds,
+		Components: components,
+	}, c.Timeout)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to send confirmation")
+	}
+
+	answer := make(chan bool, 1)
+
+	respond := func(yes bool) Handler {
+		return func(ev *Event) *api.InteractionResponse {
+			select {
+			case answer <- yes:
+			default:
+				return nil
+			}
+
+			ev.Session.Stop()
+
+			disabled := Disable(components)
+			return &api.InteractionResponse{
+				Type: api.UpdateMessage,
+				Data: &api.InteractionResponseData{
+					Components: &disabled,
+				},
+			}
+		}
+	}
+
+	s.SetUser(c.UserID)
+	s.Handle(ConfirmYesID, respond(true))
+	s.Handle(ConfirmNoID, respond(false))
+
+	select {
+	case yes := <-answer:
+		return yes, nil
+	case <-s.Done():
+		// The session also ends right after an answer.
+		select {
+		case yes := <-answer:
+			return yes, nil
+		default:
+			return false, ErrExpired
+		}
+	case <-ctx.Done():
+		s.Close()
+		return false, ctx.Err()
+	}
+}
+
+func (c Confirm) components() discord.ContainerComponents {
+	yes := c.YesLabel
+	if yes == "" {
+		yes = "Yes"
+	}
+
+	no := c.NoLabel
+	if no == "" {
+		no = "No"
+	}
+
+	return discord.ContainerComponents{
+		&discord.ActionRowComponent{
+			&discord.ButtonComponent{
+				Style:    discord.SuccessButtonStyle(),
+				CustomID: ConfirmYesID,
+				Label:    yes,
+			},
+			&discord.ButtonComponent{
+				Style:    discord.DangerButtonStyle(),
+				CustomID: ConfirmNoID,
+				Label:    no,
+			},
+		},
+	}
+}
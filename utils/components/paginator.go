@@ -0,0 +1,161 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// Custom IDs of the Paginator's components.
+const (
+	PaginatorPrevID   discord.ComponentID = "paginator:prev"
+	PaginatorNextID   discord.ComponentID = "paginator:next"
+	PaginatorSelectID discord.ComponentID = "paginator:select"
+)
+
+// maxSelectOptions is the maximum number of options in a select.
+const maxSelectOptions = 25
+
+// Paginator is a widget that shows a list of embeds one page at a time. The
+// user can flip through the pages with the Previous and Next buttons, or jump
+// to a page using a select if there are at most 25 pages.
+type Paginator struct {
+	// Pages contains the embeds to show, one per page.
+	Pages []discord.Embed
+	// Timeout is the inactivity timeout of the paginator.
+	Timeout time.Duration // default: DefaultTimeout
+	// UserID, if valid, restricts the paginator to the given user.
+	UserID discord.UserID
+
+	mut  sync.Mutex
+	page int
+}
+
+// NewPaginator creates a new Paginator with the given pages.
+func NewPaginator(pages ...discord.Embed) *Paginator {
+	return &Paginator{Pages: pages}
+}
+
+// Send sends the paginator to the given channel and attaches its session to
+// the message.
+func (p *Paginator) Send(m *Manager, channelID discord.ChannelID) (*Session, error) {
+	if len(p.Pages) == 0 {
+		return nil, errors.New("paginator has no pages")
+	}
+
+	p.mut.Lock()
+	page := p.page
+	p.mut.Unlock()
+
+	s, err := m.Send(channelID, api.SendMessageData{
+		Embeds:     []discord.Embed{p.Pages[page]},
+		Components: p.components(page),
+	}, p.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send paginator")
+	}
+
+	s.SetUser(p.UserID)
+	s.Handle(PaginatorPrevID, func(*Event) *api.InteractionResponse {
+		return p.turn(func(page int) int { return page - 1 })
+	})
+	s.Handle(PaginatorNextID, func(*Event) *api.InteractionResponse {
+		return p.turn(func(page int) int { return page + 1 })
+	})
+	s.Handle(PaginatorSelectID, func(ev *Event) *api.InteractionResponse {
+		sel, ok := ev.Data.(*discord.SelectInteraction)
+		if !ok || len(sel.Values) == 0 {
+			return nil
+		}
+
+		i, err := strconv.Atoi(sel.Values[0])
+		if err != nil {
+			return nil
+		}
+
+		return p.turn(func(int) int { return i })
+	})
+
+	return s, nil
+}
+
+// Page returns the index of the current page.
+func (p *Paginator) Page() int {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return p.page
+}
+
+func (p *Paginator) turn(f func(page int) int) *api.InteractionResponse {
+	p.mut.Lock()
+	page := f(p.page)
+	if page < 0 || page >= len(p.Pages) {
+		p.mut.Unlock()
+		return nil
+	}
+	p.page = page
+	p.mut.Unlock()
+
+	embeds := []discord.Embed{p.Pages[page]}
+	components := p.components(page)
+
+	return &api.InteractionResponse{
+		Type: api.UpdateMessage,
+		Data: &api.InteractionResponseData{
+			Embeds:     &embeds,
+			Components: &components,
+		},
+	}
+}
+
+func (p *Paginator) components(page int) discord.ContainerComponents {
+	components := discord.ContainerComponents{
+		&discord.ActionRowComponent{
+			&discord.ButtonComponent{
+				Style:    discord.SecondaryButtonStyle(),
+				CustomID: PaginatorPrevID,
+				Label:    "Previous",
+				Disabled: page == 0,
+			},
+			&discord.ButtonComponent{
+				Style:    discord.SecondaryButtonStyle(),
+				CustomID: PaginatorNextID,
+				Label:    "Next",
+				Disabled: page == len(p.Pages)-1,
+			},
+		},
+	}
+
+	if len(p.Pages) < 2 || len(p.Pages) > maxSelectOptions {
+		return components
+	}
+
+	options := make([]discord.SelectOption, len(p.Pages))
+	for i, embed := range p.Pages {
+		options[i] = discord.SelectOption{
+			Label:   fmt.Sprintf("Page %d", i+1),
+			Value:   strconv.Itoa(i),
+			Default: i == page,
+		}
+		if title := []rune(embed.Title); len(title) > 100 {
+			options[i].Description = string(title[:99]) + "…"
+		} else {
+			options[i].Description = embed.Title
+		}
+	}
+
+	return append(components, &discord.ActionRowComponent{
+		&discord.SelectComponent{
+			CustomID:    PaginatorSelectID,
+			Options:     options,
+			Placeholder: "Jump to page",
+		},
+	})
+}
@@ -0,0 +1,418 @@
+// Package components provides stateful sessions for message components on top
+// of a State. A session is attached to a message and dispatches the component
+// interactions on that message to handlers keyed by the component's custom ID.
+// Once the session has been inactive for its timeout, it expires and the
+// components on the message are edited to be disabled.
+//
+// The package also provides ready-made widgets built on sessions, such as the
+// Paginator and Confirm.
+package components
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+)
+
+// DefaultTimeout is the default inactivity timeout of a session.
+var DefaultTimeout = 5 * time.Minute
+
+// ErrExpired is returned by widgets when their session expires before the
+// user answers.
+var ErrExpired = errors.New("component session expired")
+
+// Event is the event given to a session handler.
+type Event struct {
+	// Event is the interaction event that triggered the handler.
+	Event *discord.InteractionEvent
+	// Data is the component interaction. It is either a *ButtonInteraction or
+	// a *SelectInteraction.
+	Data discord.ComponentInteraction
+	// Session is the session that the component belongs to.
+	Session *Session
+}
+
+// Handler handles a component interaction within a session. If it returns
+// nil, then the interaction is acknowledged without changing the message.
+//
+// If the returned response is an UpdateMessage response that has components,
+// then the session will keep track of the new components so that they can be
+// disabled once it expires.
+type Handler func(ev *Event) *api.InteractionResponse
+
+// Manager dispatches component interactions to the sessions attached to
+// messages.
+type Manager struct {
+	// ErrorLog is called when the Manager fails to respond to an interaction
+	// or fails to disable the components of an expired session.
+	ErrorLog func(err error) // default: log.Println
+
+	state *state.State
+	rm    func()
+
+	mut      sync.Mutex
+	sessions map[discord.MessageID]*Session
+}
+
+// NewManager creates a new Manager and adds its handler to the given State.
+// Call Close to remove it.
+func NewManager(s *state.State) *Manager {
+	m := &Manager{
+		ErrorLog: func(err error) { log.Println("components:", err) },
+		state:    s,
+		sessions: make(map[discord.MessageID]*Session),
+	}
+	m.rm = s.AddHandler(m.handle)
+	return m
+}
+
+// Close removes the handler of the Manager and closes all its sessions,
+// disabling their components.
+func (m *Manager) Close() error {
+	m.rm()
+
+	m.mut.Lock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	m.mut.Unlock()
+
+	var err error
+	for _, s := range sessions {
+		if closeErr := s.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Attach attaches a new session to the given message, replacing any existing
+// session on it. The message's components are tracked so that they can be
+// disabled once the session expires. If timeout is 0, then DefaultTimeout is
+// used.
+func (m *Manager) Attach(msg *discord.Message, timeout time.Duration) *Session {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	s := &Session{
+		ChannelID:  msg.ChannelID,
+		MessageID:  msg.ID,
+		manager:    m,
+		timeout:    timeout,
+		handlers:   make(map[discord.ComponentID]Handler),
+		components: msg.Components,
+		done:       make(chan struct{}),
+	}
+
+	s.mut.Lock()
+	s.timer = time.AfterFunc(timeout, s.expire)
+	s.mut.Unlock()
+
+	m.mut.Lock()
+	old := m.sessions[msg.ID]
+	m.sessions[msg.ID] = s
+	m.mut.Unlock()
+
+	if old != nil {
+		old.Stop()
+	}
+
+	return s
+}
+
+// Send sends a message and attaches a new session to it. See Attach.
+func (m *Manager) Send(
+	channelID discord.ChannelID,
+	data api.SendMessageData, timeout time.Duration) (*Session, error) {
+
+	msg, err := m.state.SendMessageComplex(channelID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Attach(msg, timeout), nil
+}
+
+// Session returns the session attached to the given message, or nil if there
+// is none.
+func (m *Manager) Session(messageID discord.MessageID) *Session {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	return m.sessions[messageID]
+}
+
+func (m *Manager) handle(ev *gateway.InteractionCreateEvent) {
+	data, ok := ev.Data.(discord.ComponentInteraction)
+	if !ok || ev.Message == nil {
+		return
+	}
+
+	s := m.Session(ev.Message.ID)
+	if s == nil {
+		return
+	}
+
+	resp := s.handle(&ev.InteractionEvent, data)
+	if resp == nil {
+		return
+	}
+
+	if err := m.state.RespondInteraction(ev.ID, ev.Token, *resp); err != nil {
+		m.ErrorLog(errors.Wrap(err, "failed to respond to interaction"))
+	}
+}
+
+func (m *Manager) remove(s *Session) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.sessions[s.MessageID] == s {
+		delete(m.sessions, s.MessageID)
+	}
+}
+
+// Session is a set of component handlers attached to a message. A session is
+// safe to use concurrently.
+type Session struct {
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID
+
+	manager *Manager
+	done    chan struct{}
+
+	mut        sync.Mutex
+	timeout    time.Duration
+	timer      *time.Timer
+	userID     discord.UserID
+	handlers   map[discord.ComponentID]Handler
+	components discord.ContainerComponents
+	onExpire   func()
+	closed     bool
+	expired    bool
+}
+
+// Handle sets the handler for the component with the given custom ID. The
+// same handler is used for buttons and selects.
+func (s *Session) Handle(id discord.ComponentID, h Handler) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.handlers[id] = h
+}
+
+// SetUser restricts the session to the given user. Interactions from other
+// users are acknowledged without calling any handler. A zero ID lifts the
+// restriction.
+func (s *Session) SetUser(userID discord.UserID) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.userID = userID
+}
+
+// OnExpire sets the function that is called after the session has expired and
+// its components have been disabled. It isn't called if the session is closed
+// or stopped. If the session has already expired, then f is called right away.
+func (s *Session) OnExpire(f func()) {
+	s.mut.Lock()
+
+	if s.expired {
+		s.mut.Unlock()
+		f()
+		return
+	}
+
+	s.onExpire = f
+	s.mut.Unlock()
+}
+
+// SetTimeout changes the inactivity timeout of the session and restarts it.
+func (s *Session) SetTimeout(timeout time.Duration) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.timeout = timeout
+	if !s.closed {
+		s.timer.Reset(timeout)
+	}
+}
+
+// Components returns the components that the session currently tracks.
+func (s *Session) Components() discord.ContainerComponents {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.components
+}
+
+// Done returns a channel that is closed once the session has ended, either
+// because it expired or because it was closed. If the components are being
+// disabled, then the channel is closed after that is done.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the session and edits the message to disable its components.
+func (s *Session) Close() error {
+	components, ok := s.end()
+	if !ok {
+		return nil
+	}
+	defer close(s.done)
+
+	return s.disable(components)
+}
+
+// Stop ends the session without touching the message. This is useful if a
+// handler already disables the components in its response.
+func (s *Session) Stop() {
+	if _, ok := s.end(); ok {
+		close(s.done)
+	}
+}
+
+// end marks the session as ended and returns its components. It returns false
+// if the session has already ended. The caller must close s.done.
+func (s *Session) end() (discord.ContainerComponents, bool) {
+	s.mut.Lock()
+	if s.closed {
+		s.mut.Unlock()
+		return nil, false
+	}
+
+	s.closed = true
+	s.timer.Stop()
+	components := s.components
+	s.mut.Unlock()
+
+	s.manager.remove(s)
+
+	return components, true
+}
+
+func (s *Session) disable(components discord.ContainerComponents) error {
+	if len(components) == 0 {
+		return nil
+	}
+
+	disabled := Disable(components)
+
+	_, err := s.manager.state.EditMessageComplex(s.ChannelID, s.MessageID, api.EditMessageData{
+		Components: &disabled,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to disable components")
+	}
+
+	return nil
+}
+
+func (s *Session) expire() {
+	components, ok := s.end()
+	if !ok {
+		return
+	}
+
+	if err := s.disable(components); err != nil {
+		s.manager.ErrorLog(err)
+	}
+
+	close(s.done)
+
+	s.mut.Lock()
+	s.expired = true
+	onExpire := s.onExpire
+	s.mut.Unlock()
+
+	if onExpire != nil {
+		onExpire()
+	}
+}
+
+// handle returns nil if the interaction isn't for any component in the
+// session.
+func (s *Session) handle(
+	ev *discord.InteractionEvent, data discord.ComponentInteraction) *api.InteractionResponse {
+
+	s.mut.Lock()
+
+	h, ok := s.handlers[data.ID()]
+	if s.closed || !ok {
+		s.mut.Unlock()
+		return nil
+	}
+
+	if s.userID.IsValid() && ev.SenderID() != s.userID {
+		s.mut.Unlock()
+		return &api.InteractionResponse{Type: api.DeferredMessageUpdate}
+	}
+
+	s.timer.Reset(s.timeout)
+	s.mut.Unlock()
+
+	resp := h(&Event{
+		Event:   ev,
+		Data:    data,
+		Session: s,
+	})
+	if resp == nil {
+		return &api.InteractionResponse{Type: api.DeferredMessageUpdate}
+	}
+
+	if resp.Type == api.UpdateMessage && resp.Data != nil && resp.Data.Components != nil {
+		s.mut.Lock()
+		s.components = *resp.Data.Components
+		s.mut.Unlock()
+	}
+
+	return resp
+}
+
+// Disable returns a copy of the given components with all buttons and selects
+// disabled. Link buttons are left untouched, since they don't trigger any
+// interaction.
+func Disable(components discord.ContainerComponents) discord.ContainerComponents {
+	disabled := make(discord.ContainerComponents, 0, len(components))
+
+	for _, container := range components {
+		row, ok := container.(*discord.ActionRowComponent)
+		if !ok {
+			disabled = append(disabled, container)
+			continue
+		}
+
+		newRow := make(discord.ActionRowComponent, len(*row))
+
+		for i, component := range *row {
+			switch component := component.(type) {
+			case *discord.ButtonComponent:
+				button := *component
+				if button.CustomID != "" {
+					button.Disabled = true
+				}
+				newRow[i] = &button
+			case *discord.SelectComponent:
+				sel := *component
+				sel.Disabled = true
+				newRow[i] = &sel
+			default:
+				newRow[i] = component
+			}
+		}
+
+		disabled = append(disabled, &newRow)
+	}
+
+	return disabled
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"reflect"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/internal/eventid"
+)
+
+type filterKind uint8
+
+const (
+	filterMatch filterKind = iota
+	filterGuild
+	filterChannel
+)
+
+// Filter limits the events that a handler is called with. A handler that's
+// added with AddFilteredHandler is only called if all of its filters match the
+// event.
+//
+// The guild, channel and user IDs of an event are found the same way as in
+// package infer. Filters by guild and channel are looked up by ID, so adding
+// many handlers for different guilds or channels doesn't slow down Call.
+type Filter struct {
+	kind filterKind
+	id   discord.Snowflake
+	fn   func(interface{}) bool
+}
+
+// InGuild returns a filter that only matches events of the guild with the given
+// ID.
+func InGuild(id discord.GuildID) Filter {
+	return Filter{kind: filterGuild, id: discord.Snowflake(id)}
+}
+
+// FromChannel returns a filter that only matches events of the channel with the
+// given ID.
+func FromChannel(id discord.ChannelID) Filter {
+	return Filter{kind: filterChannel, id: discord.Snowflake(id)}
+}
+
+// FromUser returns a filter that only matches events of the user with the given
+// ID.
+func FromUser(id discord.UserID) Filter {
+	return Match(func(ev interface{}) bool { return eventid.UserID(ev) == id })
+}
+
+// NotFromBot returns a filter that only matches events with an author or user
+// that isn't a bot, such as messages sent by humans. Events without an author
+// or user are matched.
+func NotFromBot() Filter {
+	return Match(func(ev interface{}) bool {
+		user := eventid.User(ev)
+		return user == nil || !user.Bot
+	})
+}
+
+// Match returns a filter that matches the events that fn returns true for. fn
+// is called before the handler with the same event, so it should be fast.
+func Match(fn func(ev interface{}) bool) Filter {
+	return Filter{kind: filterMatch, fn: fn}
+}
+
+func (f Filter) match(ev interface{}) bool {
+	switch f.kind {
+	case filterGuild:
+		return discord.Snowflake(eventid.GuildID(ev)) == f.id
+	case filterChannel:
+		return discord.Snowflake(eventid.ChannelID(ev)) == f.id
+	default:
+		return f.fn == nil || f.fn(ev)
+	}
+}
+
+// indexFilter picks the filter to look the handler up by and returns the rest.
+// Channel filters are preferred over guild filters, since channels are more
+// specific. kind is filterMatch if none of the filters can be looked up.
+func indexFilter(filters []Filter) (kind filterKind, id discord.Snowflake, rest []Filter) {
+	index := -1
+
+	for i, filter := range filters {
+		if filter.kind == filterChannel {
+			index = i
+			break
+		}
+		if filter.kind == filterGuild && index == -1 {
+			index = i
+		}
+	}
+
+	if index == -1 {
+		return filterMatch, 0, filters
+	}
+
+	rest = make([]Filter, 0, len(filters)-1)
+	rest = append(rest, filters[:index]...)
+	rest = append(rest, filters[index+1:]...)
+
+	return filters[index].kind, filters[index].id, rest
+}
+
+// idIndex is a lookup table of handlers by their event type and the ID that
+// they're filtered by. Like Handler.events, the nil type is for interfaces.
+type idIndex map[reflect.Type]map[discord.Snowflake]slab
+
+func (x *idIndex) put(t reflect.Type, id discord.Snowflake, r handler) int {
+	if *x == nil {
+		*x = make(idIndex, 10)
+	}
+
+	ids, ok := (*x)[t]
+	if !ok {
+		ids = make(map[discord.Snowflake]slab)
+		(*x)[t] = ids
+	}
+
+	slab := ids[id]
+	i := slab.Put(r)
+	ids[id] = slab
+
+	return i
+}
+
+func (x idIndex) pop(t reflect.Type, id discord.Snowflake, i int) handler {
+	ids := x[t]
+
+	slab := ids[id]
+	popped := slab.Pop(i)
+	ids[id] = slab
+
+	// Drop the emptied tables, so that Call doesn't need to look up the IDs of
+	// events that no handler wants anymore.
+	if slab.empty() {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(x, t)
+		}
+	}
+
+	return popped
+}
+
+// has returns true if there are handlers of the event type in the index.
+func (x idIndex) has(t reflect.Type) bool {
+	return len(x[t]) > 0 || len(x[nil]) > 0
+}
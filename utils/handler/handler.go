@@ -9,6 +9,14 @@
 //
 //    BenchmarkReflect-8  7260909  167 ns/op
 //
+// Handlers added with InGuild or FromChannel are looked up by the event's ID
+// instead, which takes roughly the same time regardless of the number of
+// handlers. Finding the ID costs more than checking a few predicates, so
+// filters by ID pay off with more than a few dozen handlers.
+//
+//    BenchmarkCallMatch/1000    110083  11013 ns/op
+//    BenchmarkCallInGuild/1000  1674639   767 ns/op
+//
 // Usage
 //
 // Handler's usage is mostly similar to Discordgo, in that AddHandler expects a
@@ -23,6 +31,9 @@ import (
 	"sync"
 
 	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/internal/eventid"
 )
 
 // Handler is a container for command handlers. A zero-value instance is a valid
@@ -30,6 +41,11 @@ import (
 type Handler struct {
 	mutex  sync.RWMutex
 	events map[reflect.Type]slab // nil type for interfaces
+
+	// guilds and channels contain the handlers filtered by InGuild and
+	// FromChannel.
+	guilds   idIndex
+	channels idIndex
 }
 
 func New() *Handler {
@@ -47,21 +63,44 @@ func (h *Handler) Call(ev interface{}) {
 	typedHandlers := h.events[t].Entries
 	anyHandlers := h.events[nil].Entries
 
-	if len(typedHandlers) == 0 && len(anyHandlers) == 0 {
+	// Only look up the IDs of the event if there are handlers filtered by them.
+	var guildHandlers, anyGuildHandlers []slabEntry
+	if h.guilds.has(t) {
+		id := discord.Snowflake(eventid.GuildID(ev))
+		guildHandlers = h.guilds[t][id].Entries
+		anyGuildHandlers = h.guilds[nil][id].Entries
+	}
+
+	var channelHandlers, anyChannelHandlers []slabEntry
+	if h.channels.has(t) {
+		id := discord.Snowflake(eventid.ChannelID(ev))
+		channelHandlers = h.channels[t][id].Entries
+		anyChannelHandlers = h.channels[nil][id].Entries
+	}
+
+	if len(typedHandlers) == 0 && len(anyHandlers) == 0 &&
+		len(guildHandlers) == 0 && len(anyGuildHandlers) == 0 &&
+		len(channelHandlers) == 0 && len(anyChannelHandlers) == 0 {
 		return
 	}
 
 	v := reflect.ValueOf(ev)
 
-	for _, entry := range typedHandlers {
-		if entry.isInvalid() {
-			continue
-		}
-		entry.Call(v)
-	}
+	callEntries(typedHandlers, ev, v, nil)
+	callEntries(guildHandlers, ev, v, nil)
+	callEntries(channelHandlers, ev, v, nil)
+
+	callEntries(anyHandlers, ev, v, t)
+	callEntries(anyGuildHandlers, ev, v, t)
+	callEntries(anyChannelHandlers, ev, v, t)
+}
 
-	for _, entry := range anyHandlers {
-		if entry.isInvalid() || entry.not(t) {
+// callEntries calls the valid entries whose filters match the event. If t is
+// not nil, then the entries are interface handlers that are only called if t
+// implements their interface.
+func callEntries(entries []slabEntry, ev interface{}, v reflect.Value, t reflect.Type) {
+	for _, entry := range entries {
+		if entry.isInvalid() || (t != nil && entry.not(t)) || !entry.match(ev) {
 			continue
 		}
 		entry.Call(v)
@@ -150,8 +189,26 @@ func (h *Handler) ChanFor(fn func(interface{}) bool) (out <-chan interface{}, ca
 //    ch := make(chan *gateway.MessageCreateEvent)
 //    h.AddHandler(ch)
 //
+// To only call the handler with some events, use AddFilteredHandler.
 func (h *Handler) AddHandler(handler interface{}) (rm func()) {
-	rm, err := h.addHandler(handler, false)
+	rm, err := h.addHandler(handler, false, nil)
+	if err != nil {
+		panic(err)
+	}
+	return rm
+}
+
+// AddFilteredHandler is like AddHandler, but the handler is only called with
+// the events that match all of the given filters. See Filter.
+//
+//    // Only handle messages sent by humans in a channel.
+//    h.AddFilteredHandler(func(*gateway.MessageCreateEvent) {},
+//        handler.FromChannel(channelID),
+//        handler.NotFromBot(),
+//    )
+//
+func (h *Handler) AddFilteredHandler(handler interface{}, filters ...Filter) (rm func()) {
+	rm, err := h.addHandler(handler, false, filters)
 	if err != nil {
 		panic(err)
 	}
@@ -163,7 +220,17 @@ func (h *Handler) AddHandler(handler interface{}) (rm func()) {
 // rely on the order of events arriving. Handlers added using this method should
 // not block for very long, as it may clog up other handlers.
 func (h *Handler) AddSyncHandler(handler interface{}) (rm func()) {
-	rm, err := h.addHandler(handler, true)
+	rm, err := h.addHandler(handler, true, nil)
+	if err != nil {
+		panic(err)
+	}
+	return rm
+}
+
+// AddFilteredSyncHandler is the synchronous variant of AddFilteredHandler. See
+// AddSyncHandler.
+func (h *Handler) AddFilteredSyncHandler(handler interface{}, filters ...Filter) (rm func()) {
+	rm, err := h.addHandler(handler, true, filters)
 	if err != nil {
 		panic(err)
 	}
@@ -185,7 +252,7 @@ func (h *Handler) AddHandlerCheck(handler interface{}) (rm func(), err error) {
 		}
 	}()
 
-	return h.addHandler(handler, false)
+	return h.addHandler(handler, false, nil)
 }
 
 // AddSyncHandlerCheck is the safe-guarded version of AddSyncHandler. It is
@@ -203,10 +270,10 @@ func (h *Handler) AddSyncHandlerCheck(handler interface{}) (rm func(), err error
 		}
 	}()
 
-	return h.addHandler(handler, true)
+	return h.addHandler(handler, true, nil)
 }
 
-func (h *Handler) addHandler(fn interface{}, sync bool) (rm func(), err error) {
+func (h *Handler) addHandler(fn interface{}, sync bool, filters []Filter) (rm func(), err error) {
 	// Reflect the handler
 	r, err := newHandler(fn, sync)
 	if err != nil {
@@ -219,22 +286,43 @@ func (h *Handler) addHandler(fn interface{}, sync bool) (rm func(), err error) {
 		t = r.event
 	}
 
-	h.mutex.Lock()
+	kind, key, rest := indexFilter(filters)
+	r.filters = rest
 
-	if h.events == nil {
-		h.events = make(map[reflect.Type]slab, 10)
+	var index *idIndex
+	switch kind {
+	case filterGuild:
+		index = &h.guilds
+	case filterChannel:
+		index = &h.channels
 	}
 
-	slab := h.events[t]
-	id = slab.Put(r)
-	h.events[t] = slab
+	h.mutex.Lock()
+
+	if index != nil {
+		id = index.put(t, key, r)
+	} else {
+		if h.events == nil {
+			h.events = make(map[reflect.Type]slab, 10)
+		}
+
+		slab := h.events[t]
+		id = slab.Put(r)
+		h.events[t] = slab
+	}
 
 	h.mutex.Unlock()
 
 	return func() {
+		var popped handler
+
 		h.mutex.Lock()
-		slab := h.events[t]
-		popped := slab.Pop(id)
+		if index != nil {
+			popped = index.pop(t, key, id)
+		} else {
+			slab := h.events[t]
+			popped = slab.Pop(id)
+		}
 		h.mutex.Unlock()
 
 		popped.cleanup()
@@ -245,6 +333,7 @@ type handler struct {
 	event     reflect.Type // underlying type; arg0 or chan underlying type
 	callback  reflect.Value
 	chanclose reflect.Value // IsValid() if chan
+	filters   []Filter  // filters that aren't looked up by ID
 	isIface   bool
 	isSync    bool
 	isOnce    bool
@@ -303,6 +392,16 @@ func (h handler) not(event reflect.Type) bool {
 	return h.event != event
 }
 
+// match returns true if all of the handler's filters match the event.
+func (h handler) match(ev interface{}) bool {
+	for _, filter := range h.filters {
+		if !filter.match(ev) {
+			return false
+		}
+	}
+	return true
+}
+
 func (h handler) Call(event reflect.Value) {
 	if h.isSync {
 		h.call(event)
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +12,13 @@ import (
 	"github.com/diamondburned/arikawa/v3/gateway"
 )
 
+// AddHandler must keep its signature, since it's stored as a function value
+// and implemented by wrappers.
+var (
+	_ func(*Handler, interface{}) func() = (*Handler).AddHandler
+	_ func(*Handler, interface{}) func() = (*Handler).AddSyncHandler
+)
+
 func newMessage(content string) *gateway.MessageCreateEvent {
 	return &gateway.MessageCreateEvent{
 		Message: discord.Message{Content: content},
@@ -276,6 +284,137 @@ func TestHandlerChanFor(t *testing.T) {
 	}
 }
 
+func TestCallFilters(t *testing.T) {
+	h := New()
+
+	var called []string
+	add := func(name string, filters ...Filter) func() {
+		return h.AddFilteredSyncHandler(func(*gateway.MessageCreateEvent) {
+			called = append(called, name)
+		}, filters...)
+	}
+
+	add("all")
+	add("guild 1", InGuild(1))
+	add("guild 2", InGuild(2))
+	add("channel 10", FromChannel(10))
+	add("guild 1 channel 10", InGuild(1), FromChannel(10))
+	add("guild 2 channel 10", InGuild(2), FromChannel(10))
+	add("user 100", FromUser(100))
+	add("not bot", NotFromBot())
+	rmGuild := add("guild 1 not bot", InGuild(1), NotFromBot())
+
+	h.AddFilteredSyncHandler(func(ev interface{}) {
+		if _, ok := ev.(*gateway.MessageCreateEvent); ok {
+			called = append(called, "any guild 1")
+		}
+	}, InGuild(1))
+
+	tests := []struct {
+		name   string
+		msg    discord.Message
+		expect []string
+	}{
+		{
+			name: "guild 1 channel 10 human",
+			msg: discord.Message{
+				GuildID:   1,
+				ChannelID: 10,
+				Author:    discord.User{ID: 100},
+			},
+			expect: []string{
+				"all", "user 100", "not bot",
+				"guild 1", "guild 1 not bot",
+				"channel 10", "guild 1 channel 10",
+				"any guild 1",
+			},
+		},
+		{
+			name: "guild 2 channel 20 bot",
+			msg: discord.Message{
+				GuildID:   2,
+				ChannelID: 20,
+				Author:    discord.User{ID: 200, Bot: true},
+			},
+			expect: []string{"all", "guild 2"},
+		},
+		{
+			name: "direct message",
+			msg: discord.Message{
+				ChannelID: 30,
+				Author:    discord.User{ID: 100},
+			},
+			expect: []string{"all", "user 100", "not bot"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called = nil
+			h.Call(&gateway.MessageCreateEvent{Message: test.msg})
+
+			if !sameStrings(called, test.expect) {
+				t.Fatalf("expected %q, got %q", test.expect, called)
+			}
+		})
+	}
+
+	rmGuild()
+
+	called = nil
+	h.Call(&gateway.MessageCreateEvent{Message: discord.Message{GuildID: 1}})
+
+	for _, name := range called {
+		if name == "guild 1 not bot" {
+			t.Fatal("removed handler was called")
+		}
+	}
+
+	// The interface handler filtered by guild may take any event type.
+	if !h.guilds.has(reflect.TypeOf(&gateway.TypingStartEvent{})) {
+		t.Fatal("interface handlers filtered by guild are missing")
+	}
+}
+
+func TestCallFiltersRemove(t *testing.T) {
+	h := New()
+
+	rm1 := h.AddFilteredSyncHandler(func(*gateway.MessageCreateEvent) {}, InGuild(1))
+	rm2 := h.AddFilteredSyncHandler(func(*gateway.MessageCreateEvent) {}, InGuild(1))
+	rm3 := h.AddFilteredSyncHandler(func(*gateway.MessageCreateEvent) {}, FromChannel(1))
+
+	rm1()
+	if len(h.guilds) != 1 {
+		t.Fatal("guild table was dropped with a handler left")
+	}
+
+	rm2()
+	rm3()
+	if len(h.guilds) != 0 || len(h.channels) != 0 {
+		t.Fatal("empty tables weren't dropped")
+	}
+}
+
+// sameStrings returns true if both slices have the same strings in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+		if counts[s] < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 func BenchmarkReflect(b *testing.B) {
 	h, err := newHandler(func(m *gateway.MessageCreateEvent) {}, false)
 	if err != nil {
@@ -297,3 +436,55 @@ func BenchmarkReflect(b *testing.B) {
 		h.call(msgV)
 	}
 }
+
+// benchmarkCallFiltered benchmarks calling an event with 10, 100 and 1000
+// handlers, each added with the filter returned for its index. The event only
+// matches the first handler.
+func benchmarkCallFiltered(b *testing.B, ev interface{}, filter func(i int) Filter) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			h := New()
+			for i := 0; i < n; i++ {
+				h.AddFilteredSyncHandler(func(*gateway.MessageCreateEvent) {}, filter(i))
+			}
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				h.Call(ev)
+			}
+		})
+	}
+}
+
+// BenchmarkCallMatch filters handlers by guild using predicates, which are
+// checked for every handler.
+func BenchmarkCallMatch(b *testing.B) {
+	msg := &gateway.MessageCreateEvent{Message: discord.Message{GuildID: 1}}
+
+	benchmarkCallFiltered(b, msg, func(i int) Filter {
+		guildID := discord.GuildID(i + 1)
+		return Match(func(ev interface{}) bool {
+			return ev.(*gateway.MessageCreateEvent).GuildID == guildID
+		})
+	})
+}
+
+// BenchmarkCallInGuild filters handlers using InGuild, so only the handlers of
+// the event's guild are checked.
+func BenchmarkCallInGuild(b *testing.B) {
+	msg := &gateway.MessageCreateEvent{Message: discord.Message{GuildID: 1}}
+
+	benchmarkCallFiltered(b, msg, func(i int) Filter {
+		return InGuild(discord.GuildID(i + 1))
+	})
+}
+
+// BenchmarkCallFromChannel is BenchmarkCallInGuild with FromChannel.
+func BenchmarkCallFromChannel(b *testing.B) {
+	msg := &gateway.MessageCreateEvent{Message: discord.Message{ChannelID: 1}}
+
+	benchmarkCallFiltered(b, msg, func(i int) Filter {
+		return FromChannel(discord.ChannelID(i + 1))
+	})
+}
@@ -42,3 +42,13 @@ func (s *slab) Pop(i int) handler {
 	s.free = i
 	return popped
 }
+
+// empty returns true if the slab has no handlers.
+func (s *slab) empty() bool {
+	for _, entry := range s.Entries {
+		if !entry.isInvalid() {
+			return false
+		}
+	}
+	return true
+}
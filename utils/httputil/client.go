@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 	"github.com/diamondburned/arikawa/v3/utils/json"
 )
 
@@ -41,6 +42,16 @@ type Client struct {
 	// Default to the global Retries variable (5).
 	Retries uint
 
+	// RetryPolicy decides whether and when a failed attempt is retried, up to
+	// Retries attempts. If it's nil, then requests are never retried.
+	RetryPolicy RetryPolicy // default: DefaultRetryPolicy
+
+	// Instrumenter, if not nil, receives an instrument.RequestStart event for
+	// every attempt and an instrument.RequestFinish event for every request.
+	// It is promoted to api.Client, session.Session and state.State, which
+	// also send their own events to it.
+	Instrumenter instrument.Instrumenter
+
 	context context.Context
 }
 
@@ -49,6 +60,7 @@ func NewClient() *Client {
 		Client:        httpdriver.NewClient(),
 		SchemaEncoder: &DefaultSchema{},
 		Retries:       Retries,
+		RetryPolicy:   DefaultRetryPolicy,
 		context:       context.Background(),
 	}
 }
@@ -100,6 +112,8 @@ type MultipartWriter interface {
 // MeanwhileMultipart concurrently encodes and writes the given multipart writer
 // at the same time. The writer will be called in another goroutine, but the
 // writer will be closed when MeanwhileMultipart returns.
+//
+// Since the body is streamed, the request is never retried once it is sent.
 func (c *Client) MeanwhileMultipart(
 	writer MultipartWriter,
 	method, url string, opts ...RequestOption) (httpdriver.Response, error) {
@@ -197,9 +211,47 @@ func (c *Client) request(
 		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
 	}
 
+	var (
+		trace    *instrument.RequestTrace
+		lastWait time.Duration
+		attempts int
+	)
+
+	if c.Instrumenter != nil {
+		trace = &instrument.RequestTrace{}
+		ctx = instrument.WithRequestTrace(ctx, trace)
+
+		start := time.Now()
+		defer func() {
+			retries := attempts - 1
+			if retries < 0 {
+				retries = 0
+			}
+
+			c.Instrumenter.Observe(instrument.RequestFinish{
+				Method:        method,
+				URL:           url,
+				Bucket:        trace.Bucket,
+				Status:        status,
+				Retries:       retries,
+				RateLimitWait: trace.RateLimitWait,
+				Duration:      time.Since(start),
+				Err:           doErr,
+			})
+		}()
+	}
+
+	// Keep track of whether the options give the request a body that can't be
+	// sent again.
+	body := &requestBody{}
+	ctx = context.WithValue(ctx, requestBodyKey, body)
+
 	// The c.Retries < 1 check ensures that we retry forever if that field is
 	// less than 1.
 	for i := uint(0); c.Retries < 1 || i < c.Retries; i++ {
+		status = 0
+		onRespErr = nil
+
 		q, err := c.Client.NewRequest(ctx, method, url)
 		if err != nil {
 			doErr = RequestError{err}
@@ -217,6 +269,18 @@ func (c *Client) request(
 			return
 		}
 
+		if trace != nil {
+			c.Instrumenter.Observe(instrument.RequestStart{
+				Method:        method,
+				URL:           url,
+				Bucket:        trace.Bucket,
+				Attempt:       attempts,
+				RateLimitWait: trace.RateLimitWait - lastWait,
+			})
+			lastWait = trace.RateLimitWait
+		}
+		attempts++
+
 		r, doErr = c.Client.Do(q)
 
 		// Call OnResponse() even if the request failed.
@@ -227,15 +291,51 @@ func (c *Client) request(
 			}
 		}
 
-		if onRespErr != nil || doErr != nil {
-			continue
+		if doErr == nil {
+			status = r.GetStatus()
 		}
 
-		if status = r.GetStatus(); status == StatusTooManyRequests || status >= 500 {
-			continue
+		if onRespErr == nil && doErr == nil &&
+			status != StatusTooManyRequests && status < 500 {
+			break
 		}
 
-		break
+		// Stop if this was the last attempt, the context is done or the body
+		// was already consumed.
+		if (c.Retries > 0 && i+1 >= c.Retries) || ctx.Err() != nil || body.oneShot {
+			break
+		}
+
+		if c.RetryPolicy == nil {
+			break
+		}
+
+		attempt := Attempt{
+			Method:   method,
+			Number:   int(i),
+			Response: r,
+			Err:      doErr,
+		}
+		if attempt.Err == nil {
+			attempt.Err = onRespErr
+		}
+
+		delay, retry := c.RetryPolicy.Retry(attempt)
+		if !retry {
+			break
+		}
+
+		// Discard the failed response before retrying.
+		if r != nil {
+			r.GetBody().Close()
+			r = nil
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			onRespErr = nil
+			doErr = err
+			break
+		}
 	}
 
 	if onRespErr != nil {
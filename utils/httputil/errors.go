@@ -2,7 +2,9 @@ package httputil
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/diamondburned/arikawa/v3/utils/json"
 )
@@ -48,6 +50,9 @@ type HTTPError struct {
 func (err HTTPError) Error() string {
 	switch {
 	case err.Errors != nil:
+		if fields := err.FieldErrors(); len(fields) > 0 {
+			return fmt.Sprintf("Discord %d error: %s: %s", err.Status, err.Message, fields)
+		}
 		return fmt.Sprintf("Discord %d error: %s: %s", err.Status, err.Message, err.Errors)
 
 	case err.Message != "":
@@ -66,4 +71,136 @@ func (err HTTPError) Error() string {
 	}
 }
 
+// Is returns true if target is the ErrorCode of the error. This allows checking
+// for specific Discord errors using errors.Is:
+//
+//    if errors.Is(err, api.ErrUnknownMessage) {
+//        // The message was deleted.
+//    }
+//
+func (err HTTPError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != 0 && code == err.Code
+}
+
+// FieldErrors parses the validation errors of the fields of the request body.
+// It returns nil if there are none or they can't be parsed.
+func (err HTTPError) FieldErrors() FieldErrors {
+	fields, parseErr := ParseFieldErrors(err.Errors)
+	if parseErr != nil {
+		return nil
+	}
+	return fields
+}
+
+// ErrorCode is a JSON error code returned by Discord. The known codes are
+// declared in package api. An ErrorCode is also an error, so that it can be
+// the target of errors.Is.
 type ErrorCode uint
+
+// Error formats the error code.
+func (code ErrorCode) Error() string {
+	return "Discord error code " + strconv.FormatUint(uint64(code), 10)
+}
+
+// FieldError is the validation error of a single field of the request body.
+type FieldError struct {
+	// Code is the error code, such as "BASE_TYPE_MAX_LENGTH".
+	Code string `json:"code"`
+	// Message is the human-readable message of the error.
+	Message string `json:"message"`
+}
+
+// FieldErrors maps the path of every invalid field, such as "embeds.0.title",
+// to its errors. Errors of the request body as a whole have an empty path.
+type FieldErrors map[string][]FieldError
+
+// ParseFieldErrors parses the nested errors object of a Discord error response
+// into FieldErrors.
+func ParseFieldErrors(raw json.Raw) (FieldErrors, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	fields := FieldErrors{}
+	if err := fields.parse("", raw); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func (fields FieldErrors) parse(path string, raw json.Raw) error {
+	var tree map[string]json.Raw
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		// Older API versions return a list of messages instead.
+		var messages []string
+		if json.Unmarshal(raw, &messages) != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			fields[path] = append(fields[path], FieldError{Message: message})
+		}
+
+		return nil
+	}
+
+	for key, value := range tree {
+		if key == "_errors" {
+			var errs []FieldError
+			if err := json.Unmarshal(value, &errs); err != nil {
+				return err
+			}
+
+			fields[path] = append(fields[path], errs...)
+			continue
+		}
+
+		if path != "" {
+			key = path + "." + key
+		}
+
+		if err := fields.parse(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String formats the field errors sorted by their paths, for example:
+//
+//    embeds.0.title: BASE_TYPE_MAX_LENGTH: Must be 256 or fewer in length.
+//
+// Multiple errors are separated by semicolons.
+func (fields FieldErrors) String() string {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var lines []string
+
+	for _, path := range paths {
+		for _, err := range fields[path] {
+			var line strings.Builder
+
+			if path != "" {
+				line.WriteString(path)
+				line.WriteString(": ")
+			}
+
+			if err.Code != "" {
+				line.WriteString(err.Code)
+				line.WriteString(": ")
+			}
+
+			line.WriteString(err.Message)
+			lines = append(lines, line.String())
+		}
+	}
+
+	return strings.Join(lines, "; ")
+}
@@ -0,0 +1,76 @@
+package httputil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/utils/json"
+)
+
+func TestHTTPErrorIs(t *testing.T) {
+	var err error = &HTTPError{Status: 404, Code: 10008, Message: "Unknown Message"}
+
+	if !errors.Is(err, ErrorCode(10008)) {
+		t.Error("error is not its own code")
+	}
+	if errors.Is(err, ErrorCode(10003)) {
+		t.Error("error is another code")
+	}
+	if errors.Is(&HTTPError{Status: 500}, ErrorCode(0)) {
+		t.Error("error without a code is code 0")
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	const body = `{
+		"code": 50035,
+		"message": "Invalid Form Body",
+		"errors": {
+			"_errors": [{"code": "BASE_TYPE_REQUIRED", "message": "This field is required"}],
+			"embeds": {
+				"0": {
+					"title": {
+						"_errors": [{
+							"code": "BASE_TYPE_MAX_LENGTH",
+							"message": "Must be 256 or fewer in length."
+						}]
+					}
+				}
+			},
+			"content": ["Must be 2000 or fewer in length."]
+		}
+	}`
+
+	var httpErr HTTPError
+	if err := json.Unmarshal([]byte(body), &httpErr); err != nil {
+		t.Fatal("failed to unmarshal:", err)
+	}
+	httpErr.Status = 400
+
+	expect := FieldErrors{
+		"": {{Code: "BASE_TYPE_REQUIRED", Message: "This field is required"}},
+		"embeds.0.title": {{
+			Code:    "BASE_TYPE_MAX_LENGTH",
+			Message: "Must be 256 or fewer in length.",
+		}},
+		"content": {{Message: "Must be 2000 or fewer in length."}},
+	}
+
+	if fields := httpErr.FieldErrors(); !reflect.DeepEqual(fields, expect) {
+		t.Fatalf("unexpected field errors:\nexpected %#v\ngot      %#v", expect, fields)
+	}
+
+	const expectError = "Discord 400 error: Invalid Form Body: " +
+		"BASE_TYPE_REQUIRED: This field is required; " +
+		"content: Must be 2000 or fewer in length.; " +
+		"embeds.0.title: BASE_TYPE_MAX_LENGTH: Must be 256 or fewer in length."
+
+	if err := httpErr.Error(); err != expectError {
+		t.Fatalf("unexpected error:\nexpected %q\ngot      %q", expectError, err)
+	}
+
+	if _, err := ParseFieldErrors(json.Raw(`{"a": 1}`)); err == nil {
+		t.Fatal("expected error parsing invalid field errors")
+	}
+}
@@ -1,9 +1,13 @@
 package httputil
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
 
 	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
 	"github.com/diamondburned/arikawa/v3/utils/json"
@@ -68,27 +72,53 @@ func WithSchema(schema SchemaEncoder, v interface{}) RequestOption {
 	}
 }
 
+// WithBody sets the body of the request. Since the body can only be read once,
+// the request is never retried; use WithBodyFunc for requests that should be.
 func WithBody(body io.ReadCloser) RequestOption {
 	return func(r httpdriver.Request) error {
+		markOneShot(r)
 		r.WithBody(body)
 		return nil
 	}
 }
 
-// WithJSONBody inserts a JSON body into the request. This ignores JSON errors.
+// WithBodyFunc sets the body of the request to the one returned by fn. fn is
+// called again for every attempt, so the request can be retried.
+func WithBodyFunc(fn func() (io.ReadCloser, error)) RequestOption {
+	return func(r httpdriver.Request) error {
+		body, err := fn()
+		if err != nil {
+			return err
+		}
+
+		r.WithBody(body)
+		return nil
+	}
+}
+
+// WithJSONBody inserts a JSON body into the request. The value is encoded once
+// and the encoded body is reused if the request is retried.
 func WithJSONBody(v interface{}) RequestOption {
 	if v == nil {
 		return func(httpdriver.Request) error { return nil }
 	}
 
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+
 	return func(r httpdriver.Request) error {
-		rp, wp := io.Pipe()
-		go func() { wp.CloseWithError(json.EncodeStream(wp, v)) }()
+		once.Do(func() { body, err = json.Marshal(v) })
+		if err != nil {
+			return errors.Wrap(err, "failed to encode JSON body")
+		}
 
 		r.AddHeader(http.Header{
 			"Content-Type": {"application/json"},
 		})
-		r.WithBody(rp)
+		r.WithBody(io.NopCloser(bytes.NewReader(body)))
 		return nil
 	}
 }
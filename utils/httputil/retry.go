@@ -0,0 +1,174 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/internal/backoff"
+	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
+)
+
+// Attempt describes a failed attempt of a request. An attempt fails if the
+// request couldn't be done, if an OnResponse handler failed, or if the response
+// has the status 429 or 5xx.
+type Attempt struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// Number is the attempt number, starting at 0.
+	Number int
+	// Response is the response of the attempt. It is nil if the request
+	// couldn't be done.
+	Response httpdriver.Response
+	// Err is the error of the attempt, if any.
+	Err error
+}
+
+// Status returns the status code of the response or 0 if there's none.
+func (a Attempt) Status() int {
+	if a.Response == nil {
+		return 0
+	}
+	return a.Response.GetStatus()
+}
+
+// RetryPolicy decides whether a failed attempt of a request is retried and how
+// long to wait before retrying.
+//
+// Requests with a body given by WithBody are never retried, since the body
+// can't be sent twice. Use WithBodyFunc to make these requests retryable.
+type RetryPolicy interface {
+	// Retry returns the duration to wait before retrying and whether the
+	// request should be retried at all.
+	Retry(a Attempt) (time.Duration, bool)
+}
+
+// RetryPolicyFunc is a function that implements RetryPolicy.
+type RetryPolicyFunc func(a Attempt) (time.Duration, bool)
+
+// Retry calls f.
+func (f RetryPolicyFunc) Retry(a Attempt) (time.Duration, bool) { return f(a) }
+
+// DefaultRetryPolicy is the RetryPolicy of new Clients.
+var DefaultRetryPolicy RetryPolicy = &BackoffPolicy{
+	Min: 500 * time.Millisecond,
+	Max: 15 * time.Second,
+}
+
+// BackoffPolicy is a RetryPolicy that waits an exponentially increasing
+// duration with jitter between attempts. If the response has a Retry-After
+// header, then that duration is waited instead.
+//
+// Rate limited requests (429) are always retried, since the server didn't
+// handle them. Server errors (5xx) and failed requests are only retried if the
+// method is idempotent, because the server might have already handled the
+// request; see IsIdempotent.
+type BackoffPolicy struct {
+	// Min is the duration to wait after the first attempt.
+	Min time.Duration
+	// Max is the maximum duration to wait between attempts.
+	Max time.Duration
+	// RetryUnsafe, if true, also retries server errors and failed requests of
+	// methods that aren't idempotent, such as POST. This may duplicate their
+	// side effects, like sending a message twice.
+	RetryUnsafe bool
+}
+
+var _ RetryPolicy = (*BackoffPolicy)(nil)
+
+// Retry implements RetryPolicy.
+func (p *BackoffPolicy) Retry(a Attempt) (time.Duration, bool) {
+	status := a.Status()
+
+	if status != StatusTooManyRequests {
+		if a.Err == nil && status < 500 {
+			return 0, false
+		}
+		if !p.RetryUnsafe && !IsIdempotent(a.Method) {
+			return 0, false
+		}
+	}
+
+	if a.Response != nil {
+		if d, ok := RetryAfter(a.Response.GetHeader()); ok {
+			return d, true
+		}
+	}
+
+	b := backoff.NewBackoff(p.Min, p.Max)
+	return b.ForAttempt(int32(a.Number)), true
+}
+
+// IsIdempotent returns true if requests with the given method can be safely
+// repeated, that is, GET, HEAD, OPTIONS, PUT and DELETE.
+func IsIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns false if the header is missing or
+// invalid.
+func RetryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type contextKey uint8
+
+const requestBodyKey contextKey = iota
+
+// requestBody is attached to the context of requests to keep track of their
+// bodies across attempts.
+type requestBody struct {
+	// oneShot is true if the request has a body that can only be sent once.
+	oneShot bool
+}
+
+// markOneShot marks the request as having a body that can't be sent again.
+func markOneShot(r httpdriver.Request) {
+	if body, ok := r.GetContext().Value(requestBodyKey).(*requestBody); ok {
+		body.oneShot = true
+	}
+}
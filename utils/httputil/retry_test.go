@@ -0,0 +1,192 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testResponse struct {
+	status int
+	header http.Header
+}
+
+// newRetryServer creates a server that replies with the given responses in
+// order, then with 200 OK. The bodies of all requests are sent into the
+// returned channel.
+func newRetryServer(t *testing.T, responses ...testResponse) (*Client, string, <-chan string) {
+	bodies := make(chan string, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+
+		if len(responses) == 0 {
+			w.Write([]byte("{}"))
+			return
+		}
+
+		resp := responses[0]
+		responses = responses[1:]
+
+		for k, v := range resp.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.status)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClient()
+	client.RetryPolicy = &BackoffPolicy{Min: time.Millisecond, Max: 5 * time.Millisecond}
+
+	return client, srv.URL, bodies
+}
+
+func TestRetryPolicy(t *testing.T) {
+	type test struct {
+		name      string
+		method    string
+		opt       func() RequestOption
+		responses []testResponse
+		status    int
+		attempts  int
+	}
+
+	jsonBody := func() RequestOption { return WithJSONBody(map[string]string{"a": "b"}) }
+	oneShot := func() RequestOption { return WithBody(io.NopCloser(strings.NewReader(`{"a":"b"}`))) }
+
+	tests := []test{
+		{
+			name:      "get server error",
+			method:    "GET",
+			responses: []testResponse{{status: 500}, {status: 502}},
+			status:    200,
+			attempts:  3,
+		},
+		{
+			name:      "post server error",
+			method:    "POST",
+			opt:       jsonBody,
+			responses: []testResponse{{status: 500}},
+			status:    500,
+			attempts:  1,
+		},
+		{
+			name:   "post rate limited",
+			method: "POST",
+			opt:    jsonBody,
+			responses: []testResponse{
+				{status: 429, header: http.Header{"Retry-After": {"0.01"}}},
+			},
+			status:   200,
+			attempts: 2,
+		},
+		{
+			name:      "one-shot body",
+			method:    "POST",
+			opt:       oneShot,
+			responses: []testResponse{{status: 429}},
+			status:    429,
+			attempts:  1,
+		},
+		{
+			name:      "client error",
+			method:    "GET",
+			responses: []testResponse{{status: 404}},
+			status:    404,
+			attempts:  1,
+		},
+		{
+			name:   "max retries",
+			method: "GET",
+			responses: []testResponse{
+				{status: 500}, {status: 500}, {status: 500}, {status: 500}, {status: 500},
+			},
+			status:   500,
+			attempts: 5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, url, bodies := newRetryServer(t, test.responses...)
+
+			var opts []RequestOption
+			if test.opt != nil {
+				opts = append(opts, test.opt())
+			}
+
+			var status int
+
+			r, err := client.Request(test.method, url, opts...)
+			if err == nil {
+				status = r.GetStatus()
+				r.GetBody().Close()
+			} else {
+				var httpErr *HTTPError
+				if !errors.As(err, &httpErr) {
+					t.Fatal("unexpected error:", err)
+				}
+				status = httpErr.Status
+			}
+
+			if status != test.status {
+				t.Errorf("expected status %d, got %d", test.status, status)
+			}
+
+			if len(bodies) != test.attempts {
+				t.Fatalf("expected %d attempts, got %d", test.attempts, len(bodies))
+			}
+
+			if test.opt == nil {
+				return
+			}
+
+			for i := 0; i < test.attempts; i++ {
+				if body := <-bodies; body != `{"a":"b"}` {
+					t.Errorf("attempt %d: unexpected body %q", i, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	client, url, _ := newRetryServer(t, testResponse{
+		status: 429,
+		header: http.Header{"Retry-After": {"10"}},
+	})
+	client.Timeout = 50 * time.Millisecond
+
+	_, err := client.Request("GET", url)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected deadline exceeded, got", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"2", 2 * time.Second, true},
+		{"0.5", 500 * time.Millisecond, true},
+		{"-1", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		d, ok := RetryAfter(http.Header{"Retry-After": {test.value}})
+		if d != test.expect || ok != test.ok {
+			t.Errorf("%q: expected (%v, %v), got (%v, %v)", test.value, test.expect, test.ok, d, ok)
+		}
+	}
+}
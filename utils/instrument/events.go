@@ -0,0 +1,113 @@
+package instrument
+
+import "time"
+
+// RequestStart is sent before each attempt of a REST request, after the rate
+// limiter has let the request through.
+type RequestStart struct {
+	Method string
+	URL    string
+	// Bucket is the rate limit bucket of the request. It is empty if the
+	// client has no rate limiter.
+	Bucket string
+	// Attempt is the attempt number, starting at 0.
+	Attempt int
+	// RateLimitWait is the time this attempt spent waiting for the rate
+	// limiter.
+	RateLimitWait time.Duration
+}
+
+// RequestFinish is sent once a REST request is done, successful or not.
+type RequestFinish struct {
+	Method string
+	URL    string
+	Bucket string
+	// Status is the status code of the last response. It is 0 if no response
+	// was received.
+	Status int
+	// Retries is the number of attempts made after the first one.
+	Retries int
+	// RateLimitWait is the total time spent waiting for the rate limiter.
+	RateLimitWait time.Duration
+	// Duration is the total time taken by the request, including retries and
+	// rate limits.
+	Duration time.Duration
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// GatewayDial is sent after each attempt to dial the gateway.
+type GatewayDial struct {
+	// Attempt is the attempt number, starting at 0.
+	Attempt  int
+	Duration time.Duration
+	Err      error
+}
+
+// GatewayIdentify is sent when the gateway identifies a new session.
+type GatewayIdentify struct {
+	// Shard is the shard ID and the total number of shards. It is zero if the
+	// gateway isn't sharded.
+	Shard [2]int
+}
+
+// GatewayResume is sent when the gateway resumes a previous session.
+type GatewayResume struct {
+	SessionID string
+	Sequence  int64
+}
+
+// GatewayReconnect is sent when the gateway starts reconnecting after having
+// been connected.
+type GatewayReconnect struct {
+	// Err is the last error seen before reconnecting, if any.
+	Err error
+}
+
+// GatewayHeartbeat is sent when a heartbeat is acknowledged.
+type GatewayHeartbeat struct {
+	// Latency is the time between sending the heartbeat and receiving its
+	// acknowledgement.
+	Latency time.Duration
+}
+
+// GatewayError is sent for every background error of the gateway. These are
+// the same errors sent as ws.BackgroundErrorEvent.
+type GatewayError struct {
+	Err error
+}
+
+// EventDispatch is sent after a gateway event has been dispatched to the
+// handlers. Only synchronous handlers count towards the duration.
+type EventDispatch struct {
+	// Type is the gateway event type, such as "MESSAGE_CREATE".
+	Type     string
+	Duration time.Duration
+}
+
+// CacheLookup is sent when the state looks up something in its cache.
+type CacheLookup struct {
+	// Kind is what was looked up, such as "channel" or "members".
+	Kind string
+	// Hit is true if the cache had the data. Otherwise, the data is fetched
+	// from the API.
+	Hit bool
+}
+
+// StateError is sent for every error of the state cache. These are the same
+// errors given to State.StateLog.
+type StateError struct {
+	Err error
+}
+
+func (RequestStart) EventName() string     { return "api.request.start" }
+func (RequestFinish) EventName() string    { return "api.request.finish" }
+func (GatewayDial) EventName() string      { return "gateway.dial" }
+func (GatewayIdentify) EventName() string  { return "gateway.identify" }
+func (GatewayResume) EventName() string    { return "gateway.resume" }
+func (GatewayReconnect) EventName() string { return "gateway.reconnect" }
+func (GatewayHeartbeat) EventName() string { return "gateway.heartbeat" }
+func (GatewayError) EventName() string     { return "gateway.error" }
+func (EventDispatch) EventName() string    { return "session.dispatch" }
+func (CacheLookup) EventName() string      { return "state.cache" }
+func (StateError) EventName() string       { return "state.error" }
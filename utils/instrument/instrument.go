@@ -0,0 +1,97 @@
+// Package instrument provides a single interface to observe what the library is
+// doing. An Instrumenter set on an api.Client, gateway.Gateway, session.Session
+// or state.State receives typed events for REST requests, the gateway
+// connection, event dispatching and the state cache.
+//
+// Usage
+//
+// The Instrumenter field of api.Client is promoted to Session and State, so
+// setting it once before opening the session instruments the whole stack:
+//
+//    s := state.New("Bot " + token)
+//    s.Instrumenter = instrument.Multi(
+//        instrument.NewLogger(slog.Default()),
+//        metrics,
+//    )
+//
+// Two adapters are provided: NewLogger, which logs events into a log/slog-style
+// logger, and Metrics, which exports events in the Prometheus text format.
+package instrument
+
+import (
+	"context"
+	"time"
+)
+
+// Event is the interface of all events sent to an Instrumenter. The concrete
+// types are declared in this package.
+type Event interface {
+	// EventName returns a short dotted name of the event, such as
+	// "api.request.finish".
+	EventName() string
+}
+
+// Instrumenter observes events. Observe may be called concurrently from
+// multiple goroutines and should not block for long, since it's called inline.
+type Instrumenter interface {
+	Observe(Event)
+}
+
+// Func is a function that implements Instrumenter.
+type Func func(Event)
+
+// Observe calls f.
+func (f Func) Observe(ev Event) { f(ev) }
+
+type multi []Instrumenter
+
+// Multi returns an Instrumenter that sends every event to all of the given
+// instrumenters in order. Nil instrumenters are skipped.
+func Multi(instrumenters ...Instrumenter) Instrumenter {
+	m := make(multi, 0, len(instrumenters))
+	for _, i := range instrumenters {
+		if i != nil {
+			m = append(m, i)
+		}
+	}
+	return m
+}
+
+func (m multi) Observe(ev Event) {
+	for _, i := range m {
+		i.Observe(ev)
+	}
+}
+
+// Observe sends the event to i if i is not nil.
+func Observe(i Instrumenter, ev Event) {
+	if i != nil {
+		i.Observe(ev)
+	}
+}
+
+type contextKey uint8
+
+const requestTraceKey contextKey = iota
+
+// RequestTrace is attached to the context of instrumented requests. It lets
+// the layers above the HTTP client, such as the rate limiter in package api,
+// add information to the request events.
+type RequestTrace struct {
+	// Bucket is the rate limit bucket of the request.
+	Bucket string
+	// RateLimitWait is the total time spent waiting for the rate limiter.
+	RateLimitWait time.Duration
+}
+
+// WithRequestTrace returns a copy of ctx with the given RequestTrace.
+func WithRequestTrace(ctx context.Context, trace *RequestTrace) context.Context {
+	return context.WithValue(ctx, requestTraceKey, trace)
+}
+
+// RequestTraceFromContext returns the RequestTrace in ctx or nil if the request
+// is not instrumented.
+func RequestTraceFromContext(ctx context.Context) *RequestTrace {
+	trace, _ := ctx.Value(requestTraceKey).(*RequestTrace)
+	return trace
+}
@@ -0,0 +1,196 @@
+package instrument
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api/rate"
+)
+
+func TestMulti(t *testing.T) {
+	var got []string
+
+	i := Multi(
+		Func(func(ev Event) { got = append(got, "a:"+ev.EventName()) }),
+		nil,
+		Func(func(ev Event) { got = append(got, "b:"+ev.EventName()) }),
+	)
+	i.Observe(GatewayIdentify{})
+
+	expect := []string{"a:gateway.identify", "b:gateway.identify"}
+	if fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Fatalf("expected %v, got %v", expect, got)
+	}
+
+	// Observe must accept a nil Instrumenter.
+	Observe(nil, GatewayIdentify{})
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func TestLogger(t *testing.T) {
+	l := &testLogger{}
+	i := NewLogger(l)
+
+	i.Observe(RequestFinish{Method: "GET", Bucket: "/users/@me", Status: 200})
+	i.Observe(RequestFinish{Method: "GET", Status: 200, Retries: 1})
+	i.Observe(RequestFinish{Method: "GET", Err: errors.New("oh no")})
+	i.Observe(GatewayIdentify{Shard: [2]int{1, 2}})
+	i.Observe(GatewayError{Err: errors.New("oh no")})
+	i.Observe(CacheLookup{Kind: "guild", Hit: true})
+	i.Observe(StateError{Err: errors.New("oh no")})
+
+	levels := []string{"DEBUG", "WARN", "ERROR", "INFO", "ERROR", "DEBUG", "ERROR"}
+	if len(l.lines) != len(levels) {
+		t.Fatalf("expected %d lines, got %d: %q", len(levels), len(l.lines), l.lines)
+	}
+
+	for i, level := range levels {
+		if !strings.HasPrefix(l.lines[i], level+" ") {
+			t.Errorf("line %d: expected level %s, got %q", i, level, l.lines[i])
+		}
+	}
+
+	if expect := "INFO gateway.identify [shard 1 shards 2]"; l.lines[3] != expect {
+		t.Errorf("expected %q, got %q", expect, l.lines[3])
+	}
+}
+
+func TestLoggerRedactsTokens(t *testing.T) {
+	l := &testLogger{}
+	i := NewLogger(l)
+
+	i.Observe(RequestStart{
+		Method: "POST",
+		URL:    "https://discord.com/api/v9/webhooks/1/c2VjcmV0?wait=true",
+	})
+	i.Observe(RequestFinish{
+		Method: "POST",
+		URL:    "https://discord.com/api/v9/interactions/1/aW50ZXJhY3Rpb24/callback",
+	})
+
+	for _, line := range l.lines {
+		if strings.Contains(line, "c2VjcmV0") || strings.Contains(line, "aW50ZXJhY3Rpb24") {
+			t.Errorf("token is logged: %q", line)
+		}
+	}
+
+	if !strings.Contains(l.lines[0], "/webhooks/1/:token?wait=true") {
+		t.Errorf("unexpected webhook URL: %q", l.lines[0])
+	}
+	if !strings.Contains(l.lines[1], "/interactions/1/:token/callback") {
+		t.Errorf("unexpected interaction URL: %q", l.lines[1])
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := map[string]string{
+		"":                                       "",
+		"/users/@me":                             "/users/@me",
+		"/channels/123456789012345678/messages/": "/channels/:id/messages/",
+		"/guilds/1/members/":                     "/guilds/:id/members/",
+		"/webhooks/1/c2VjcmV0/messages/2":        "/webhooks/:id/:token/messages/:id",
+		"/webhooks/1/c2VjcmV0/messages/@original":  "/webhooks/:id/:token/messages/@original",
+		"/interactions/1/aW50ZXJhY3Rpb24/callback": "/interactions/:id/:token/callback",
+	}
+
+	for bucket, expect := range tests {
+		if got := Route(bucket); got != expect {
+			t.Errorf("Route(%q): expected %q, got %q", bucket, expect, got)
+		}
+	}
+
+	// The buckets of webhook and interaction routes keep their tokens.
+	paths := map[string]string{
+		"/webhooks/1/c2VjcmV0":                     "/webhooks//:token",
+		"/webhooks/1/c2VjcmV0/messages/2":          "/webhooks//:token/messages/:id",
+		"/interactions/1/aW50ZXJhY3Rpb24/callback": "/interactions//:token/callback",
+	}
+
+	for path, expect := range paths {
+		if got := Route(rate.ParseBucketKey(path)); got != expect {
+			t.Errorf("Route(ParseBucketKey(%q)): expected %q, got %q", path, expect, got)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics("")
+
+	m.Observe(RequestFinish{
+		Method:        "POST",
+		Bucket:        "/channels/1/messages",
+		Status:        200,
+		Retries:       2,
+		RateLimitWait: 500 * time.Millisecond,
+		Duration:      20 * time.Millisecond,
+	})
+	m.Observe(RequestFinish{Method: "GET", Bucket: "/users/@me", Err: errors.New("oh no")})
+	m.Observe(GatewayDial{})
+	m.Observe(GatewayDial{Err: errors.New("oh no")})
+	m.Observe(GatewayHeartbeat{Latency: 50 * time.Millisecond})
+	m.Observe(GatewayHeartbeat{Latency: 40 * time.Millisecond})
+	m.Observe(CacheLookup{Kind: "guild", Hit: true})
+	m.Observe(CacheLookup{Kind: "guild", Hit: false})
+	m.Observe(CacheLookup{Kind: "guild", Hit: true})
+	m.Observe(EventDispatch{Type: `WEIRD"EVENT`, Duration: time.Second})
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	out := rec.Body.String()
+
+	expect := []string{
+		"# TYPE arikawa_api_requests_total counter\n",
+		`arikawa_api_requests_total{method="GET",route="/users/@me",status="error"} 1` + "\n",
+		`arikawa_api_requests_total{method="POST",route="/channels/:id/messages",status="200"} 1` + "\n",
+		"# TYPE arikawa_api_request_duration_seconds histogram\n",
+		`arikawa_api_request_duration_seconds_bucket{method="POST",route="/channels/:id/messages",le="0.01"} 0` + "\n",
+		`arikawa_api_request_duration_seconds_bucket{method="POST",route="/channels/:id/messages",le="0.025"} 1` + "\n",
+		`arikawa_api_request_duration_seconds_bucket{method="POST",route="/channels/:id/messages",le="+Inf"} 1` + "\n",
+		`arikawa_api_request_duration_seconds_count{method="POST",route="/channels/:id/messages"} 1` + "\n",
+		`arikawa_api_request_retries_total{route="/channels/:id/messages"} 2` + "\n",
+		`arikawa_api_ratelimit_wait_seconds_total{route="/channels/:id/messages"} 0.5` + "\n",
+		`arikawa_gateway_dials_total{result="error"} 1` + "\n",
+		`arikawa_gateway_dials_total{result="ok"} 1` + "\n",
+		"arikawa_gateway_heartbeat_latency_seconds 0.04\n",
+		`arikawa_cache_lookups_total{kind="guild",result="hit"} 2` + "\n",
+		`arikawa_cache_lookups_total{kind="guild",result="miss"} 1` + "\n",
+		`arikawa_dispatch_duration_seconds_sum{event="WEIRD\"EVENT"} 1` + "\n",
+	}
+
+	for _, line := range expect {
+		if !strings.Contains(out, line) {
+			t.Errorf("missing %q in output:\n%s", line, out)
+		}
+	}
+
+	// Metrics without any series must not be written.
+	if strings.Contains(out, "gateway_identifies_total") {
+		t.Errorf("unexpected empty metric in output:\n%s", out)
+	}
+
+	var buf strings.Builder
+	n, err := m.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("failed to write metrics:", err)
+	}
+	if int(n) != buf.Len() || buf.String() != out {
+		t.Fatalf("WriteTo wrote %d bytes, inconsistent with ServeHTTP", n)
+	}
+}
@@ -0,0 +1,90 @@
+package instrument
+
+// Logger is a leveled logger taking alternating key-value pairs, such as
+// *slog.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type logger struct {
+	l Logger
+}
+
+// NewLogger returns an Instrumenter that logs every event into l. The message
+// is the event name. The tokens of webhook and interaction URLs are redacted.
+//
+// Failed requests, gateway errors and state errors are logged as errors,
+// requests that needed retries as warnings, connection changes as info and
+// everything else, including every request, dispatch and cache lookup, as
+// debug.
+func NewLogger(l Logger) Instrumenter {
+	return logger{l}
+}
+
+func (l logger) Observe(ev Event) {
+	msg := ev.EventName()
+
+	switch ev := ev.(type) {
+	case RequestStart:
+		l.l.Debug(msg,
+			"method", ev.Method, "url", redactURL(ev.URL), "bucket", ev.Bucket,
+			"attempt", ev.Attempt, "ratelimit_wait", ev.RateLimitWait)
+
+	case RequestFinish:
+		args := []interface{}{
+			"method", ev.Method, "url", redactURL(ev.URL), "bucket", ev.Bucket,
+			"status", ev.Status, "retries", ev.Retries,
+			"ratelimit_wait", ev.RateLimitWait, "duration", ev.Duration,
+		}
+
+		switch {
+		case ev.Err != nil:
+			l.l.Error(msg, append(args, "err", ev.Err)...)
+		case ev.Retries > 0:
+			l.l.Warn(msg, args...)
+		default:
+			l.l.Debug(msg, args...)
+		}
+
+	case GatewayDial:
+		if ev.Err != nil {
+			l.l.Error(msg, "attempt", ev.Attempt, "duration", ev.Duration, "err", ev.Err)
+		} else {
+			l.l.Info(msg, "attempt", ev.Attempt, "duration", ev.Duration)
+		}
+
+	case GatewayIdentify:
+		l.l.Info(msg, "shard", ev.Shard[0], "shards", ev.Shard[1])
+
+	case GatewayResume:
+		l.l.Info(msg, "session_id", ev.SessionID, "sequence", ev.Sequence)
+
+	case GatewayReconnect:
+		if ev.Err != nil {
+			l.l.Warn(msg, "err", ev.Err)
+		} else {
+			l.l.Info(msg)
+		}
+
+	case GatewayHeartbeat:
+		l.l.Debug(msg, "latency", ev.Latency)
+
+	case GatewayError:
+		l.l.Error(msg, "err", ev.Err)
+
+	case EventDispatch:
+		l.l.Debug(msg, "type", ev.Type, "duration", ev.Duration)
+
+	case CacheLookup:
+		l.l.Debug(msg, "kind", ev.Kind, "hit", ev.Hit)
+
+	case StateError:
+		l.l.Error(msg, "err", ev.Err)
+
+	default:
+		l.l.Debug(msg)
+	}
+}
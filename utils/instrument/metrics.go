@@ -0,0 +1,454 @@
+package instrument
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are the histogram buckets, in seconds, used for the
+// duration metrics of Metrics.
+var DefaultDurationBuckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10,
+}
+
+// Metrics is an Instrumenter that aggregates events into metrics and exports
+// them in the Prometheus text format. It is also an http.Handler serving the
+// metrics, so it can be mounted directly as the /metrics endpoint.
+//
+// REST requests are labeled by their route, which is the rate limit bucket
+// with snowflakes replaced by ":id" and webhook and interaction tokens
+// replaced by ":token" to keep the number of series bounded. See Route.
+type Metrics struct {
+	mutex    sync.Mutex
+	families []*family
+
+	requests      *family
+	requestTime   *family
+	retries       *family
+	rateLimitWait *family
+
+	dials      *family
+	identifies *family
+	resumes    *family
+	reconnects *family
+	gwErrors   *family
+	heartbeat  *family
+
+	dispatchTime *family
+	cache        *family
+	stateErrors  *family
+}
+
+var _ Instrumenter = (*Metrics)(nil)
+
+// NewMetrics creates a new Metrics instance. All metric names are prefixed
+// with the given namespace and an underscore; if namespace is empty, then
+// "arikawa" is used.
+func NewMetrics(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = "arikawa"
+	}
+
+	m := &Metrics{}
+
+	add := func(f *family) *family {
+		f.name = namespace + "_" + f.name
+		f.series = make(map[string]*series)
+		m.families = append(m.families, f)
+		return f
+	}
+
+	m.requests = add(&family{
+		name:   "api_requests_total",
+		help:   "Total number of REST requests.",
+		typ:    "counter",
+		labels: []string{"method", "route", "status"},
+	})
+	m.requestTime = add(&family{
+		name:    "api_request_duration_seconds",
+		help:    "Duration of REST requests, including retries and rate limits.",
+		typ:     "histogram",
+		labels:  []string{"method", "route"},
+		buckets: DefaultDurationBuckets,
+	})
+	m.retries = add(&family{
+		name:   "api_request_retries_total",
+		help:   "Total number of retried REST request attempts.",
+		typ:    "counter",
+		labels: []string{"route"},
+	})
+	m.rateLimitWait = add(&family{
+		name:   "api_ratelimit_wait_seconds_total",
+		help:   "Total time spent waiting for the rate limiter.",
+		typ:    "counter",
+		labels: []string{"route"},
+	})
+	m.dials = add(&family{
+		name:   "gateway_dials_total",
+		help:   "Total number of gateway dial attempts.",
+		typ:    "counter",
+		labels: []string{"result"},
+	})
+	m.identifies = add(&family{
+		name: "gateway_identifies_total",
+		help: "Total number of sessions identified.",
+		typ:  "counter",
+	})
+	m.resumes = add(&family{
+		name: "gateway_resumes_total",
+		help: "Total number of sessions resumed.",
+		typ:  "counter",
+	})
+	m.reconnects = add(&family{
+		name: "gateway_reconnects_total",
+		help: "Total number of gateway reconnections.",
+		typ:  "counter",
+	})
+	m.gwErrors = add(&family{
+		name: "gateway_errors_total",
+		help: "Total number of background gateway errors.",
+		typ:  "counter",
+	})
+	m.heartbeat = add(&family{
+		name: "gateway_heartbeat_latency_seconds",
+		help: "Latency of the last acknowledged heartbeat.",
+		typ:  "gauge",
+	})
+	m.dispatchTime = add(&family{
+		name:    "dispatch_duration_seconds",
+		help:    "Duration of dispatching gateway events to the handlers.",
+		typ:     "histogram",
+		labels:  []string{"event"},
+		buckets: DefaultDurationBuckets,
+	})
+	m.cache = add(&family{
+		name:   "cache_lookups_total",
+		help:   "Total number of state cache lookups.",
+		typ:    "counter",
+		labels: []string{"kind", "result"},
+	})
+	m.stateErrors = add(&family{
+		name: "state_errors_total",
+		help: "Total number of state cache errors.",
+		typ:  "counter",
+	})
+
+	return m
+}
+
+// Observe implements Instrumenter.
+func (m *Metrics) Observe(ev Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	switch ev := ev.(type) {
+	case RequestFinish:
+		route := Route(ev.Bucket)
+
+		status := "error"
+		if ev.Status > 0 {
+			status = strconv.Itoa(ev.Status)
+		}
+
+		m.requests.add(1, ev.Method, route, status)
+		m.requestTime.observe(ev.Duration.Seconds(), ev.Method, route)
+
+		if ev.Retries > 0 {
+			m.retries.add(float64(ev.Retries), route)
+		}
+		if ev.RateLimitWait > 0 {
+			m.rateLimitWait.add(ev.RateLimitWait.Seconds(), route)
+		}
+
+	case GatewayDial:
+		result := "ok"
+		if ev.Err != nil {
+			result = "error"
+		}
+		m.dials.add(1, result)
+
+	case GatewayIdentify:
+		m.identifies.add(1)
+	case GatewayResume:
+		m.resumes.add(1)
+	case GatewayReconnect:
+		m.reconnects.add(1)
+	case GatewayError:
+		m.gwErrors.add(1)
+	case GatewayHeartbeat:
+		m.heartbeat.set(ev.Latency.Seconds())
+
+	case EventDispatch:
+		m.dispatchTime.observe(ev.Duration.Seconds(), ev.Type)
+
+	case CacheLookup:
+		result := "miss"
+		if ev.Hit {
+			result = "hit"
+		}
+		m.cache.add(1, ev.Kind, result)
+
+	case StateError:
+		m.stateErrors.add(1)
+	}
+}
+
+// WriteTo writes all metrics in the Prometheus text format into w.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	cw := countWriter{w: w}
+	bw := bufio.NewWriter(&cw)
+
+	m.mutex.Lock()
+	for _, f := range m.families {
+		f.writeTo(bw)
+	}
+	m.mutex.Unlock()
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// Route returns the given rate limit bucket with all numeric path segments
+// replaced by ":id" and the tokens of webhook and interaction routes replaced
+// by ":token".
+func Route(bucket string) string {
+	if bucket == "" {
+		return ""
+	}
+
+	parts := strings.Split(bucket, "/")
+	redactTokens(parts)
+
+	for i, part := range parts {
+		if isID(part) {
+			parts[i] = ":id"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// redactURL returns the given URL with the tokens of webhook and interaction
+// routes replaced by ":token".
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ":invalid"
+	}
+
+	parts := strings.Split(u.Path, "/")
+	if redactTokens(parts) {
+		u.Path = strings.Join(parts, "/")
+		u.RawPath = ""
+	}
+
+	return u.String()
+}
+
+// tokenRoutes are the path segments followed by an ID and a token.
+var tokenRoutes = map[string]bool{
+	"webhooks":     true,
+	"interactions": true,
+}
+
+// tokenRouteParts are the path segments that may follow the token of a
+// webhook or interaction route. Every other segment is redacted.
+var tokenRouteParts = map[string]bool{
+	"messages":  true,
+	"callback":  true,
+	"slack":     true,
+	"github":    true,
+	"@original": true,
+}
+
+// redactTokens replaces the non-numeric path segments after the ID of webhook
+// and interaction routes, except the known ones, with ":token". It returns true
+// if any segment is replaced.
+func redactTokens(parts []string) bool {
+	var redacted bool
+
+	for i := 0; i+1 < len(parts); i++ {
+		// The ID is empty in the rate limit buckets of major parameters.
+		if !tokenRoutes[parts[i]] || (parts[i+1] != "" && !isID(parts[i+1])) {
+			continue
+		}
+
+		for j := i + 2; j < len(parts); j++ {
+			if parts[j] != "" && !isID(parts[j]) && !tokenRouteParts[parts[j]] {
+				parts[j] = ":token"
+				redacted = true
+			}
+		}
+
+		break
+	}
+
+	return redacted
+}
+
+func isID(part string) bool {
+	_, err := strconv.ParseUint(part, 10, 64)
+	return err == nil
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64 // histograms only
+
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+
+	// counters and gauges
+	value float64
+
+	// histograms
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (f *family) get(values []string) *series {
+	key := strings.Join(values, "\xff")
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: values}
+		if f.buckets != nil {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+func (f *family) add(v float64, values ...string) {
+	f.get(values).value += v
+}
+
+func (f *family) set(v float64, values ...string) {
+	f.get(values).value = v
+}
+
+func (f *family) observe(v float64, values ...string) {
+	s := f.get(values)
+	s.sum += v
+	s.count++
+
+	for i, le := range f.buckets {
+		if v <= le {
+			s.counts[i]++
+		}
+	}
+}
+
+func (f *family) writeTo(w *bufio.Writer) {
+	if len(f.series) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		labels := formatLabels(f.labels, s.values, "")
+
+		if f.buckets == nil {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatFloat(s.value))
+			continue
+		}
+
+		for i, le := range f.buckets {
+			bucketLabels := formatLabels(f.labels, s.values, formatFloat(le))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, bucketLabels, s.counts[i])
+		}
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.values, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, s.count)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats the label set. If le is not empty, then it's added as
+// the histogram bucket label.
+func formatLabels(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		labelEscaper.WriteString(&b, values[i])
+		b.WriteByte('"')
+	}
+
+	if le != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`le="`)
+		b.WriteString(le)
+		b.WriteByte('"')
+	}
+
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, +1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
@@ -6,7 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/diamondburned/arikawa/v3/internal/backoff"
 	"github.com/diamondburned/arikawa/v3/internal/lazytime"
+	"github.com/diamondburned/arikawa/v3/utils/instrument"
 	"github.com/diamondburned/arikawa/v3/utils/json"
 	"github.com/pkg/errors"
 )
@@ -54,10 +56,16 @@ func (err *BackgroundErrorEvent) EventType() EventType {
 // GatewayOpts describes the gateway event loop options.
 type GatewayOpts struct {
 	// ReconnectDelay determines the duration to idle after each failed retry.
-	// This can be used to implement exponential backoff. The default is already
-	// sane, so this field rarely needs to be changed.
+	// The default is an exponential backoff with jitter; see
+	// ExponentialBackoff. It rarely needs to be changed.
 	ReconnectDelay func(try int) time.Duration
 
+	// ZombieThreshold is the number of consecutive heartbeats that may go
+	// unacknowledged before the connection is considered zombied and is
+	// reconnected. It is used by Handlers that keep track of heartbeat
+	// acknowledgements. If it's 0, then 2 is used.
+	ZombieThreshold int
+
 	// FatalCloseCodes is a list of close codes that will cause the gateway to
 	// exit out if it stumbles on one of these. It is a copy of FatalCloseCodes
 	// (the global variable) by default.
@@ -80,15 +88,24 @@ type GatewayOpts struct {
 
 // DefaultGatewayOpts is the default event loop options.
 var DefaultGatewayOpts = GatewayOpts{
-	ReconnectDelay: func(try int) time.Duration {
-		// minimum 4 seconds
-		return time.Duration(4+(2*try)) * time.Second
-	},
+	ReconnectDelay:        ExponentialBackoff(4*time.Second, 2*time.Minute),
+	ZombieThreshold:       2,
 	DialTimeout:           0,
 	ReconnectAttempt:      0,
 	AlwaysCloseGracefully: true,
 }
 
+// ExponentialBackoff returns a ReconnectDelay function that starts at min and
+// doubles for every retry up to max. The delay of each retry is picked randomly
+// between min and that duration, so that many clients that lost their
+// connections at once don't all reconnect at the same time.
+func ExponentialBackoff(min, max time.Duration) func(try int) time.Duration {
+	b := backoff.NewBackoff(min, max)
+	return func(try int) time.Duration {
+		return b.ForAttempt(int32(try))
+	}
+}
+
 // Gateway describes an instance that handles the Discord gateway. It is
 // basically an abstracted concurrent event loop that the user could signal to
 // start connecting to the Discord gateway server.
@@ -101,6 +118,10 @@ type Gateway struct {
 	outer     outerState
 	lastError error
 
+	// reconnectErr is the last error seen since the last successful dial.
+	reconnectErr error
+	instrumenter instrument.Instrumenter
+
 	opts GatewayOpts
 }
 
@@ -142,7 +163,16 @@ func NewGateway(ws *Websocket, opts *GatewayOpts) *Gateway {
 	}
 }
 
-// Send is a function to send an Op payload to the Gateway.
+// SetInstrumenter sets the instrumenter that receives the dial, reconnect and
+// error events of the gateway. It panics if the gateway is running.
+func (g *Gateway) SetInstrumenter(i instrument.Instrumenter) {
+	g.AssertIsNotRunning()
+	g.instrumenter = i
+}
+
+// Send is a function to send an Op payload to the Gateway. Commands are sent in
+// the order of their priorities if they have to wait for the send rate limit;
+// see PrioritizedEvent.
 func (g *Gateway) Send(ctx context.Context, data Event) error {
 	op := Op{
 		Code: data.Op(),
@@ -158,7 +188,13 @@ func (g *Gateway) Send(ctx context.Context, data Event) error {
 	}
 
 	// WS should already be thread-safe.
-	return g.ws.Send(ctx, b)
+	return g.ws.SendWithPriority(ctx, b, EventSendPriority(data))
+}
+
+// SendQueueLen returns the number of commands that are waiting to be sent
+// because of the send rate limit.
+func (g *Gateway) SendQueueLen() int {
+	return g.ws.SendQueueLen()
 }
 
 // HasStarted returns true if the gateway event loop is currently spinning.
@@ -276,6 +312,20 @@ func (g *Gateway) SendError(err error) {
 		Data: event,
 	}
 	g.lastError = err
+	g.reconnectErr = err
+
+	instrument.Observe(g.instrumenter, instrument.GatewayError{Err: err})
+}
+
+// SendEvent sends the given event into the event channel, such as one of the
+// lifecycle events. Like SendError, it must only be called from the event
+// loop, which includes the Handler.
+func (g *Gateway) SendEvent(ev Event) {
+	g.outer.ch <- Op{
+		Code: ev.Op(),
+		Type: ev.EventType(),
+		Data: ev,
+	}
 }
 
 // SendErrorWrap is a convenient function over SendError.
@@ -306,12 +356,15 @@ func (g *Gateway) spin(ctx context.Context, h Handler) {
 
 			switch data := op.Data.(type) {
 			case *CloseEvent:
+				g.reconnectErr = data
+
 				for _, code := range g.opts.FatalCloseCodes {
 					if code == data.Code {
 						// Don't wrap the error, but instead, just pipe it as-is
 						// through the channel.
 						g.outer.ch <- op
 						g.lastError = data
+						g.SendEvent(&GaveUpEvent{Err: data})
 						return
 					}
 				}
@@ -336,6 +389,15 @@ func (g *Gateway) spin(ctx context.Context, h Handler) {
 				g.SendErrorWrap(err, "error closing before reconnecting")
 			}
 
+			reconnect := g.srcOp != nil
+
+			// Only signal a reconnection if we've been connected before.
+			if reconnect {
+				instrument.Observe(g.instrumenter, instrument.GatewayReconnect{
+					Err: g.reconnectErr,
+				})
+			}
+
 			// Invalidate our srcOp.
 			g.srcOp = nil
 
@@ -344,8 +406,20 @@ func (g *Gateway) spin(ctx context.Context, h Handler) {
 
 		retryLoop:
 			for try := 0; g.opts.ReconnectAttempt == 0 || try < g.opts.ReconnectAttempt; try++ {
+				g.SendEvent(&ConnectingEvent{Attempt: try, Reconnect: reconnect})
+
+				start := time.Now()
 				g.srcOp, err = g.ws.Dial(ctx)
+
+				instrument.Observe(g.instrumenter, instrument.GatewayDial{
+					Attempt:  try,
+					Duration: time.Since(start),
+					Err:      err,
+				})
+
 				if err == nil {
+					g.reconnectErr = nil
+					g.SendEvent(&ConnectedEvent{Attempt: try, Reconnect: reconnect})
 					break
 				}
 
@@ -371,6 +445,9 @@ func (g *Gateway) spin(ctx context.Context, h Handler) {
 			if g.srcOp == nil {
 				err = errors.Wrap(err, "failed to reconnect after max attempts")
 				g.SendError(ConnectionError{err})
+				if ctx.Err() == nil {
+					g.SendEvent(&GaveUpEvent{Err: err})
+				}
 				return
 			}
 		}
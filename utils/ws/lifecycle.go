@@ -0,0 +1,88 @@
+package ws
+
+import "time"
+
+// The lifecycle events below are sent into the event channel of the Gateway
+// alongside the events from the server, so that the user can follow the state
+// of the connection. Like BackgroundErrorEvent, their Op is -1.
+
+// ConnectingEvent is sent before every attempt to dial the websocket, including
+// the first one and the ones made to reconnect.
+type ConnectingEvent struct {
+	// Attempt is the attempt number of the dial, starting at 0 for each
+	// (re)connection.
+	Attempt int
+	// Reconnect is true if the gateway was connected before.
+	Reconnect bool
+}
+
+// ConnectedEvent is sent once the websocket has been dialed successfully. The
+// client still has to identify or resume after this.
+type ConnectedEvent struct {
+	// Attempt is the attempt number of the successful dial.
+	Attempt int
+	// Reconnect is true if the gateway was connected before.
+	Reconnect bool
+}
+
+// ResumedEvent is sent once a previous session has been resumed successfully.
+type ResumedEvent struct {
+	SessionID string
+	// Sequence is the sequence that the session was resumed from.
+	Sequence int64
+}
+
+// ZombiedEvent is sent if the server stops acknowledging heartbeats. The
+// connection is then considered zombied and is reconnected.
+type ZombiedEvent struct {
+	// Missed is the number of heartbeats that weren't acknowledged.
+	Missed int
+	// LastAck is the time that the last heartbeat was acknowledged at. It is
+	// zero if no heartbeat was ever acknowledged on the connection.
+	LastAck time.Time
+}
+
+// GaveUpEvent is sent if the gateway stops trying to reconnect, either because
+// it ran out of attempts or because it was closed with a fatal close code. The
+// event channel is closed right after.
+type GaveUpEvent struct {
+	Err error
+}
+
+var (
+	_ Event = (*ConnectingEvent)(nil)
+	_ Event = (*ConnectedEvent)(nil)
+	_ Event = (*ResumedEvent)(nil)
+	_ Event = (*ZombiedEvent)(nil)
+	_ Event = (*GaveUpEvent)(nil)
+)
+
+// Op implements Event. It returns -1.
+func (ev *ConnectingEvent) Op() OpCode { return -1 }
+
+// EventType implements Event. It returns an opaque unique string.
+func (ev *ConnectingEvent) EventType() EventType { return "__ws.ConnectingEvent" }
+
+// Op implements Event. It returns -1.
+func (ev *ConnectedEvent) Op() OpCode { return -1 }
+
+// EventType implements Event. It returns an opaque unique string.
+func (ev *ConnectedEvent) EventType() EventType { return "__ws.ConnectedEvent" }
+
+// Op implements Event. It returns -1.
+func (ev *ResumedEvent) Op() OpCode { return -1 }
+
+// EventType implements Event. It returns an opaque unique string.
+func (ev *ResumedEvent) EventType() EventType { return "__ws.ResumedEvent" }
+
+// Op implements Event. It returns -1.
+func (ev *ZombiedEvent) Op() OpCode { return -1 }
+
+// EventType implements Event. It returns an opaque unique string.
+func (ev *ZombiedEvent) EventType() EventType { return "__ws.ZombiedEvent" }
+
+// Op implements Event. It returns -1.
+func (ev *GaveUpEvent) Op() OpCode { return -1 }
+
+// EventType implements Event. It returns an opaque unique string.
+func (ev *GaveUpEvent) EventType() EventType { return "__ws.GaveUpEvent" }
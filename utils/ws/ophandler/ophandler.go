@@ -5,14 +5,19 @@ package ophandler
 import (
 	"context"
 
-	"github.com/diamondburned/arikawa/v3/utils/handler"
 	"github.com/diamondburned/arikawa/v3/utils/ws"
 )
 
+// Caller is the interface of what Loop distributes events to. It is usually a
+// *handler.Handler.
+type Caller interface {
+	Call(ev interface{})
+}
+
 // Loop starts a background goroutine that starts reading from src and
 // distributes received events into the given handler. It's stopped once src is
 // closed. The returned channel will be closed once src is closed.
-func Loop(src <-chan ws.Op, dst *handler.Handler) <-chan struct{} {
+func Loop(src <-chan ws.Op, dst Caller) <-chan struct{} {
 	done := make(chan struct{})
 	go func() {
 		for op := range src {
@@ -0,0 +1,97 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/json"
+)
+
+// Reader reads a recording. Both gzip-compressed and uncompressed recordings
+// are accepted.
+type Reader struct {
+	r      *bufio.Reader
+	header Header
+}
+
+// NewReader creates a new Reader that reads from r and reads the header of the
+// recording.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read recording")
+	}
+
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read gzip header")
+		}
+		br = bufio.NewReader(gz)
+	}
+
+	reader := &Reader{r: br}
+
+	line, err := reader.readLine()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read header")
+	}
+
+	if err := json.Unmarshal(line, &reader.header); err != nil {
+		return nil, errors.Wrap(err, "failed to decode header")
+	}
+
+	if reader.header.Version != Version {
+		return nil, errors.Errorf("unsupported recording version %d", reader.header.Version)
+	}
+
+	return reader, nil
+}
+
+// Header returns the header of the recording.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next reads the next Entry. It returns io.EOF once there are no more entries.
+func (r *Reader) Next() (Entry, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var e entryLine
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Entry{}, errors.Wrap(err, "failed to decode entry")
+	}
+
+	e.Entry.At = time.Duration(e.At) * time.Microsecond
+	return e.Entry, nil
+}
+
+// readLine reads the next non-empty line.
+func (r *Reader) readLine() ([]byte, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+
+		if len(line) > 0 {
+			// Allow the last line to not have a trailing newline.
+			return line, nil
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, errors.Wrap(err, "failed to read line")
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package record records gateway Ops into files and replays them. It is
+// mostly useful for debugging and for testing handlers and the state against
+// real-world traffic without connecting to Discord.
+//
+// Recordings are gzip-compressed JSON lines. The first line is a Header, and
+// every other line is an Entry, which is the Op as it was sent by Discord with
+// an additional "at" field containing the time since the start of the
+// recording in microseconds.
+//
+// Usage
+//
+// To record a gateway, pass its Op channel through Tee:
+//
+//    rec, err := record.NewRecorder(f)
+//    if err != nil {
+//        return err
+//    }
+//    defer rec.Close()
+//
+//    for op := range rec.Tee(g.Connect(ctx)) {
+//        // handle op
+//    }
+//
+// To replay a recording into a State, give Replay the handler that the State
+// hooks itself into:
+//
+//    r, err := record.NewReader(f)
+//    if err != nil {
+//        return err
+//    }
+//
+//    codec := ws.NewCodec(gateway.OpUnmarshalers)
+//    err = record.Replay(ctx, r, codec, s.Session.Handler, 10)
+//
+// By default, Ops are re-encoded from their decoded events, which drops the
+// fields that arikawa doesn't know of. If ws.EnableRawEvents is true, then the
+// raw payloads are recorded instead.
+package record
+
+import (
+	"compress/gzip"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+// Version is the version of the recording format written by Recorder.
+const Version = 1
+
+// Header is the first line of a recording.
+type Header struct {
+	// Version is the version of the recording format.
+	Version int `json:"version"`
+	// Start is the time the recording was started.
+	Start time.Time `json:"start"`
+}
+
+// Entry is a single recorded Op.
+type Entry struct {
+	// At is the time since the start of the recording.
+	At time.Duration `json:"-"`
+
+	Code     ws.OpCode    `json:"op"`
+	Type     ws.EventType `json:"t,omitempty"`
+	Sequence int64        `json:"s,omitempty"`
+	Data     json.Raw     `json:"d,omitempty"`
+}
+
+// entryLine is the JSON line of an Entry.
+type entryLine struct {
+	At int64 `json:"at"` // µs
+	Entry
+}
+
+// Recorder writes Ops into a recording. All its methods are thread-safe.
+type Recorder struct {
+	mu sync.Mutex
+	gz *gzip.Writer
+
+	start time.Time
+	err   error
+
+	// skip is true if the last Op was a RawEvent, in which case the decoded
+	// Op following it is already recorded.
+	skip bool
+}
+
+// NewRecorder creates a new Recorder that writes into w and writes the header
+// of the recording. Close must be called to flush the recording; it doesn't
+// close w.
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	r := &Recorder{
+		gz:    gzip.NewWriter(w),
+		start: time.Now(),
+	}
+
+	if err := r.writeLine(Header{Version: Version, Start: r.start}); err != nil {
+		return nil, errors.Wrap(err, "failed to write header")
+	}
+
+	return r, nil
+}
+
+// Record records the given Op. Internal Ops, such as errors, are ignored.
+// Once writing fails, all subsequent calls return the same error.
+func (r *Recorder) Record(op ws.Op) error {
+	at := time.Since(r.start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if raw, ok := op.Data.(*ws.RawEvent); ok {
+		r.skip = true
+		return r.writeEntry(Entry{
+			At:       at,
+			Code:     raw.OriginalCode,
+			Type:     raw.OriginalType,
+			Sequence: op.Sequence,
+			Data:     raw.Raw,
+		})
+	}
+
+	if op.Code < 0 {
+		r.skip = false
+		return nil
+	}
+
+	if r.skip {
+		r.skip = false
+		return nil
+	}
+
+	e := Entry{
+		At:       at,
+		Code:     op.Code,
+		Type:     op.Type,
+		Sequence: op.Sequence,
+	}
+
+	if op.Data != nil {
+		b, err := json.Marshal(op.Data)
+		if err != nil {
+			// Events that can't be marshaled shouldn't stop the recording.
+			return errors.Wrapf(err, "failed to marshal op %d %s", op.Code, op.Type)
+		}
+		e.Data = b
+	}
+
+	return r.writeEntry(e)
+}
+
+// WriteEntry writes the given Entry as-is. It is useful to copy recordings.
+func (r *Recorder) WriteEntry(e Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.writeEntry(e)
+}
+
+func (r *Recorder) writeEntry(e Entry) error {
+	if err := r.writeLine(entryLine{At: e.At.Microseconds(), Entry: e}); err != nil {
+		r.err = errors.Wrap(err, "failed to write entry")
+		return r.err
+	}
+	return nil
+}
+
+func (r *Recorder) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.gz.Write(append(b, '\n'))
+	return err
+}
+
+// Tee records all Ops received from src and sends them into the returned
+// channel, which is closed once src is closed. Errors are sent into the
+// returned channel as BackgroundErrorEvents.
+func (r *Recorder) Tee(src <-chan ws.Op) <-chan ws.Op {
+	dst := make(chan ws.Op)
+	go func() {
+		defer close(dst)
+
+		for op := range src {
+			if err := r.Record(op); err != nil {
+				ev := &ws.BackgroundErrorEvent{Err: err}
+				dst <- ws.Op{Code: ev.Op(), Type: ev.EventType(), Data: ev}
+			}
+			dst <- op
+		}
+	}()
+	return dst
+}
+
+// Close flushes the recording. It does not close the underlying writer.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil {
+		return errors.Wrap(err, "failed to flush recording")
+	}
+
+	return r.err
+}
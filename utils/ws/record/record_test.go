@@ -0,0 +1,187 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/state/store/defaultstore"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+func newOp(seq int64, ev ws.Event) ws.Op {
+	return ws.Op{
+		Code:     ev.Op(),
+		Type:     ev.EventType(),
+		Sequence: seq,
+		Data:     ev,
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatal("failed to create recorder:", err)
+	}
+
+	src := make(chan ws.Op)
+	dst := rec.Tee(src)
+
+	go func() {
+		src <- newOp(0, &gateway.HelloEvent{HeartbeatInterval: 41250})
+		src <- newOp(1, &gateway.ReadyEvent{
+			User:      discord.User{ID: 1, Username: "bot"},
+			SessionID: "session",
+			Guilds: []gateway.GuildCreateEvent{
+				{Guild: discord.Guild{ID: 2, Name: "guild"}},
+			},
+		})
+		// Internal ops must not be recorded.
+		src <- newOp(0, &ws.CloseEvent{Code: -1})
+		src <- newOp(2, &gateway.MessageCreateEvent{
+			Message: discord.Message{
+				ID:        4,
+				ChannelID: 3,
+				GuildID:   2,
+				Content:   "hello",
+			},
+		})
+		close(src)
+	}()
+
+	var n int
+	for range dst {
+		n++
+	}
+	if n != 4 {
+		t.Fatalf("expected 4 ops from Tee, got %d", n)
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatal("failed to close recorder:", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal("failed to create reader:", err)
+	}
+
+	if r.Header().Version != Version {
+		t.Fatalf("unexpected version %d", r.Header().Version)
+	}
+
+	s := state.NewWithStore("", defaultstore.New())
+
+	var content string
+	s.AddSyncHandler(func(ev *gateway.MessageCreateEvent) {
+		content = ev.Content
+	})
+
+	codec := ws.NewCodec(gateway.OpUnmarshalers)
+	if err := Replay(context.Background(), r, codec, s.Session.Handler, 0); err != nil {
+		t.Fatal("failed to replay:", err)
+	}
+
+	if content != "hello" {
+		t.Fatalf("expected message content %q, got %q", "hello", content)
+	}
+
+	me, err := s.Me()
+	if err != nil || me.Username != "bot" {
+		t.Fatalf("unexpected me %v: %v", me, err)
+	}
+
+	g, err := s.Cabinet.Guild(2)
+	if err != nil || g.Name != "guild" {
+		t.Fatalf("unexpected guild %v: %v", g, err)
+	}
+}
+
+func TestRecordRawEvents(t *testing.T) {
+	var buf bytes.Buffer
+
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatal("failed to create recorder:", err)
+	}
+
+	raw := `{"heartbeat_interval":41250,"unknown_field":true}`
+
+	rec.Record(ws.Op{
+		Code: -1,
+		Data: &ws.RawEvent{Raw: []byte(raw), OriginalCode: 10},
+	})
+	rec.Record(newOp(0, &gateway.HelloEvent{HeartbeatInterval: 41250}))
+
+	if err := rec.Close(); err != nil {
+		t.Fatal("failed to close recorder:", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal("failed to create reader:", err)
+	}
+
+	e, err := r.Next()
+	if err != nil {
+		t.Fatal("failed to read entry:", err)
+	}
+
+	if e.Code != 10 || string(e.Data) != raw {
+		t.Fatalf("unexpected entry %d %s", e.Code, e.Data)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatal("expected EOF after raw entry, got", err)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	recording := strings.Join([]string{
+		`{"version":1,"start":"2021-01-01T00:00:00Z"}`,
+		`{"at":0,"op":11}`,
+		`{"at":1000000,"op":11}`,
+		`{"at":2000000,"op":1337}`,
+	}, "\n")
+
+	r, err := NewReader(strings.NewReader(recording))
+	if err != nil {
+		t.Fatal("failed to create reader:", err)
+	}
+
+	var events []interface{}
+	h := handler.New()
+	h.AddSyncHandler(func(ev interface{}) { events = append(events, ev) })
+
+	start := time.Now()
+
+	codec := ws.NewCodec(gateway.OpUnmarshalers)
+	if err := Replay(context.Background(), r, codec, h, 100); err != nil {
+		t.Fatal("failed to replay:", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("replay at 100x of 2s took %v", elapsed)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	if _, ok := events[0].(*gateway.HeartbeatAckEvent); !ok {
+		t.Errorf("expected HeartbeatAckEvent, got %T", events[0])
+	}
+
+	if _, ok := events[2].(*ws.BackgroundErrorEvent); !ok {
+		t.Errorf("expected BackgroundErrorEvent for unknown op, got %T", events[2])
+	}
+}
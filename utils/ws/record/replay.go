@@ -0,0 +1,78 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+	"github.com/diamondburned/arikawa/v3/utils/ws/ophandler"
+)
+
+// Replay reads all entries from r, decodes them using codec the same way
+// ws.Conn does and calls dst with the decoded events. Entries that can't be
+// decoded are given to dst as BackgroundErrorEvents, like they would be when
+// connected.
+//
+// The original timing is kept if speed is 1. Higher speeds replay faster; for
+// example, 10 replays 10 times as fast. If speed is 0 or lower, then all
+// entries are replayed without waiting.
+//
+// Replay returns nil once all entries are replayed.
+func Replay(ctx context.Context, r *Reader, codec ws.Codec, dst ophandler.Caller, speed float64) error {
+	// The codec may send a RawEvent before the event itself.
+	opCh := make(chan ws.Op, 2)
+	buf := ws.NewDecodeBuffer(1 << 14) // 16KB
+	start := time.Now()
+
+	for {
+		e, err := r.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if speed > 0 {
+			at := time.Duration(float64(e.At) / speed)
+			if err := sleep(ctx, time.Until(start.Add(at))); err != nil {
+				return err
+			}
+		}
+
+		b, err := json.Marshal(e)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode entry")
+		}
+
+		if err := codec.DecodeInto(ctx, bytes.NewReader(b), &buf, opCh); err != nil {
+			return err
+		}
+
+		for len(opCh) > 0 {
+			dst.Call((<-opCh).Data)
+		}
+	}
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,192 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// SendPriority is the priority of an outgoing command. Commands with a lower
+// SendPriority are sent first; commands with the same priority are sent in the
+// order that they were queued.
+type SendPriority int
+
+const (
+	// SendPriorityCritical is the priority of commands that keep the
+	// connection alive, such as heartbeats, identifies and resumes. These
+	// commands skip the queue and are rate limited by their own ReservedSends
+	// budget, so that other commands can never delay them.
+	SendPriorityCritical SendPriority = iota
+	// SendPriorityHigh is the priority of commands that should be sent before
+	// the others, such as voice state updates.
+	SendPriorityHigh
+	// SendPriorityNormal is the priority of all other commands, such as member
+	// requests and presence updates.
+	SendPriorityNormal
+)
+
+// PrioritizedEvent is an Event that is sent with a SendPriority other than
+// SendPriorityNormal.
+type PrioritizedEvent interface {
+	Event
+	SendPriority() SendPriority
+}
+
+// EventSendPriority returns the SendPriority of the given event.
+func EventSendPriority(ev Event) SendPriority {
+	if p, ok := ev.(PrioritizedEvent); ok {
+		return p.SendPriority()
+	}
+	return SendPriorityNormal
+}
+
+// sendQueue hands out the send rate limit to commands in the order of their
+// priorities.
+type sendQueue struct {
+	mu       sync.Mutex
+	limiter  *rate.Limiter
+	reserved *rate.Limiter
+	waiters  []*sendWaiter // sorted by priority, then by arrival
+}
+
+type sendWaiter struct {
+	priority SendPriority
+	// wake is signaled if the waiter becomes the head of the queue or if it
+	// loses its place as the head.
+	wake chan struct{}
+}
+
+func newSendQueue() *sendQueue {
+	return &sendQueue{
+		limiter:  NewSendLimiter(),
+		reserved: NewReservedSendLimiter(),
+	}
+}
+
+// reset resets the rate limits, which is done for every new connection.
+func (q *sendQueue) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.limiter = NewSendLimiter()
+	q.reserved = NewReservedSendLimiter()
+
+	// Make the head wait for the new limiter instead.
+	if len(q.waiters) > 0 {
+		q.waiters[0].signal()
+	}
+}
+
+// len returns the number of commands waiting in the queue.
+func (q *sendQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.waiters)
+}
+
+// wait blocks until a command with the given priority may be sent or until ctx
+// expires.
+func (q *sendQueue) wait(ctx context.Context, priority SendPriority) error {
+	if priority <= SendPriorityCritical {
+		q.mu.Lock()
+		reserved := q.reserved
+		q.mu.Unlock()
+
+		return reserved.Wait(ctx)
+	}
+
+	w := &sendWaiter{
+		priority: priority,
+		wake:     make(chan struct{}, 1),
+	}
+
+	q.push(w)
+	defer q.remove(w)
+
+	for {
+		q.mu.Lock()
+		head := q.waiters[0] == w
+		limiter := q.limiter
+		q.mu.Unlock()
+
+		if !head {
+			select {
+			case <-w.wake:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		r := limiter.Reserve()
+
+		delay := r.Delay()
+		if delay == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(delay)
+
+		select {
+		case <-t.C:
+			return nil
+		case <-w.wake:
+			// Give the token back to whoever took our place.
+			t.Stop()
+			r.Cancel()
+		case <-ctx.Done():
+			t.Stop()
+			r.Cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// push adds the waiter after all waiters with the same or a higher priority.
+func (q *sendQueue) push(w *sendWaiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := len(q.waiters)
+	for i > 0 && q.waiters[i-1].priority > w.priority {
+		i--
+	}
+
+	q.waiters = append(q.waiters, nil)
+	copy(q.waiters[i+1:], q.waiters[i:])
+	q.waiters[i] = w
+
+	if i == 0 && len(q.waiters) > 1 {
+		// Preempt the previous head.
+		q.waiters[1].signal()
+	}
+}
+
+// remove removes the waiter and wakes up the next head.
+func (q *sendQueue) remove(w *sendWaiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, waiter := range q.waiters {
+		if waiter != w {
+			continue
+		}
+
+		q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+
+		if i == 0 && len(q.waiters) > 0 {
+			q.waiters[0].signal()
+		}
+		return
+	}
+}
+
+func (w *sendWaiter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
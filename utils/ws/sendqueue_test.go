@@ -0,0 +1,109 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func newTestSendQueue() *sendQueue {
+	// Allow a send every 20ms with no burst, so that sends after the first
+	// one have to queue.
+	return &sendQueue{
+		limiter:  rate.NewLimiter(rate.Every(20*time.Millisecond), 1),
+		reserved: rate.NewLimiter(rate.Every(time.Hour), 1),
+	}
+}
+
+func waitForQueueLen(t *testing.T, q *sendQueue, n int) {
+	t.Helper()
+
+	for i := 0; q.len() != n; i++ {
+		if i == 100 {
+			t.Fatalf("expected %d queued, got %d", n, q.len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSendQueuePriority(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	q := newTestSendQueue()
+
+	// Take the only token.
+	if err := q.wait(ctx, SendPriorityNormal); err != nil {
+		t.Fatal("failed to wait:", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	send := func(name string, p SendPriority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := q.wait(ctx, p); err != nil {
+				t.Error("failed to wait:", err)
+				return
+			}
+
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}()
+	}
+
+	send("normal 1", SendPriorityNormal)
+	waitForQueueLen(t, q, 1)
+	send("normal 2", SendPriorityNormal)
+	waitForQueueLen(t, q, 2)
+	send("high", SendPriorityHigh)
+	waitForQueueLen(t, q, 3)
+
+	// Critical commands don't wait for the queue.
+	if err := q.wait(ctx, SendPriorityCritical); err != nil {
+		t.Fatal("failed to wait for critical:", err)
+	}
+	if q.len() != 3 {
+		t.Fatalf("expected 3 queued, got %d", q.len())
+	}
+
+	wg.Wait()
+
+	expect := []string{"high", "normal 1", "normal 2"}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("expected order %v, got %v", expect, order)
+		}
+	}
+}
+
+func TestSendQueueCancel(t *testing.T) {
+	q := newTestSendQueue()
+
+	if err := q.wait(context.Background(), SendPriorityNormal); err != nil {
+		t.Fatal("failed to wait:", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- q.wait(ctx, SendPriorityNormal) }()
+
+	waitForQueueLen(t, q, 1)
+	cancel()
+
+	if err := <-errCh; err != context.Canceled {
+		t.Fatal("expected context.Canceled, got", err)
+	}
+	if q.len() != 0 {
+		t.Fatal("cancelled send is still queued")
+	}
+}
@@ -6,8 +6,28 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// SendLimit is the number of commands that may be sent in any minute on a
+// connection. Discord closes connections that send more.
+const SendLimit = 120
+
+// ReservedSends is the number of sends in SendLimit that are reserved for
+// commands with SendPriorityCritical, such as heartbeats. Other commands share
+// the rest.
+const ReservedSends = 10
+
+// NewSendLimiter creates the limiter shared by commands that aren't critical.
+// It allows at most SendLimit-ReservedSends commands in any minute: a burst of
+// all but one of them, then one every minute.
 func NewSendLimiter() *rate.Limiter {
-	return rate.NewLimiter(rate.Every(time.Minute), 120)
+	return rate.NewLimiter(rate.Every(time.Minute), SendLimit-ReservedSends-1)
+}
+
+// NewReservedSendLimiter creates the limiter of commands with
+// SendPriorityCritical. It allows at most ReservedSends commands in any minute:
+// a burst of half of them, then the other half spread over the minute, so that
+// heartbeats can be sent indefinitely.
+func NewReservedSendLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Minute/(ReservedSends/2)), ReservedSends/2)
 }
 
 func NewDialLimiter() *rate.Limiter {
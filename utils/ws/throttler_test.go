@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendLimiters(t *testing.T) {
+	limiter := NewSendLimiter()
+	reserved := NewReservedSendLimiter()
+
+	// Send as much as both limiters allow for a few minutes.
+	start := time.Now()
+	var sends []time.Time
+
+	for now := start; now.Before(start.Add(3 * time.Minute)); now = now.Add(100 * time.Millisecond) {
+		for limiter.AllowN(now, 1) {
+			sends = append(sends, now)
+		}
+		for reserved.AllowN(now, 1) {
+			sends = append(sends, now)
+		}
+	}
+
+	// Count the sends in the minute from every send on.
+	for i, from := range sends {
+		var n int
+		for _, sent := range sends[i:] {
+			if sent.Sub(from) > time.Minute {
+				break
+			}
+			n++
+		}
+
+		if n > SendLimit {
+			t.Fatalf("%d sends in the minute from %v, expected at most %d",
+				n, from.Sub(start), SendLimit)
+		}
+	}
+
+	// Heartbeats must never be starved by the reserved limiter.
+	if perMinute := reserved.Limit() * 60; perMinute < 2 {
+		t.Fatalf("reserved limiter refills only %v sends per minute", perMinute)
+	}
+}
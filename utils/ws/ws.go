@@ -30,7 +30,7 @@ type Websocket struct {
 	// open an issue. It might be worth it to refactor these out for distributed
 	// sharding.
 
-	sendLimiter *rate.Limiter
+	sendQueue   *sendQueue
 	dialLimiter *rate.Limiter
 }
 
@@ -45,7 +45,7 @@ func NewCustomWebsocket(conn Connection, addr string) *Websocket {
 		conn: conn,
 		addr: addr,
 
-		sendLimiter: NewSendLimiter(),
+		sendQueue:   newSendQueue(),
 		dialLimiter: NewDialLimiter(),
 	}
 }
@@ -60,26 +60,33 @@ func (ws *Websocket) Dial(ctx context.Context) (<-chan Op, error) {
 	ws.mutex.Lock()
 	defer ws.mutex.Unlock()
 
-	// Reset the send limiter.
-	// TODO: see if each limit only applies to one connection or not.
-	ws.sendLimiter = NewSendLimiter()
+	// Reset the send limiters, since they apply to each connection.
+	ws.sendQueue.reset()
 
 	return ws.conn.Dial(ctx, ws.addr)
 }
 
-// Send sends b over the Websocket with a deadline. It closes the internal
-// Websocket if the Send method errors out.
+// Send sends b over the Websocket with a deadline and SendPriorityNormal. It
+// closes the internal Websocket if the Send method errors out.
 func (ws *Websocket) Send(ctx context.Context, b []byte) error {
+	return ws.SendWithPriority(ctx, b, SendPriorityNormal)
+}
+
+// SendWithPriority is like Send, but b waits for the send rate limiter behind
+// all queued commands with the same or a higher priority. Commands with
+// SendPriorityCritical don't wait for other commands at all. If ctx expires
+// while b is queued, then b is removed from the queue and ctx's error is
+// returned.
+func (ws *Websocket) SendWithPriority(ctx context.Context, b []byte, p SendPriority) error {
 	WSDebug("Acquiring the websocket mutex for sending.")
 
 	ws.mutex.Lock()
-	sendLimiter := ws.sendLimiter
 	conn := ws.conn
 	ws.mutex.Unlock()
 
 	WSDebug("Waiting for the send rate limiter...")
 
-	if err := sendLimiter.Wait(ctx); err != nil {
+	if err := ws.sendQueue.wait(ctx, p); err != nil {
 		WSDebug("Send rate limiter timed out.")
 		return errors.Wrap(err, "SendLimiter failed")
 	}
@@ -89,6 +96,12 @@ func (ws *Websocket) Send(ctx context.Context, b []byte) error {
 	return conn.Send(ctx, b)
 }
 
+// SendQueueLen returns the number of commands that are waiting for the send
+// rate limiter.
+func (ws *Websocket) SendQueueLen() int {
+	return ws.sendQueue.len()
+}
+
 // Close closes the websocket connection. It assumes that the Websocket is
 // closed even when it returns an error. If the Websocket was already closed
 // before, ErrWebsocketClosed will be returned.
@@ -0,0 +1,262 @@
+package voice
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+)
+
+// ErrNoSession is returned by Manager when there is no voice session for the
+// given guild.
+var ErrNoSession = errors.New("no voice session for guild")
+
+// Manager manages multiple voice sessions, one for each guild. It routes the
+// voice events from the main gateway to the right session and tears sessions
+// down when they can no longer be used, such as when the guild is deleted or
+// becomes unavailable, or when the main gateway reconnects with a new session.
+//
+// A Manager instance is thread-safe.
+type Manager struct {
+	state *state.State
+
+	// ErrorLog is called with errors that happen when a session is torn down
+	// in the background. It defaults to a no-op.
+	ErrorLog func(error)
+
+	mut      sync.Mutex
+	sessions map[discord.GuildID]*managedSession
+	detach   []func()
+}
+
+// managedSession is a voice session managed by a Manager.
+type managedSession struct {
+	*Session
+	guild *guildSession
+}
+
+// guildSession wraps around a State to implement MainSession. It only routes
+// events of its guild into the voice session's handlers.
+type guildSession struct {
+	*state.State
+	handler *handler.Handler
+}
+
+var _ MainSession = (*guildSession)(nil)
+
+// AddHandler adds the handler into the guild's own handler instead of the
+// State's.
+func (g *guildSession) AddHandler(h interface{}) (rm func()) {
+	return g.handler.AddHandler(h)
+}
+
+// NewManager creates a new voice session manager on top of the given state.
+// The state should have the needed intents; see AddIntents.
+func NewManager(s *state.State) *Manager {
+	m := &Manager{
+		state:    s,
+		ErrorLog: func(error) {},
+		sessions: make(map[discord.GuildID]*managedSession),
+	}
+
+	m.detach = []func(){
+		s.AddHandler(m.onVoiceServerUpdate),
+		s.AddHandler(m.onVoiceStateUpdate),
+		s.AddHandler(m.onGuildDelete),
+		s.AddHandler(m.onReady),
+	}
+
+	return m
+}
+
+// Join joins the voice channel with the given ID in the given guild. If the
+// guild doesn't have a voice session yet, then a new one is created;
+// otherwise, the existing session is moved to the given channel.
+func (m *Manager) Join(
+	ctx context.Context, guildID discord.GuildID, channelID discord.ChannelID) (*Session, error) {
+
+	ch, err := m.state.Channel(channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid channel ID")
+	}
+
+	if ch.GuildID != guildID {
+		return nil, errors.New("channel is not in the given guild")
+	}
+
+	m.mut.Lock()
+	ms, ok := m.sessions[guildID]
+	if !ok {
+		guild := &guildSession{
+			State:   m.state,
+			handler: handler.New(),
+		}
+
+		session, err := NewSession(guild)
+		if err != nil {
+			m.mut.Unlock()
+			return nil, err
+		}
+
+		ms = &managedSession{
+			Session: session,
+			guild:   guild,
+		}
+		m.sessions[guildID] = ms
+	}
+	m.mut.Unlock()
+
+	if err := ms.JoinChannel(ctx, channelID, false, false); err != nil {
+		// Only forget the session if we just made it.
+		if !ok {
+			m.remove(guildID, ms)
+		}
+		return nil, err
+	}
+
+	return ms.Session, nil
+}
+
+// Leave leaves the voice channel in the given guild and forgets its session.
+// ErrNoSession is returned if the guild has no voice session.
+func (m *Manager) Leave(ctx context.Context, guildID discord.GuildID) error {
+	m.mut.Lock()
+	ms, ok := m.sessions[guildID]
+	if ok {
+		delete(m.sessions, guildID)
+	}
+	m.mut.Unlock()
+
+	if !ok {
+		return ErrNoSession
+	}
+
+	return ms.Leave(ctx)
+}
+
+// Get returns the voice session of the given guild, or nil if there is none.
+func (m *Manager) Get(guildID discord.GuildID) *Session {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	ms, ok := m.sessions[guildID]
+	if !ok {
+		return nil
+	}
+
+	return ms.Session
+}
+
+// Sessions returns a copy of all the voice sessions, keyed by guild ID.
+func (m *Manager) Sessions() map[discord.GuildID]*Session {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	sessions := make(map[discord.GuildID]*Session, len(m.sessions))
+	for id, ms := range m.sessions {
+		sessions[id] = ms.Session
+	}
+
+	return sessions
+}
+
+// Close leaves all voice sessions and detaches the Manager from the State. The
+// Manager must not be used after Close is called.
+func (m *Manager) Close(ctx context.Context) error {
+	m.mut.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[discord.GuildID]*managedSession)
+
+	for _, detach := range m.detach {
+		detach()
+	}
+	m.detach = nil
+	m.mut.Unlock()
+
+	var lastErr error
+	for _, ms := range sessions {
+		if err := ms.Leave(ctx); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// remove removes the session of the given guild only if it's still the given
+// session.
+func (m *Manager) remove(guildID discord.GuildID, ms *managedSession) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.sessions[guildID] == ms {
+		delete(m.sessions, guildID)
+	}
+}
+
+func (m *Manager) guild(guildID discord.GuildID) *guildSession {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	ms, ok := m.sessions[guildID]
+	if !ok {
+		return nil
+	}
+
+	return ms.guild
+}
+
+func (m *Manager) onVoiceServerUpdate(ev *gateway.VoiceServerUpdateEvent) {
+	if guild := m.guild(ev.GuildID); guild != nil {
+		guild.handler.Call(ev)
+	}
+}
+
+func (m *Manager) onVoiceStateUpdate(ev *gateway.VoiceStateUpdateEvent) {
+	if guild := m.guild(ev.GuildID); guild != nil {
+		guild.handler.Call(ev)
+	}
+}
+
+// onGuildDelete tears down the session of a guild that the bot was removed
+// from or that became unavailable.
+func (m *Manager) onGuildDelete(ev *gateway.GuildDeleteEvent) {
+	m.mut.Lock()
+	ms, ok := m.sessions[ev.ID]
+	if ok {
+		delete(m.sessions, ev.ID)
+	}
+	m.mut.Unlock()
+
+	if ok {
+		m.teardown(ms)
+	}
+}
+
+// onReady tears down all sessions. A Ready event means that the main gateway
+// has started a new session instead of resuming, so Discord has already
+// dropped all of our voice connections.
+func (m *Manager) onReady(*gateway.ReadyEvent) {
+	m.mut.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[discord.GuildID]*managedSession)
+	m.mut.Unlock()
+
+	for _, ms := range sessions {
+		m.teardown(ms)
+	}
+}
+
+func (m *Manager) teardown(ms *managedSession) {
+	ctx, cancel := context.WithTimeout(context.Background(), ms.WSTimeout)
+	defer cancel()
+
+	if err := ms.Leave(ctx); err != nil {
+		m.ErrorLog(errors.Wrap(err, "failed to tear down voice session"))
+	}
+}
@@ -0,0 +1,77 @@
+package voice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/handler"
+)
+
+func newTestManager(t *testing.T, guildIDs ...discord.GuildID) (*state.State, *Manager) {
+	s := state.NewAPIOnlyState("Bot token", handler.New())
+	m := NewManager(s)
+
+	for _, guildID := range guildIDs {
+		guild := &guildSession{State: s, handler: handler.New()}
+		m.sessions[guildID] = &managedSession{
+			Session: NewSessionCustom(guild, 1),
+			guild:   guild,
+		}
+	}
+
+	return s, m
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for !cond() {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for condition")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestManagerRoute(t *testing.T) {
+	s, m := newTestManager(t, 100, 200)
+
+	ch := make(chan *gateway.VoiceServerUpdateEvent, 1)
+	m.sessions[200].guild.AddHandler(ch)
+
+	// Events for other guilds must not be routed.
+	s.Handler.Call(&gateway.VoiceServerUpdateEvent{GuildID: 100})
+	s.Handler.Call(&gateway.VoiceServerUpdateEvent{GuildID: 200, Token: "hi"})
+
+	select {
+	case ev := <-ch:
+		if ev.GuildID != 200 || ev.Token != "hi" {
+			t.Fatalf("unexpected event routed: %#v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for routed event")
+	}
+}
+
+func TestManagerGuildDelete(t *testing.T) {
+	s, m := newTestManager(t, 100, 200)
+
+	s.Handler.Call(&gateway.GuildDeleteEvent{ID: 100, Unavailable: true})
+	waitFor(t, func() bool { return m.Get(100) == nil })
+
+	if m.Get(200) == nil {
+		t.Fatal("unrelated guild session was torn down")
+	}
+}
+
+func TestManagerReady(t *testing.T) {
+	s, m := newTestManager(t, 100, 200)
+
+	s.Handler.Call(&gateway.ReadyEvent{})
+	waitFor(t, func() bool { return len(m.Sessions()) == 0 })
+}
@@ -0,0 +1,338 @@
+// Package mixer provides an audio mixer that allows multiple sources to be
+// played at once into a single voice session.
+//
+// A voice.Session can only be written to by one goroutine at a time, since each
+// Write call is one Opus frame. The Mixer solves this by giving each producer
+// its own Source, which is an io.Writer, and writing a single paced stream of
+// frames into the session.
+//
+// PCM sources are mixed together and encoded using the given Encoder. When
+// only a single Opus source is playing, its frames are passed through as-is
+// without being decoded or encoded again.
+package mixer
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SampleRate is the sample rate of the PCM that the Mixer works with.
+	SampleRate = 48000
+	// Channels is the number of interleaved channels of the PCM that the
+	// Mixer works with.
+	Channels = 2
+	// DefaultFrameSize is the default number of samples per channel of each
+	// frame, which is 20ms.
+	DefaultFrameSize = 960
+)
+
+// silenceFrame is an Opus frame of silence. Discord recommends sending 5 of
+// these when audio stops to avoid unintended interpolation.
+var silenceFrame = []byte{0xF8, 0xFF, 0xFE}
+
+// ErrNoEncoder is returned by Run if a PCM source has to be played, but the
+// Mixer has no Encoder.
+var ErrNoEncoder = errors.New("mixer has no encoder for PCM sources")
+
+// ErrMixerStopped is returned by Source's Write if the Mixer has stopped.
+var ErrMixerStopped = errors.New("mixer stopped")
+
+// Encoder encodes PCM frames into Opus frames.
+type Encoder interface {
+	// Encode encodes a single frame of interleaved 16-bit PCM samples and
+	// appends the Opus frame into dst.
+	Encode(dst []byte, pcm []int16) ([]byte, error)
+}
+
+// Decoder decodes Opus frames into PCM frames.
+type Decoder interface {
+	// Decode decodes a single Opus frame and appends the interleaved 16-bit
+	// PCM samples into dst.
+	Decode(dst []int16, opus []byte) ([]int16, error)
+}
+
+// Mixer mixes multiple sources into a single stream of Opus frames. A Mixer
+// instance is thread-safe.
+type Mixer struct {
+	w   io.Writer
+	enc Encoder
+
+	// Decoder is used to mix Opus sources with other sources. If it's nil,
+	// then Opus sources are held back while other sources are playing, and
+	// are only played when they're the only one left.
+	Decoder Decoder
+	// FrameSize is the number of samples per channel of each frame. It must
+	// match the frame duration of the voice connection and must not be
+	// changed after Run is called.
+	FrameSize int // DefaultFrameSize
+	// DuckVolume is the volume multiplier applied to other sources while a
+	// ducking source is playing.
+	DuckVolume float64 // 0.25
+
+	mut     sync.Mutex
+	sources []*Source
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// New creates a new Mixer that writes Opus frames into w, which is usually a
+// *voice.Session. The encoder may be nil if only Opus sources are used.
+func New(w io.Writer, enc Encoder) *Mixer {
+	return &Mixer{
+		w:          w,
+		enc:        enc,
+		FrameSize:  DefaultFrameSize,
+		DuckVolume: 0.25,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+}
+
+// NewSource creates a new PCM source. Writes into the source are interleaved
+// stereo 16-bit little-endian PCM at 48kHz, and they can be of any length.
+func (m *Mixer) NewSource() *Source {
+	return m.addSource(false)
+}
+
+// NewOpusSource creates a new Opus source. Each write into the source must be
+// exactly one Opus frame, just like writing into a voice.Session.
+func (m *Mixer) NewOpusSource() *Source {
+	return m.addSource(true)
+}
+
+func (m *Mixer) addSource(opus bool) *Source {
+	s := &Source{
+		mixer:  m,
+		opus:   opus,
+		frames: make(chan []byte, 1),
+		closed: make(chan struct{}),
+		volume: math.Float64bits(1),
+	}
+
+	m.mut.Lock()
+	m.sources = append(m.sources, s)
+	m.mut.Unlock()
+
+	return s
+}
+
+func (m *Mixer) removeSource(s *Source) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	for i, source := range m.sources {
+		if source == s {
+			m.sources = append(m.sources[:i], m.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+// notify wakes up Run if it's waiting for frames.
+func (m *Mixer) notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// frame is a single frame taken from a source.
+type frame struct {
+	source *Source
+	data   []byte
+}
+
+// Run runs the Mixer until ctx is done or the writer fails. Write is called
+// once per frame, and it's expected to block to pace the stream, which
+// voice.Session does. Once Run returns, all writes into the sources fail with
+// ErrMixerStopped.
+func (m *Mixer) Run(ctx context.Context) error {
+	defer close(m.done)
+
+	var (
+		frames  []frame
+		pcm     []int16
+		decoded []int16
+		encoded []byte
+		playing bool
+	)
+
+	for {
+		frames = m.takeFrames(frames[:0])
+
+		if len(frames) == 0 {
+			if playing {
+				// Mark the end of the audio.
+				playing = false
+				for i := 0; i < 5; i++ {
+					if _, err := m.w.Write(silenceFrame); err != nil {
+						return errors.Wrap(err, "failed to write silence")
+					}
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-m.wake:
+				continue
+			}
+		}
+
+		playing = true
+
+		if len(frames) == 1 && frames[0].source.opus {
+			// Passthrough; don't bother decoding and encoding.
+			if _, err := m.w.Write(frames[0].data); err != nil {
+				return errors.Wrap(err, "failed to write Opus frame")
+			}
+			continue
+		}
+
+		if m.enc == nil {
+			return ErrNoEncoder
+		}
+
+		if cap(pcm) < m.FrameSize*Channels {
+			pcm = make([]int16, m.FrameSize*Channels)
+		}
+		pcm = pcm[:m.FrameSize*Channels]
+
+		var err error
+		decoded, err = m.mix(pcm, frames, decoded)
+		if err != nil {
+			return err
+		}
+
+		encoded, err = m.enc.Encode(encoded[:0], pcm)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode frame")
+		}
+
+		if _, err := m.w.Write(encoded); err != nil {
+			return errors.Wrap(err, "failed to write mixed frame")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
+// takeFrames takes the next frame from each source that has one ready.
+func (m *Mixer) takeFrames(frames []frame) []frame {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	for _, s := range m.sources {
+		if s.held != nil {
+			frames = append(frames, frame{s, s.held})
+			s.held = nil
+			continue
+		}
+
+		select {
+		case data := <-s.frames:
+			frames = append(frames, frame{s, data})
+		default:
+		}
+	}
+
+	if m.Decoder != nil || len(frames) < 2 {
+		return frames
+	}
+
+	// We can't mix Opus frames without a decoder, so hold them back until
+	// they're the only ones left.
+	var hasPCM bool
+	for _, f := range frames {
+		if !f.source.opus {
+			hasPCM = true
+			break
+		}
+	}
+
+	if !hasPCM {
+		// Only play the first Opus source and hold back the rest.
+		for _, f := range frames[1:] {
+			f.source.held = f.data
+		}
+		return frames[:1]
+	}
+
+	pcmFrames := frames[:0]
+	for _, f := range frames {
+		if f.source.opus {
+			f.source.held = f.data
+		} else {
+			pcmFrames = append(pcmFrames, f)
+		}
+	}
+
+	return pcmFrames
+}
+
+// mix mixes the given frames into pcm. The scratch buffer for decoding is
+// returned to be reused.
+func (m *Mixer) mix(pcm []int16, frames []frame, decoded []int16) ([]int16, error) {
+	var ducking bool
+	for _, f := range frames {
+		if f.source.Ducking() {
+			ducking = true
+			break
+		}
+	}
+
+	mixed := make([]float64, len(pcm))
+
+	for _, f := range frames {
+		volume := f.source.Volume()
+		if ducking && !f.source.Ducking() {
+			volume *= m.DuckVolume
+		}
+
+		if f.source.opus {
+			var err error
+			decoded, err = m.Decoder.Decode(decoded[:0], f.data)
+			if err != nil {
+				return decoded, errors.Wrap(err, "failed to decode Opus frame")
+			}
+
+			for i := 0; i < len(mixed) && i < len(decoded); i++ {
+				mixed[i] += float64(decoded[i]) * volume
+			}
+			continue
+		}
+
+		for i := range mixed {
+			sample := int16(binary.LittleEndian.Uint16(f.data[i*2:]))
+			mixed[i] += float64(sample) * volume
+		}
+	}
+
+	for i, sample := range mixed {
+		pcm[i] = clamp(sample)
+	}
+
+	return decoded, nil
+}
+
+func clamp(sample float64) int16 {
+	switch {
+	case sample > math.MaxInt16:
+		return math.MaxInt16
+	case sample < math.MinInt16:
+		return math.MinInt16
+	default:
+		return int16(sample)
+	}
+}
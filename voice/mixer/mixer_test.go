@@ -0,0 +1,167 @@
+package mixer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pcmEncoder "encodes" PCM by marshaling it as little-endian bytes.
+type pcmEncoder struct{}
+
+func (pcmEncoder) Encode(dst []byte, pcm []int16) ([]byte, error) {
+	for _, sample := range pcm {
+		dst = append(dst, byte(sample), byte(sample>>8))
+	}
+	return dst, nil
+}
+
+// frameWriter records all written frames.
+type frameWriter struct {
+	mut    sync.Mutex
+	frames [][]byte
+	notify chan struct{}
+}
+
+func newFrameWriter() *frameWriter {
+	return &frameWriter{notify: make(chan struct{}, 100)}
+}
+
+func (w *frameWriter) Write(b []byte) (int, error) {
+	w.mut.Lock()
+	w.frames = append(w.frames, append([]byte(nil), b...))
+	w.mut.Unlock()
+
+	w.notify <- struct{}{}
+	return len(b), nil
+}
+
+func (w *frameWriter) wait(t *testing.T, n int) [][]byte {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-w.notify:
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for frame %d", i)
+		}
+	}
+
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return w.frames
+}
+
+func pcmFrame(m *Mixer, sample int16) []byte {
+	b := make([]byte, m.FrameSize*Channels*2)
+	for i := 0; i < len(b); i += 2 {
+		binary.LittleEndian.PutUint16(b[i:], uint16(sample))
+	}
+	return b
+}
+
+func runMixer(t *testing.T, m *Mixer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestPassthrough(t *testing.T) {
+	w := newFrameWriter()
+	m := New(w, nil)
+	runMixer(t, m)
+
+	src := m.NewOpusSource()
+	defer src.Close()
+
+	if _, err := src.Write([]byte("opus")); err != nil {
+		t.Fatal("failed to write:", err)
+	}
+
+	frames := w.wait(t, 1)
+	if !bytes.Equal(frames[0], []byte("opus")) {
+		t.Fatalf("unexpected frame %q", frames[0])
+	}
+
+	// Silence is sent once the source stops.
+	frames = w.wait(t, 5)
+	if !bytes.Equal(frames[5], silenceFrame) {
+		t.Fatalf("expected silence, got %q", frames[5])
+	}
+}
+
+func TestMixDucking(t *testing.T) {
+	w := newFrameWriter()
+	m := New(w, pcmEncoder{})
+	m.FrameSize = 4
+
+	music := m.NewSource()
+	music.SetVolume(0.5)
+
+	voice := m.NewSource()
+	voice.SetDucking(true)
+
+	// Fill both sources before running the mixer, so that they're mixed in
+	// the same frame.
+	go music.Write(pcmFrame(m, 1000))
+	go voice.Write(pcmFrame(m, 100))
+
+	for len(music.frames) == 0 || len(voice.frames) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	runMixer(t, m)
+
+	frames := w.wait(t, 1)
+	// 1000 * 0.5 * 0.25 + 100
+	expect := int16(225)
+
+	if sample := int16(binary.LittleEndian.Uint16(frames[0])); sample != expect {
+		t.Fatalf("expected mixed sample %d, got %d", expect, sample)
+	}
+}
+
+func TestOpusHeldBack(t *testing.T) {
+	w := newFrameWriter()
+	m := New(w, pcmEncoder{})
+	m.FrameSize = 1
+
+	pcm := m.NewSource()
+	opus := m.NewOpusSource()
+
+	go pcm.Write(pcmFrame(m, 7))
+	go opus.Write([]byte("opus"))
+
+	for len(pcm.frames) == 0 || len(opus.frames) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	runMixer(t, m)
+
+	frames := w.wait(t, 2)
+	if !bytes.Equal(frames[0], pcmFrame(m, 7)) {
+		t.Fatalf("expected PCM frame first, got %q", frames[0])
+	}
+	if !bytes.Equal(frames[1], []byte("opus")) {
+		t.Fatalf("expected held Opus frame second, got %q", frames[1])
+	}
+}
+
+func TestSourceClosed(t *testing.T) {
+	m := New(newFrameWriter(), nil)
+
+	src := m.NewSource()
+	src.Close()
+
+	if _, err := src.Write(pcmFrame(m, 0)); err != ErrSourceClosed {
+		t.Fatalf("expected ErrSourceClosed, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package mixer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/internal/moreatomic"
+)
+
+// ErrSourceClosed is returned by Source's Write if the source is closed.
+var ErrSourceClosed = errors.New("mixer source closed")
+
+// Source is a single audio source in a Mixer. Each Source should only be
+// written to by one goroutine, but different sources can be written to
+// concurrently. Writes block until the Mixer has taken the frames, so they are
+// paced the same way writing into a voice.Session is.
+type Source struct {
+	volume uint64 // atomic math.Float64bits; first for alignment
+
+	mixer *Mixer
+	opus  bool
+
+	frames chan []byte
+	held   []byte // guarded by mixer.mut
+
+	// pending is the PCM that doesn't yet make up a whole frame.
+	pending []byte
+
+	ducking moreatomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Write writes audio into the source. For a PCM source, b can be of any
+// length; for an Opus source, b must be exactly one Opus frame.
+func (s *Source) Write(b []byte) (int, error) {
+	if s.opus {
+		frame := append([]byte(nil), b...)
+		if err := s.send(frame); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	frameBytes := s.mixer.FrameSize * Channels * 2
+
+	s.pending = append(s.pending, b...)
+
+	for len(s.pending) >= frameBytes {
+		frame := append([]byte(nil), s.pending[:frameBytes]...)
+		if err := s.send(frame); err != nil {
+			return 0, err
+		}
+
+		s.pending = s.pending[:copy(s.pending, s.pending[frameBytes:])]
+	}
+
+	return len(b), nil
+}
+
+func (s *Source) send(frame []byte) error {
+	select {
+	case <-s.closed:
+		return ErrSourceClosed
+	case <-s.mixer.done:
+		return ErrMixerStopped
+	default:
+	}
+
+	select {
+	case s.frames <- frame:
+		s.mixer.notify()
+		return nil
+	case <-s.closed:
+		return ErrSourceClosed
+	case <-s.mixer.done:
+		return ErrMixerStopped
+	}
+}
+
+// Close removes the source from the mixer. Frames that were not yet played
+// are discarded, and all future writes fail with ErrSourceClosed.
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.mixer.removeSource(s)
+	})
+	return nil
+}
+
+// SetVolume sets the volume multiplier of the source. 1 is the original
+// volume. This is ignored for Opus sources that are passed through.
+func (s *Source) SetVolume(volume float64) {
+	atomic.StoreUint64(&s.volume, math.Float64bits(volume))
+}
+
+// Volume returns the volume multiplier of the source.
+func (s *Source) Volume() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.volume))
+}
+
+// SetDucking sets whether or not the source ducks other sources. When a
+// ducking source is playing, the volume of all other non-ducking sources is
+// lowered by the Mixer's DuckVolume. This is useful for announcements over
+// music.
+func (s *Source) SetDucking(ducking bool) {
+	s.ducking.Set(ducking)
+}
+
+// Ducking returns true if the source ducks other sources.
+func (s *Source) Ducking() bool {
+	return s.ducking.Get()
+}
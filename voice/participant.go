@@ -0,0 +1,175 @@
+package voice
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/voice/voicegateway"
+)
+
+// Participant is a user connected to the same voice channel as the session,
+// along with the SSRCs of the streams that they send.
+type Participant struct {
+	UserID    discord.UserID
+	AudioSSRC uint32
+	VideoSSRC uint32
+	RTXSSRC   uint32
+	Streams   []voicegateway.VideoStream
+	// Speaking is the last known speaking flag of the participant.
+	Speaking voicegateway.SpeakingFlag
+}
+
+// HasSSRC returns true if any of the participant's streams uses the given
+// SSRC.
+func (p Participant) HasSSRC(ssrc uint32) bool {
+	if ssrc == 0 {
+		return false
+	}
+
+	if p.AudioSSRC == ssrc || p.VideoSSRC == ssrc || p.RTXSSRC == ssrc {
+		return true
+	}
+
+	for _, stream := range p.Streams {
+		if stream.SSRC == ssrc || stream.RTXSSRC == ssrc {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParticipantJoinEvent is emitted into Session's handler when a user is first
+// seen in the voice channel, either through a ClientConnect or a Speaking
+// event.
+type ParticipantJoinEvent struct {
+	Participant
+}
+
+// ParticipantUpdateEvent is emitted into Session's handler when a known
+// participant's SSRCs or speaking state change.
+type ParticipantUpdateEvent struct {
+	Participant
+}
+
+// ParticipantLeaveEvent is emitted into Session's handler when a participant
+// disconnects from the voice channel.
+type ParticipantLeaveEvent struct {
+	Participant
+}
+
+// roster keeps track of the participants of a voice session. It is
+// thread-safe.
+type roster struct {
+	mut   sync.RWMutex
+	users map[discord.UserID]*Participant
+}
+
+// update updates the roster from the given voice gateway event. It returns an
+// event to be dispatched if the roster changed, or nil if it didn't.
+func (r *roster) update(ev interface{}) interface{} {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	switch ev := ev.(type) {
+	case *voicegateway.SpeakingEvent:
+		if !ev.UserID.IsValid() {
+			return nil
+		}
+
+		p, isNew := r.get(ev.UserID)
+		if !isNew && p.AudioSSRC == ev.SSRC && p.Speaking == ev.Speaking {
+			return nil
+		}
+
+		p.AudioSSRC = ev.SSRC
+		p.Speaking = ev.Speaking
+
+		return r.changed(p, isNew)
+
+	case *voicegateway.ClientConnectEvent:
+		if !ev.UserID.IsValid() {
+			return nil
+		}
+
+		p, isNew := r.get(ev.UserID)
+		// Discord may send a zero audio SSRC once the user only changes their
+		// video streams, so don't override a known SSRC with that.
+		if ev.AudioSSRC != 0 {
+			p.AudioSSRC = ev.AudioSSRC
+		}
+		p.VideoSSRC = ev.VideoSSRC
+		p.RTXSSRC = ev.RTXSSRC
+		p.Streams = append([]voicegateway.VideoStream(nil), ev.Streams...)
+
+		return r.changed(p, isNew)
+
+	case *voicegateway.ClientDisconnectEvent:
+		p, ok := r.users[ev.UserID]
+		if !ok {
+			return nil
+		}
+
+		delete(r.users, ev.UserID)
+		return &ParticipantLeaveEvent{*p}
+	}
+
+	return nil
+}
+
+// get gets the participant with the given user ID, creating a new one if it
+// doesn't exist yet. It does not acquire the mutex.
+func (r *roster) get(userID discord.UserID) (p *Participant, isNew bool) {
+	if r.users == nil {
+		r.users = make(map[discord.UserID]*Participant)
+	}
+
+	p, ok := r.users[userID]
+	if !ok {
+		p = &Participant{UserID: userID}
+		r.users[userID] = p
+	}
+
+	return p, !ok
+}
+
+func (r *roster) changed(p *Participant, isNew bool) interface{} {
+	if isNew {
+		return &ParticipantJoinEvent{*p}
+	}
+	return &ParticipantUpdateEvent{*p}
+}
+
+// reset clears the roster.
+func (r *roster) reset() {
+	r.mut.Lock()
+	r.users = nil
+	r.mut.Unlock()
+}
+
+// all returns a copy of all participants.
+func (r *roster) all() map[discord.UserID]Participant {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	participants := make(map[discord.UserID]Participant, len(r.users))
+	for id, p := range r.users {
+		participants[id] = *p
+	}
+
+	return participants
+}
+
+// bySSRC looks up the participant that owns the given SSRC.
+func (r *roster) bySSRC(ssrc uint32) (Participant, bool) {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	for _, p := range r.users {
+		if p.HasSSRC(ssrc) {
+			return *p, true
+		}
+	}
+
+	return Participant{}, false
+}
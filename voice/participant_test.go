@@ -0,0 +1,76 @@
+package voice
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/voice/voicegateway"
+)
+
+func TestRoster(t *testing.T) {
+	var r roster
+
+	ev := r.update(&voicegateway.ClientConnectEvent{
+		UserID:    1,
+		AudioSSRC: 100,
+		VideoSSRC: 101,
+		Streams: []voicegateway.VideoStream{
+			{Type: "video", RID: "100", SSRC: 102, RTXSSRC: 103},
+		},
+	})
+	if join, ok := ev.(*ParticipantJoinEvent); !ok || join.UserID != 1 {
+		t.Fatalf("expected join event, got %#v", ev)
+	}
+
+	for _, ssrc := range []uint32{100, 101, 102, 103} {
+		p, ok := r.bySSRC(ssrc)
+		if !ok || p.UserID != 1 {
+			t.Errorf("SSRC %d not found in roster", ssrc)
+		}
+	}
+
+	// A second user is first seen through Speaking.
+	ev = r.update(&voicegateway.SpeakingEvent{
+		UserID:   2,
+		SSRC:     200,
+		Speaking: voicegateway.Microphone,
+	})
+	if _, ok := ev.(*ParticipantJoinEvent); !ok {
+		t.Fatalf("expected join event, got %#v", ev)
+	}
+
+	// Repeated Speaking events without changes are not emitted.
+	ev = r.update(&voicegateway.SpeakingEvent{
+		UserID:   2,
+		SSRC:     200,
+		Speaking: voicegateway.Microphone,
+	})
+	if ev != nil {
+		t.Fatalf("expected no event, got %#v", ev)
+	}
+
+	ev = r.update(&voicegateway.SpeakingEvent{
+		UserID:   1,
+		SSRC:     100,
+		Speaking: voicegateway.Microphone,
+	})
+	if update, ok := ev.(*ParticipantUpdateEvent); !ok || update.VideoSSRC != 101 {
+		t.Fatalf("expected update event keeping the video SSRC, got %#v", ev)
+	}
+
+	if n := len(r.all()); n != 2 {
+		t.Fatalf("expected 2 participants, got %d", n)
+	}
+
+	ev = r.update(&voicegateway.ClientDisconnectEvent{UserID: 1})
+	if leave, ok := ev.(*ParticipantLeaveEvent); !ok || leave.AudioSSRC != 100 {
+		t.Fatalf("expected leave event, got %#v", ev)
+	}
+
+	if _, ok := r.bySSRC(100); ok {
+		t.Fatal("SSRC of disconnected user still found")
+	}
+
+	if ev := r.update(&voicegateway.ClientDisconnectEvent{UserID: 1}); ev != nil {
+		t.Fatalf("expected no event for unknown user, got %#v", ev)
+	}
+}
@@ -48,6 +48,12 @@ func (e ReconnectError) Error() string {
 // Unwrap returns e.Err.
 func (e ReconnectError) Unwrap() error { return e.Err }
 
+// StatsEvent is emitted into Session.Handler every StatsInterval while the
+// session is connected. It contains the statistics of the UDP connection.
+type StatsEvent struct {
+	udp.Stats
+}
+
 // MainSession abstracts both session.Session and state.State.
 type MainSession interface {
 	// AddHandler describes the method in handler.Handler.
@@ -78,6 +84,8 @@ type Session struct {
 
 	state voicegateway.State // guarded except UserID
 
+	roster roster
+
 	detachReconnect []func()
 
 	// udpManager is the manager for a UDP connection. The user can use this to
@@ -93,6 +101,10 @@ type Session struct {
 	WSRetryDelay   time.Duration // 2s
 	WSWaitDuration time.Duration // 5s
 
+	// StatsInterval is the interval between each StatsEvent. If it's 0, then
+	// no StatsEvent is emitted.
+	StatsInterval time.Duration // 5s
+
 	// joining determines the behavior of incoming event callbacks (Update).
 	// If this is true, incoming events will just send into Updated channels. If
 	// false, events will trigger a reconnection.
@@ -129,6 +141,7 @@ func NewSessionCustom(ses MainSession, userID discord.UserID) *Session {
 		WSMaxRetry:     2,
 		WSRetryDelay:   2 * time.Second,
 		WSWaitDuration: 5 * time.Second,
+		StatsInterval:  5 * time.Second,
 
 		// Set this pair of value in so we never have to nil-check the channel.
 		// We can just assume that it's either closed or connected.
@@ -136,6 +149,8 @@ func NewSessionCustom(ses MainSession, userID discord.UserID) *Session {
 		disconnectClosed: true,
 	}
 
+	session.udpManager.SetDeadHandler(session.redialUDP)
+
 	return session
 }
 
@@ -145,6 +160,14 @@ func (s *Session) SetUDPDialer(d udp.DialFunc) {
 	s.udpManager.SetDialer(d)
 }
 
+// SetUDPKeepalive sets the interval between UDP keepalive packets and the
+// duration without receiving anything after which the UDP connection is
+// considered dead and is redialed. A zero timeout disables redialing. The
+// defaults are udp.DefaultKeepaliveInterval and udp.DefaultDeadTimeout.
+func (s *Session) SetUDPKeepalive(interval, deadTimeout time.Duration) {
+	s.udpManager.SetKeepalive(interval, deadTimeout)
+}
+
 func (s *Session) acquireUpdate(f func()) bool {
 	if s.joining.Get() {
 		return false
@@ -391,6 +414,9 @@ func (s *Session) reconnectCtx(ctx context.Context) error {
 
 	s.ensureClosed()
 
+	// The participants will be sent again by the new gateway.
+	s.roster.reset()
+
 	ws.WSDebug("Start gateway.")
 	s.gateway = voicegateway.New(s.state)
 
@@ -414,7 +440,11 @@ func (s *Session) reconnectCtx(ctx context.Context) error {
 	}
 
 	// Start dispatching.
-	s.gwDone = ophandler.Loop(gwch, s.Handler)
+	s.gwDone = ophandler.Loop(gwch, sessionCaller{s})
+
+	if s.StatsInterval > 0 {
+		go s.statsLoop(gwctx, s.StatsInterval)
+	}
 
 	ws.WSDebug("Voice reconnectCtx finished with no error")
 
@@ -447,15 +477,8 @@ func (s *Session) spinGateway(ctx context.Context, gwch <-chan ws.Op) error {
 					return errors.Wrap(err, "failed to open voice UDP connection")
 				}
 
-				if err := s.gateway.Send(ctx, &voicegateway.SelectProtocolCommand{
-					Protocol: "udp",
-					Data: voicegateway.SelectProtocolData{
-						Address: conn.GatewayIP,
-						Port:    conn.GatewayPort,
-						Mode:    Protocol,
-					},
-				}); err != nil {
-					return errors.Wrap(err, "failed to send SelectProtocolCommand")
+				if err := s.selectProtocol(ctx, conn); err != nil {
+					return err
 				}
 
 			case *voicegateway.SessionDescriptionEvent:
@@ -471,11 +494,141 @@ func (s *Session) spinGateway(ctx context.Context, gwch <-chan ws.Op) error {
 			}
 
 			// Dispatch this event to the handler.
-			s.Handler.Call(ev.Data)
+			s.dispatch(ev.Data)
+		}
+	}
+}
+
+func (s *Session) selectProtocol(ctx context.Context, conn *udp.Connection) error {
+	err := s.gateway.Send(ctx, &voicegateway.SelectProtocolCommand{
+		Protocol: "udp",
+		Data: voicegateway.SelectProtocolData{
+			Address: conn.GatewayIP,
+			Port:    conn.GatewayPort,
+			Mode:    Protocol,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to send SelectProtocolCommand")
+	}
+	return nil
+}
+
+// redialUDP is called by the UDP manager when the UDP connection is detected
+// to be dead. It redials the connection and selects the protocol again on the
+// existing voice gateway.
+func (s *Session) redialUDP() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.gateway == nil || s.udpManager.IsClosed() {
+		return
+	}
+
+	ready := s.gateway.Ready()
+	if ready == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.WSTimeout)
+	defer cancel()
+
+	ws.WSDebug("Redialing dead voice UDP connection")
+
+	if err := s.redialUDPCtx(ctx, ready); err != nil {
+		ws.WSDebug("Voice UDP redial error:", err)
+		s.Handler.Call(&ReconnectError{err})
+	}
+}
+
+func (s *Session) redialUDPCtx(ctx context.Context, ready *voicegateway.ReadyEvent) error {
+	descCh := make(chan *voicegateway.SessionDescriptionEvent)
+	rm := s.Handler.AddHandler(descCh)
+	defer rm()
+
+	if err := s.udpManager.Pause(ctx); err != nil {
+		return errors.Wrap(err, "cannot pause UDP manager")
+	}
+
+	conn, err := s.udpManager.Dial(ctx, ready.Addr(), ready.SSRC)
+	if err != nil {
+		// Dial already unpauses on error.
+		return errors.Wrap(err, "failed to redial voice UDP connection")
+	}
+	defer s.udpManager.Continue()
+
+	if err := s.selectProtocol(ctx, conn); err != nil {
+		return err
+	}
+
+	select {
+	case desc := <-descCh:
+		conn.UseSecret(desc.SecretKey)
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "cannot wait for SessionDescription")
+	}
+}
+
+// sessionCaller dispatches the events of the gateway through the session, so
+// that the participants are updated along with them.
+type sessionCaller struct {
+	session *Session
+}
+
+func (c sessionCaller) Call(ev interface{}) {
+	c.session.dispatch(ev)
+}
+
+// dispatch updates the participants using the given event, then calls the
+// handler with it, followed by any participant event.
+func (s *Session) dispatch(ev interface{}) {
+	change := s.roster.update(ev)
+
+	s.Handler.Call(ev)
+
+	if change != nil {
+		s.Handler.Call(change)
+	}
+}
+
+// statsLoop emits a StatsEvent every interval until ctx is done.
+func (s *Session) statsLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, err := s.udpManager.Stats()
+			if err != nil {
+				continue
+			}
+			s.Handler.Call(&StatsEvent{stats})
 		}
 	}
 }
 
+// Stats returns the current statistics of the UDP connection.
+func (s *Session) Stats() (udp.Stats, error) {
+	return s.udpManager.Stats()
+}
+
+// Participants returns a copy of all the known participants in the voice
+// channel, keyed by their user IDs. A participant is known once Discord sends
+// either a ClientConnect or a Speaking event for them.
+func (s *Session) Participants() map[discord.UserID]Participant {
+	return s.roster.all()
+}
+
+// ParticipantBySSRC returns the participant that owns the given audio, video
+// or RTX SSRC. It is useful to know who a received packet belongs to.
+func (s *Session) ParticipantBySSRC(ssrc uint32) (Participant, bool) {
+	return s.roster.bySSRC(ssrc)
+}
+
 // Speaking tells Discord we're speaking. This method should not be called
 // concurrently.
 //
@@ -516,6 +669,7 @@ func (s *Session) Leave(ctx context.Context) error {
 	defer s.mut.Unlock()
 
 	s.ensureClosed()
+	s.roster.reset()
 
 	// Unbind the handlers.
 	if s.detachReconnect != nil {
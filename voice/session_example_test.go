@@ -12,6 +12,7 @@ import (
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/diamondburned/arikawa/v3/voice"
 	"github.com/diamondburned/arikawa/v3/voice/testdata"
+	"github.com/diamondburned/arikawa/v3/voice/voicegateway"
 )
 
 var (
@@ -60,3 +61,39 @@ func ExampleSession() {
 		log.Fatalln("failed to write opus:", err)
 	}
 }
+
+func ExampleManager() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	s := state.New("Bot " + token)
+	voice.AddIntents(s)
+
+	// Create the manager before opening, so it never misses any event.
+	m := voice.NewManager(s)
+	defer m.Close(ctx)
+
+	if err := s.Open(ctx); err != nil {
+		log.Fatalln("failed to open gateway:", err)
+	}
+	defer s.Close()
+
+	ch, err := s.Channel(channelID)
+	if err != nil {
+		log.Fatalln("failed to get voice channel:", err)
+	}
+
+	v, err := m.Join(ctx, ch.GuildID, ch.ID)
+	if err != nil {
+		log.Fatalln("failed to join voice channel:", err)
+	}
+	defer m.Leave(ctx, ch.GuildID)
+
+	if err := v.Speaking(ctx, voicegateway.Microphone); err != nil {
+		log.Fatalln("failed to start speaking:", err)
+	}
+
+	if err := testdata.WriteOpus(v, "testdata/nico.dca"); err != nil {
+		log.Fatalln("failed to write opus:", err)
+	}
+}
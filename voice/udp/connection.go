@@ -1,16 +1,16 @@
 package udp
 
 import (
-	"bytes"
 	"context"
 	"encoding/binary"
-	"io"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/nacl/secretbox"
+
+	"github.com/diamondburned/arikawa/v3/internal/moreatomic"
 )
 
 // ErrDecryptionFailed is returned from ReadPacket if the received packet fails
@@ -49,6 +49,26 @@ type Connection struct {
 	recvOpus   []byte  // len 1400
 	recvPacket *Packet // uses recvOpus' backing array
 
+	// keepalive fields
+	readLock      chan struct{}
+	lastRecv      *moreatomic.Time
+	keepaliveSeq  uint32
+	keepaliveSent time.Time
+	readDeadline  time.Time
+
+	// stats fields; statsMu also guards secret and the keepalive fields
+	statsMu    sync.Mutex
+	hasSecret  bool
+	sources    map[uint32]*rtpSource
+	sent       uint64
+	sentOctets uint64
+	sentTime   uint32
+	received   uint64
+	rtt        time.Duration
+	ping       time.Duration
+	remoteLoss float64
+	remoteLost int64
+
 	closed sync.Once
 }
 
@@ -89,36 +109,12 @@ func DialConnectionCustom(
 		return nil, errors.Wrap(err, "failed to dial host")
 	}
 
-	// https://discord.com/developers/docs/topics/voice-connections#ip-discovery
-	ssrcBuffer := [70]byte{
-		0x1, 0x2,
-	}
-	binary.BigEndian.PutUint16(ssrcBuffer[2:4], 70)
-	binary.BigEndian.PutUint32(ssrcBuffer[4:8], ssrc)
-
-	_, err = conn.Write(ssrcBuffer[:])
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to write SSRC buffer")
-	}
-
-	var ipBuffer [70]byte
-
-	// ReadFull makes sure to read all 70 bytes.
-	_, err = io.ReadFull(conn, ipBuffer[:])
+	ip, port, err := discoverIP(ctx, conn, ssrc)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to read IP buffer")
-	}
-
-	ipbody := ipBuffer[4:68]
-
-	nullPos := bytes.Index(ipbody, []byte{'\x00'})
-	if nullPos < 0 {
-		return nil, errors.New("UDP IP discovery did not contain a null terminator")
+		conn.Close()
+		return nil, err
 	}
 
-	ip := ipbody[:nullPos]
-	port := binary.LittleEndian.Uint16(ipBuffer[68:70])
-
 	// https://discord.com/developers/docs/topics/voice-connections#encrypting-and-sending-voice
 	packet := [12]byte{
 		0: 0x80, // Version + Flags
@@ -130,8 +126,8 @@ func DialConnectionCustom(
 	// Write SSRC to the header.
 	binary.BigEndian.PutUint32(packet[8:12], ssrc) // SSRC
 
-	return &Connection{
-		GatewayIP:   string(ip),
+	c := &Connection{
+		GatewayIP:   ip,
 		GatewayPort: port,
 		frequency:   time.NewTicker(20 * time.Millisecond),
 		timeIncr:    960,
@@ -142,7 +138,14 @@ func DialConnectionCustom(
 		recvBuf:     make([]byte, 1400),
 		recvOpus:    make([]byte, 1400),
 		recvPacket:  &Packet{},
-	}, nil
+		sources:     make(map[uint32]*rtpSource),
+		readLock:    make(chan struct{}, 1),
+		lastRecv:    moreatomic.Now(),
+	}
+
+	go c.reportLoop(RTCPInterval)
+
+	return c, nil
 }
 
 // ResetFrequency resets the internal frequency ticker as well as the timestamp
@@ -177,7 +180,10 @@ func (c *Connection) ResetFrequency(frameDuration time.Duration, timeIncr uint32
 // UseSecret uses the given secret. This method is not thread-safe, so it should
 // only be used right after initialization.
 func (c *Connection) UseSecret(secret [32]byte) {
+	c.statsMu.Lock()
 	c.secret = secret
+	c.hasSecret = true
+	c.statsMu.Unlock()
 }
 
 // SetWriteDeadline sets the UDP connection's write deadline.
@@ -187,6 +193,10 @@ func (c *Connection) SetWriteDeadline(deadline time.Time) {
 
 // SetReadDeadline sets the UDP connection's read deadline.
 func (c *Connection) SetReadDeadline(deadline time.Time) {
+	c.statsMu.Lock()
+	c.readDeadline = deadline
+	c.statsMu.Unlock()
+
 	c.conn.SetReadDeadline(deadline)
 }
 
@@ -231,6 +241,12 @@ func (c *Connection) Write(b []byte) (int, error) {
 		return 0, err
 	}
 
+	c.statsMu.Lock()
+	c.sent++
+	c.sentOctets += uint64(len(b))
+	c.sentTime = c.timestamp
+	c.statsMu.Unlock()
+
 	return len(b), nil
 }
 
@@ -266,7 +282,13 @@ const packetHeaderSize = 12
 // ReadPacket reads the UDP connection and returns a packet if successful. The
 // returned packet is invalidated once ReadPacket is called again. To avoid
 // this, manually Copy the packet.
+//
+// RTCP packets are consumed by ReadPacket to update the connection's Stats and
+// are never returned.
 func (c *Connection) ReadPacket() (*Packet, error) {
+	c.readLock <- struct{}{}
+	defer func() { <-c.readLock }()
+
 	if c.recvPacket.header == nil {
 		// Initialize the recvPacket's header.
 		c.recvPacket.header = c.recvBuf[:12]
@@ -278,6 +300,20 @@ func (c *Connection) ReadPacket() (*Packet, error) {
 			return nil, err
 		}
 
+		c.lastRecv.Set(time.Now())
+
+		if i == keepaliveSize {
+			c.handleKeepalive(c.recvBuf[:i])
+			continue
+		}
+
+		// RTCP packets are checked first, since their first byte may contain
+		// a non-zero report count.
+		if i >= rtcpHeaderSize && isRTCP(c.recvBuf[1]) {
+			c.handleRTCP(c.recvBuf[:i])
+			continue
+		}
+
 		if i < packetHeaderSize || (c.recvBuf[0] != 0x80 && c.recvBuf[0] != 0x90) {
 			continue
 		}
@@ -296,6 +332,8 @@ func (c *Connection) ReadPacket() (*Packet, error) {
 			return nil, ErrDecryptionFailed
 		}
 
+		c.recordReceived(c.recvPacket.SSRC(), c.recvPacket.Sequence(), c.recvPacket.Timestamp())
+
 		// Partial structure of the RTP header for reference
 		//
 		//     0                   1                   2                   3
@@ -0,0 +1,223 @@
+package udp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+)
+
+var (
+	// DiscoveryAttempts is the number of times IP discovery is attempted
+	// before dialing fails.
+	DiscoveryAttempts = 5
+	// DiscoveryTimeout is the duration to wait for each IP discovery response.
+	DiscoveryTimeout = 2 * time.Second
+)
+
+const (
+	// DefaultKeepaliveInterval is the default interval between UDP keepalive
+	// packets, which keep NAT mappings from expiring during silence.
+	DefaultKeepaliveInterval = 5 * time.Second
+	// DefaultDeadTimeout is the default duration without receiving anything,
+	// including keepalive replies, after which the UDP path is considered
+	// dead.
+	DefaultDeadTimeout = time.Minute
+)
+
+const keepaliveSize = 8
+
+// ErrDiscoveryTimeout is returned when IP discovery gets no response after all
+// attempts.
+var ErrDiscoveryTimeout = errors.New("UDP IP discovery timed out")
+
+// discoverIP performs IP discovery and returns our external IP and port.
+//
+// https://discord.com/developers/docs/topics/voice-connections#ip-discovery
+func discoverIP(ctx context.Context, conn net.Conn, ssrc uint32) (string, uint16, error) {
+	ssrcBuffer := [70]byte{
+		0x1, 0x2,
+	}
+	binary.BigEndian.PutUint16(ssrcBuffer[2:4], 70)
+	binary.BigEndian.PutUint32(ssrcBuffer[4:8], ssrc)
+
+	// Unblock reads if the context is cancelled.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	// Reset the deadline for the caller.
+	defer conn.SetReadDeadline(time.Time{})
+
+	var ipBuffer [70]byte
+
+	for i := 0; i < DiscoveryAttempts; i++ {
+		if i > 0 {
+			ws.WSDebug("retrying UDP IP discovery, attempt", i+1)
+		}
+
+		if _, err := conn.Write(ssrcBuffer[:]); err != nil {
+			return "", 0, errors.Wrap(err, "failed to write SSRC buffer")
+		}
+
+		conn.SetReadDeadline(time.Now().Add(DiscoveryTimeout))
+
+		err := readDiscovery(conn, ipBuffer[:])
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", 0, ctx.Err()
+			}
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+
+			return "", 0, errors.Wrap(err, "failed to read IP buffer")
+		}
+
+		ipbody := ipBuffer[4:68]
+
+		nullPos := bytes.Index(ipbody, []byte{'\x00'})
+		if nullPos < 0 {
+			return "", 0, errors.New("UDP IP discovery did not contain a null terminator")
+		}
+
+		ip := ipbody[:nullPos]
+		port := binary.LittleEndian.Uint16(ipBuffer[68:70])
+
+		return string(ip), port, nil
+	}
+
+	return "", 0, ErrDiscoveryTimeout
+}
+
+// readDiscovery reads packets until an IP discovery response is found, skipping
+// any other packet that may arrive in between.
+func readDiscovery(conn net.Conn, buf []byte) error {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+
+		// Response packets are always exactly as long as the request.
+		if n == len(buf) {
+			return nil
+		}
+	}
+}
+
+// SendKeepalive sends a UDP keepalive packet. The server replies with the same
+// packet, which is used to measure the ping and to know that the UDP path is
+// still alive.
+func (c *Connection) SendKeepalive() error {
+	var packet [keepaliveSize]byte
+
+	c.statsMu.Lock()
+	c.keepaliveSeq++
+	c.keepaliveSent = time.Now()
+	binary.LittleEndian.PutUint32(packet[:4], c.keepaliveSeq)
+	c.statsMu.Unlock()
+
+	_, err := c.conn.Write(packet[:])
+	return err
+}
+
+func (c *Connection) handleKeepalive(b []byte) {
+	seq := binary.LittleEndian.Uint32(b[:4])
+
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	if seq == c.keepaliveSeq && !c.keepaliveSent.IsZero() {
+		c.ping = time.Since(c.keepaliveSent)
+	}
+}
+
+// LastReceived returns the time that the last packet of any kind was received.
+// If no packet was received yet, then the time that the connection was dialed
+// is returned.
+func (c *Connection) LastReceived() time.Time {
+	return c.lastRecv.Get()
+}
+
+// poll reads all packets that arrived if nobody is currently reading the
+// connection using ReadPacket. This allows keepalive replies to be seen even
+// if the user never reads. Audio packets read by poll are discarded.
+func (c *Connection) poll() {
+	select {
+	case c.readLock <- struct{}{}:
+		defer func() { <-c.readLock }()
+	default:
+		// Someone is reading, so they'll see the replies.
+		return
+	}
+
+	c.statsMu.Lock()
+	deadline := c.readDeadline
+	c.statsMu.Unlock()
+
+	defer c.conn.SetReadDeadline(deadline)
+
+	var buf [1400]byte
+
+	// Only drain what arrives within a short window, so that a steady stream
+	// of audio doesn't keep us here forever.
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Millisecond))
+
+	for {
+		n, err := c.conn.Read(buf[:])
+		if err != nil {
+			return
+		}
+
+		c.lastRecv.Set(time.Now())
+
+		switch {
+		case n == keepaliveSize:
+			c.handleKeepalive(buf[:n])
+		case n >= rtcpHeaderSize && isRTCP(buf[1]):
+			c.handleRTCP(buf[:n])
+		}
+	}
+}
+
+// keepaliveLoop sends keepalives every interval until the connection is closed.
+// If nothing is received for the timeout duration, then dead is called and the
+// loop exits.
+func (c *Connection) keepaliveLoop(interval, timeout time.Duration, dead func(*Connection)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopFreq:
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.SendKeepalive(); err != nil {
+			ws.WSDebug("UDP keepalive error:", err)
+		}
+
+		c.poll()
+
+		if timeout > 0 && time.Since(c.LastReceived()) > timeout {
+			ws.WSDebug("UDP path is dead, last received at", c.LastReceived())
+			dead(c)
+			return
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package udp
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeVoiceServer is a UDP server that answers IP discovery and echoes
+// keepalives.
+type fakeVoiceServer struct {
+	conn *net.UDPConn
+	// dropDiscovery is the number of IP discovery requests to ignore.
+	dropDiscovery int32
+	// mute stops the server from replying to anything.
+	mute int32
+}
+
+func newFakeVoiceServer(t *testing.T) *fakeVoiceServer {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal("failed to listen:", err)
+	}
+
+	s := &fakeVoiceServer{conn: conn}
+	t.Cleanup(func() { conn.Close() })
+
+	go s.serve()
+	return s
+}
+
+func (s *fakeVoiceServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *fakeVoiceServer) serve() {
+	var buf [1500]byte
+
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf[:])
+		if err != nil {
+			return
+		}
+
+		if atomic.LoadInt32(&s.mute) == 1 {
+			continue
+		}
+
+		switch {
+		case n == 70:
+			if atomic.AddInt32(&s.dropDiscovery, -1) >= 0 {
+				continue
+			}
+
+			var reply [70]byte
+			copy(reply[0:4], buf[4:8])
+			copy(reply[4:68], "127.0.0.1")
+			binary.LittleEndian.PutUint16(reply[68:70], 1234)
+
+			s.conn.WriteToUDP(reply[:], addr)
+
+		case n == keepaliveSize:
+			s.conn.WriteToUDP(buf[:n], addr)
+		}
+	}
+}
+
+func TestDiscoveryRetry(t *testing.T) {
+	timeout := DiscoveryTimeout
+	DiscoveryTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { DiscoveryTimeout = timeout })
+
+	server := newFakeVoiceServer(t)
+	atomic.StoreInt32(&server.dropDiscovery, 2)
+
+	conn, err := DialConnection(context.Background(), server.addr(), 42)
+	if err != nil {
+		t.Fatal("failed to dial:", err)
+	}
+	defer conn.Close()
+
+	if conn.GatewayIP != "127.0.0.1" || conn.GatewayPort != 1234 {
+		t.Fatalf("unexpected discovered address %s:%d", conn.GatewayIP, conn.GatewayPort)
+	}
+}
+
+func TestDiscoveryTimeout(t *testing.T) {
+	timeout := DiscoveryTimeout
+	DiscoveryTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { DiscoveryTimeout = timeout })
+
+	server := newFakeVoiceServer(t)
+	atomic.StoreInt32(&server.mute, 1)
+
+	_, err := DialConnection(context.Background(), server.addr(), 42)
+	if err != ErrDiscoveryTimeout {
+		t.Fatalf("expected ErrDiscoveryTimeout, got %v", err)
+	}
+}
+
+func TestKeepaliveDead(t *testing.T) {
+	server := newFakeVoiceServer(t)
+
+	dead := make(chan struct{}, 1)
+
+	m := NewManager()
+	m.SetKeepalive(10*time.Millisecond, 100*time.Millisecond)
+	m.SetDeadHandler(func() { dead <- struct{}{} })
+
+	if err := m.Pause(context.Background()); err != nil {
+		t.Fatal("failed to pause:", err)
+	}
+
+	conn, err := m.Dial(context.Background(), server.addr(), 42)
+	if err != nil {
+		t.Fatal("failed to dial:", err)
+	}
+	m.Continue()
+	defer m.Close()
+
+	// Keepalives are echoed, so the connection must stay alive.
+	select {
+	case <-dead:
+		t.Fatal("connection marked dead while keepalives are echoed")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if stats := conn.Stats(); stats.Ping == 0 {
+		t.Error("keepalive ping was not measured")
+	}
+
+	atomic.StoreInt32(&server.mute, 1)
+
+	select {
+	case <-dead:
+	case <-time.After(time.Second):
+		t.Fatal("dead connection was not detected")
+	}
+}
@@ -32,6 +32,10 @@ type Manager struct {
 
 	frequency time.Duration
 	timeIncr  uint32
+
+	keepalive   time.Duration
+	deadTimeout time.Duration
+	onDead      func()
 }
 
 // NewManager creates a new UDP connection manager with the default dial
@@ -43,9 +47,11 @@ func NewManager() *Manager {
 // NewManagerWithDialer creates a UDP manager with an existing dial function.
 func NewManagerWithDialer(dialer DialFunc) *Manager {
 	return &Manager{
-		dialer:   dialer,
-		stopConn: make(chan struct{}),
-		connLock: make(chan struct{}, 1),
+		dialer:      dialer,
+		stopConn:    make(chan struct{}),
+		connLock:    make(chan struct{}, 1),
+		keepalive:   DefaultKeepaliveInterval,
+		deadTimeout: DefaultDeadTimeout,
 	}
 }
 
@@ -62,6 +68,32 @@ func (m *Manager) SetDialer(d DialFunc) {
 	}
 }
 
+// SetKeepalive sets the interval between UDP keepalive packets and the duration
+// without receiving anything after which the connection is considered dead. A
+// zero timeout disables dead connection detection. The new values only apply
+// to connections dialed afterwards.
+func (m *Manager) SetKeepalive(interval, deadTimeout time.Duration) {
+	m.stopMu.Lock()
+	defer m.stopMu.Unlock()
+
+	m.keepalive = interval
+	m.deadTimeout = deadTimeout
+}
+
+// SetDeadHandler sets the function to be called in a new goroutine when the
+// current connection is detected to be dead. The function would usually
+// redial the Manager. Calling this function while the Manager is working will
+// cause a panic. Only call this method directly after construction.
+func (m *Manager) SetDeadHandler(f func()) {
+	select {
+	case m.connLock <- struct{}{}:
+		m.onDead = f
+		<-m.connLock
+	default:
+		panic("SetDeadHandler called while Manager is working")
+	}
+}
+
 // Pause explicitly pauses the manager. It blocks until the Manager is paused or
 // the context expires.
 func (m *Manager) Pause(ctx context.Context) error {
@@ -99,6 +131,11 @@ func (m *Manager) Close() (err error) {
 		ws.WSDebug("UDP manager closed")
 	}
 
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+
 	return nil
 }
 
@@ -154,11 +191,20 @@ func (m *Manager) Dial(ctx context.Context, addr string, ssrc uint32) (*Connecti
 
 	m.stopMu.Lock()
 	ws.WSDebug("setting UDP conn to one w/ gateway address", conn.GatewayIP)
+	if m.conn != nil {
+		// Close the old connection, since it's being replaced.
+		m.conn.Close()
+	}
 	m.conn = conn
 	m.stopDial = nil
 	m.stopConn = make(chan struct{})
+	keepalive, deadTimeout := m.keepalive, m.deadTimeout
 	m.stopMu.Unlock()
 
+	if keepalive > 0 {
+		go conn.keepaliveLoop(keepalive, deadTimeout, m.connDead)
+	}
+
 	return conn, nil
 }
 
@@ -199,6 +245,30 @@ func (m *Manager) Write(b []byte) (n int, err error) {
 	return conn.Write(b)
 }
 
+// Stats returns the statistics of the current connection. It blocks if the
+// connection is being re-established.
+func (m *Manager) Stats() (Stats, error) {
+	conn := m.acquireConn()
+	if conn == nil {
+		return Stats{}, ErrManagerClosed
+	}
+
+	return conn.Stats(), nil
+}
+
+// connDead is called when the given connection is detected to be dead.
+func (m *Manager) connDead(conn *Connection) {
+	m.stopMu.Lock()
+	current := m.conn == conn
+	m.stopMu.Unlock()
+
+	// onDead is only set before the Manager is used, so it's fine to read it
+	// here.
+	if current && m.onDead != nil {
+		go m.onDead()
+	}
+}
+
 // acquireConn acquires the current connection and releases the lock, returning
 // the connection at that point in time. Nil is returned if Manager is closed.
 func (m *Manager) acquireConn() *Connection {
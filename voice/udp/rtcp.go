@@ -0,0 +1,536 @@
+package udp
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// RTCPInterval is the default interval between RTCP reports sent by a
+// Connection. RFC3550 recommends a minimum of 5 seconds.
+var RTCPInterval = 5 * time.Second
+
+// clockRate is the RTP clock rate of Opus, which is always 48kHz.
+const clockRate = 48000
+
+// RTCP packet types.
+//
+// https://tools.ietf.org/html/rfc3550#section-12.1
+const (
+	RTCPSenderReport   byte = 200
+	RTCPReceiverReport byte = 201
+	RTCPSourceDesc     byte = 202
+	RTCPGoodbye        byte = 203
+	RTCPApplication    byte = 204
+)
+
+const (
+	rtcpHeaderSize  = 8
+	rtcpSenderSize  = 20
+	rtcpReportSize  = 24
+	maxReportBlocks = 31
+)
+
+// isRTCP returns true if the given packet type byte is of an RTCP packet.
+func isRTCP(packetType byte) bool {
+	return packetType >= RTCPSenderReport && packetType <= RTCPApplication
+}
+
+// ReceptionReport is a reception report block inside an RTCP sender or
+// receiver report. It describes how the reporter receives the stream of SSRC.
+//
+// https://tools.ietf.org/html/rfc3550#section-6.4.1
+type ReceptionReport struct {
+	SSRC uint32
+	// FractionLost is the fraction of packets lost since the last report,
+	// as a fixed point number with the binary point at the left edge.
+	FractionLost uint8
+	// TotalLost is the cumulative number of packets lost.
+	TotalLost int32
+	// HighestSequence is the extended highest sequence number received.
+	HighestSequence uint32
+	// Jitter is the interarrival jitter in timestamp units.
+	Jitter uint32
+	// LastSenderReport is the middle 32 bits of the NTP timestamp of the last
+	// sender report received from SSRC.
+	LastSenderReport uint32
+	// DelaySinceLastSR is the delay between receiving the last sender report
+	// and sending this report, in units of 1/65536 seconds.
+	DelaySinceLastSR uint32
+}
+
+// RTCPReport is a parsed RTCP sender or receiver report. Receiver reports have
+// a zero NTPTime.
+type RTCPReport struct {
+	Type byte
+	SSRC uint32
+
+	// Sender info, only valid for sender reports.
+	NTPTime      uint64
+	RTPTime      uint32
+	PacketCount  uint32
+	OctetCount   uint32
+	ReportBlocks []ReceptionReport
+}
+
+// ParseRTCP parses a compound RTCP packet and returns all sender and receiver
+// reports within it. Other packet types are skipped.
+func ParseRTCP(b []byte) ([]RTCPReport, error) {
+	var reports []RTCPReport
+
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return reports, errors.New("RTCP packet too short")
+		}
+
+		if b[0]>>6 != 2 {
+			return reports, errors.New("invalid RTCP version")
+		}
+
+		count := int(b[0] & 0x1F)
+		packetType := b[1]
+		length := 4 * (int(binary.BigEndian.Uint16(b[2:4])) + 1)
+
+		if length > len(b) {
+			return reports, errors.New("RTCP packet length overflows buffer")
+		}
+
+		packet := b[:length]
+		b = b[length:]
+
+		var offset int
+
+		switch packetType {
+		case RTCPSenderReport:
+			offset = rtcpHeaderSize + rtcpSenderSize
+		case RTCPReceiverReport:
+			offset = rtcpHeaderSize
+		default:
+			continue
+		}
+
+		if len(packet) < offset+count*rtcpReportSize {
+			return reports, errors.New("RTCP report too short")
+		}
+
+		report := RTCPReport{
+			Type:         packetType,
+			SSRC:         binary.BigEndian.Uint32(packet[4:8]),
+			ReportBlocks: make([]ReceptionReport, count),
+		}
+
+		if packetType == RTCPSenderReport {
+			report.NTPTime = binary.BigEndian.Uint64(packet[8:16])
+			report.RTPTime = binary.BigEndian.Uint32(packet[16:20])
+			report.PacketCount = binary.BigEndian.Uint32(packet[20:24])
+			report.OctetCount = binary.BigEndian.Uint32(packet[24:28])
+		}
+
+		for i := range report.ReportBlocks {
+			block := packet[offset+i*rtcpReportSize:]
+			report.ReportBlocks[i] = parseReceptionReport(block)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func parseReceptionReport(b []byte) ReceptionReport {
+	// The cumulative number of packets lost is a signed 24-bit integer.
+	lost := int32(uint32(b[5])<<16|uint32(b[6])<<8|uint32(b[7])) << 8 >> 8
+
+	return ReceptionReport{
+		SSRC:             binary.BigEndian.Uint32(b[0:4]),
+		FractionLost:     b[4],
+		TotalLost:        lost,
+		HighestSequence:  binary.BigEndian.Uint32(b[8:12]),
+		Jitter:           binary.BigEndian.Uint32(b[12:16]),
+		LastSenderReport: binary.BigEndian.Uint32(b[16:20]),
+		DelaySinceLastSR: binary.BigEndian.Uint32(b[20:24]),
+	}
+}
+
+// AppendRTCP appends the marshaled report into b and returns the new slice.
+// At most 31 reception reports are written.
+func AppendRTCP(b []byte, report RTCPReport) []byte {
+	blocks := report.ReportBlocks
+	if len(blocks) > maxReportBlocks {
+		blocks = blocks[:maxReportBlocks]
+	}
+
+	size := rtcpHeaderSize + len(blocks)*rtcpReportSize
+	if report.Type == RTCPSenderReport {
+		size += rtcpSenderSize
+	}
+
+	start := len(b)
+	b = append(b, make([]byte, size)...)
+	packet := b[start:]
+
+	packet[0] = 2<<6 | byte(len(blocks))
+	packet[1] = report.Type
+	binary.BigEndian.PutUint16(packet[2:4], uint16(size/4-1))
+	binary.BigEndian.PutUint32(packet[4:8], report.SSRC)
+
+	offset := rtcpHeaderSize
+	if report.Type == RTCPSenderReport {
+		binary.BigEndian.PutUint64(packet[8:16], report.NTPTime)
+		binary.BigEndian.PutUint32(packet[16:20], report.RTPTime)
+		binary.BigEndian.PutUint32(packet[20:24], report.PacketCount)
+		binary.BigEndian.PutUint32(packet[24:28], report.OctetCount)
+		offset += rtcpSenderSize
+	}
+
+	for i, block := range blocks {
+		p := packet[offset+i*rtcpReportSize:]
+		binary.BigEndian.PutUint32(p[0:4], block.SSRC)
+		p[4] = block.FractionLost
+		p[5] = byte(block.TotalLost >> 16)
+		p[6] = byte(block.TotalLost >> 8)
+		p[7] = byte(block.TotalLost)
+		binary.BigEndian.PutUint32(p[8:12], block.HighestSequence)
+		binary.BigEndian.PutUint32(p[12:16], block.Jitter)
+		binary.BigEndian.PutUint32(p[16:20], block.LastSenderReport)
+		binary.BigEndian.PutUint32(p[20:24], block.DelaySinceLastSR)
+	}
+
+	return b
+}
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and
+// the Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+// ntpTime converts the given time to a 64-bit NTP timestamp.
+func ntpTime(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+// ntpShort returns the middle 32 bits of the 64-bit NTP timestamp.
+func ntpShort(ntp uint64) uint32 {
+	return uint32(ntp >> 16)
+}
+
+// shortDuration converts a duration in units of 1/65536 seconds into a
+// time.Duration.
+func shortDuration(short uint32) time.Duration {
+	return time.Duration(uint64(short) * uint64(time.Second) >> 16)
+}
+
+// Stats contains the statistics of a voice UDP connection.
+type Stats struct {
+	// PacketsSent is the number of RTP packets sent.
+	PacketsSent uint64
+	// PacketsReceived is the number of RTP packets received.
+	PacketsReceived uint64
+	// PacketsLost is the estimated number of incoming RTP packets lost, summed
+	// across all remote sources.
+	PacketsLost int64
+	// Jitter is the highest interarrival jitter across all remote sources.
+	Jitter time.Duration
+	// RTT is the last round-trip time computed from a reception report of our
+	// own stream. It is zero if it's not yet known.
+	RTT time.Duration
+	// RemoteFractionLost is the fraction of our outgoing packets lost, as last
+	// reported by the server.
+	RemoteFractionLost float64
+	// RemotePacketsLost is the cumulative number of our outgoing packets lost,
+	// as last reported by the server.
+	RemotePacketsLost int64
+	// Ping is the last round-trip time of a UDP keepalive packet. It is zero
+	// if it's not yet known.
+	Ping time.Duration
+}
+
+// rtpSource keeps track of the reception statistics of a single remote SSRC.
+// Refer to RFC3550 appendix A.1 and A.8.
+type rtpSource struct {
+	baseSeq  uint16
+	maxSeq   uint16
+	cycles   uint32
+	received uint32
+
+	expectedPrior uint32
+	receivedPrior uint32
+
+	start   time.Time // arrival of the first packet
+	transit uint32
+	jitter  float64
+
+	lastSR     uint32
+	lastSRTime time.Time
+}
+
+func newRTPSource(seq uint16) *rtpSource {
+	return &rtpSource{
+		baseSeq: seq,
+		maxSeq:  seq,
+	}
+}
+
+// update updates the source using a received RTP packet.
+func (s *rtpSource) update(seq uint16, timestamp uint32, arrival time.Time) {
+	s.received++
+
+	// Only move forward if the packet is in order; older packets are counted
+	// as received but don't change the highest sequence number.
+	if delta := seq - s.maxSeq; delta != 0 && delta < 0x8000 {
+		if seq < s.maxSeq {
+			s.cycles += 1 << 16
+		}
+		s.maxSeq = seq
+	}
+
+	// Interarrival jitter in timestamp units. The arrival time is relative to
+	// the first packet, so that scaling it to the clock rate can't overflow,
+	// and the transit times wrap around like the RTP timestamps do.
+	if s.start.IsZero() {
+		s.start = arrival
+	}
+
+	since := arrival.Sub(s.start)
+	arrivalTS := uint32(int64(since/time.Second)*clockRate +
+		int64(since%time.Second)*clockRate/int64(time.Second))
+	transit := arrivalTS - timestamp
+
+	if s.received > 1 {
+		d := int32(transit - s.transit)
+		if d < 0 {
+			d = -d
+		}
+		s.jitter += (float64(d) - s.jitter) / 16
+	}
+
+	s.transit = transit
+}
+
+func (s *rtpSource) extendedMax() uint32 {
+	return s.cycles + uint32(s.maxSeq)
+}
+
+func (s *rtpSource) expected() uint32 {
+	return s.extendedMax() - uint32(s.baseSeq) + 1
+}
+
+func (s *rtpSource) lost() int64 {
+	return int64(s.expected()) - int64(s.received)
+}
+
+func (s *rtpSource) jitterDuration() time.Duration {
+	return time.Duration(s.jitter * float64(time.Second) / clockRate)
+}
+
+// report creates a reception report of the source and resets the interval
+// counters.
+func (s *rtpSource) report(ssrc uint32, now time.Time) ReceptionReport {
+	expected := s.expected()
+	expectedInterval := expected - s.expectedPrior
+	receivedInterval := s.received - s.receivedPrior
+	s.expectedPrior = expected
+	s.receivedPrior = s.received
+
+	var fraction uint8
+	if lostInterval := int64(expectedInterval) - int64(receivedInterval); expectedInterval > 0 && lostInterval > 0 {
+		fraction = uint8((lostInterval << 8) / int64(expectedInterval))
+	}
+
+	lost := s.lost()
+	// Clamp into a signed 24-bit integer.
+	if lost > 0x7FFFFF {
+		lost = 0x7FFFFF
+	} else if lost < -0x800000 {
+		lost = -0x800000
+	}
+
+	var dlsr uint32
+	if !s.lastSRTime.IsZero() {
+		dlsr = uint32(now.Sub(s.lastSRTime) * (1 << 16) / time.Second)
+	}
+
+	return ReceptionReport{
+		SSRC:             ssrc,
+		FractionLost:     fraction,
+		TotalLost:        int32(lost),
+		HighestSequence:  s.extendedMax(),
+		Jitter:           uint32(s.jitter),
+		LastSenderReport: s.lastSR,
+		DelaySinceLastSR: dlsr,
+	}
+}
+
+// Stats returns the current statistics of the connection. It is safe to call
+// concurrently. Incoming statistics are only updated as ReadPacket is called.
+func (c *Connection) Stats() Stats {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	stats := Stats{
+		PacketsSent:        c.sent,
+		PacketsReceived:    c.received,
+		RTT:                c.rtt,
+		RemoteFractionLost: c.remoteLoss,
+		RemotePacketsLost:  c.remoteLost,
+		Ping:               c.ping,
+	}
+
+	for _, source := range c.sources {
+		stats.PacketsLost += source.lost()
+		if jitter := source.jitterDuration(); jitter > stats.Jitter {
+			stats.Jitter = jitter
+		}
+	}
+
+	return stats
+}
+
+func (c *Connection) recordReceived(ssrc uint32, seq uint16, timestamp uint32) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.received++
+
+	source, ok := c.sources[ssrc]
+	if !ok {
+		source = newRTPSource(seq)
+		c.sources[ssrc] = source
+	}
+
+	source.update(seq, timestamp, time.Now())
+}
+
+// handleRTCP decrypts and parses the given RTCP packet and updates the
+// statistics with it. Invalid packets are ignored.
+//
+// Only the first 8 bytes of an RTCP packet are unencrypted. They are used as
+// the nonce, similarly to RTP packets.
+func (c *Connection) handleRTCP(b []byte) {
+	var nonce [24]byte
+	copy(nonce[:], b[:rtcpHeaderSize])
+
+	c.statsMu.Lock()
+	secret := c.secret
+	c.statsMu.Unlock()
+
+	body, ok := secretbox.Open(nil, b[rtcpHeaderSize:], &nonce, &secret)
+	if !ok {
+		return
+	}
+
+	packet := append(append(make([]byte, 0, len(b)), b[:rtcpHeaderSize]...), body...)
+	// The length field may cover the encrypted packet, so clamp it to the
+	// decrypted one.
+	if length := 4 * (int(binary.BigEndian.Uint16(packet[2:4])) + 1); length > len(packet) {
+		binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)/4-1))
+	}
+
+	reports, _ := ParseRTCP(packet)
+	now := time.Now()
+
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	for _, report := range reports {
+		if report.Type == RTCPSenderReport {
+			if source, ok := c.sources[report.SSRC]; ok {
+				source.lastSR = ntpShort(report.NTPTime)
+				source.lastSRTime = now
+			}
+		}
+
+		for _, block := range report.ReportBlocks {
+			if block.SSRC != c.ssrc {
+				continue
+			}
+
+			c.remoteLoss = float64(block.FractionLost) / 256
+			c.remoteLost = int64(block.TotalLost)
+
+			// https://tools.ietf.org/html/rfc3550#section-6.4.1, see the
+			// paragraph on round-trip propagation delay.
+			if block.LastSenderReport != 0 {
+				rtt := ntpShort(ntpTime(now)) - block.LastSenderReport - block.DelaySinceLastSR
+				// Ignore clearly bogus values caused by clock wraparounds.
+				if rtt < 1<<31 {
+					c.rtt = shortDuration(rtt)
+				}
+			}
+		}
+	}
+}
+
+// report creates an RTCP report of the connection. A sender report is made if
+// any packet was sent, otherwise a receiver report is made. The boolean is
+// false if the secret is not known yet.
+func (c *Connection) report(now time.Time) (RTCPReport, [32]byte, bool) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	if !c.hasSecret {
+		return RTCPReport{}, c.secret, false
+	}
+
+	report := RTCPReport{
+		Type: RTCPReceiverReport,
+		SSRC: c.ssrc,
+	}
+
+	if c.sent > 0 {
+		report.Type = RTCPSenderReport
+		report.NTPTime = ntpTime(now)
+		report.RTPTime = c.sentTime
+		report.PacketCount = uint32(c.sent)
+		report.OctetCount = uint32(c.sentOctets)
+	}
+
+	for ssrc, source := range c.sources {
+		report.ReportBlocks = append(report.ReportBlocks, source.report(ssrc, now))
+	}
+
+	return report, c.secret, true
+}
+
+// sendReport sends an RTCP sender or receiver report to the server.
+func (c *Connection) sendReport() error {
+	report, secret, ok := c.report(time.Now())
+	if !ok {
+		return nil
+	}
+
+	packet := AppendRTCP(nil, report)
+
+	// Account for the authenticator in the length field. This must be done
+	// before making the nonce, since the receiver takes the nonce from the
+	// header as sent.
+	sealedLen := len(packet) + secretbox.Overhead
+	binary.BigEndian.PutUint16(packet[2:4], uint16((sealedLen+3)/4-1))
+
+	var nonce [24]byte
+	copy(nonce[:], packet[:rtcpHeaderSize])
+
+	toSend := secretbox.Seal(packet[:rtcpHeaderSize:rtcpHeaderSize], packet[rtcpHeaderSize:], &nonce, &secret)
+
+	_, err := c.conn.Write(toSend)
+	return err
+}
+
+func (c *Connection) reportLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopFreq:
+			return
+		case <-ticker.C:
+			// Errors are ignored, since the same error would also surface from
+			// Write or ReadPacket.
+			c.sendReport()
+		}
+	}
+}
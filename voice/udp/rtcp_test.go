@@ -0,0 +1,193 @@
+package udp
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+func TestRTCPRoundTrip(t *testing.T) {
+	reports := []RTCPReport{
+		{
+			Type:        RTCPSenderReport,
+			SSRC:        1,
+			NTPTime:     ntpTime(time.Now()),
+			RTPTime:     960,
+			PacketCount: 10,
+			OctetCount:  1000,
+			ReportBlocks: []ReceptionReport{
+				{SSRC: 2, FractionLost: 64, TotalLost: -3, HighestSequence: 70000},
+			},
+		},
+		{
+			Type:         RTCPReceiverReport,
+			SSRC:         1,
+			ReportBlocks: []ReceptionReport{},
+		},
+	}
+
+	var b []byte
+	for _, report := range reports {
+		b = AppendRTCP(b, report)
+	}
+
+	parsed, err := ParseRTCP(b)
+	if err != nil {
+		t.Fatal("failed to parse RTCP:", err)
+	}
+
+	if !reflect.DeepEqual(reports, parsed) {
+		t.Fatalf("parsed reports differ:\nexpected %#v\ngot      %#v", reports, parsed)
+	}
+}
+
+func TestRTPSourceLoss(t *testing.T) {
+	now := time.Now()
+	s := newRTPSource(65534)
+
+	// 65534, 65535, 0, (1 lost), 2, then a late 65535.
+	for _, seq := range []uint16{65534, 65535, 0, 2, 65535} {
+		s.update(seq, uint32(seq)*960, now)
+	}
+
+	if max := s.extendedMax(); max != 1<<16+2 {
+		t.Fatalf("unexpected extended max %d", max)
+	}
+
+	// 5 received (one duplicate) out of 5 expected.
+	if lost := s.lost(); lost != 0 {
+		t.Fatalf("expected 0 lost counting the duplicate, got %d", lost)
+	}
+
+	report := s.report(5, now)
+	if report.HighestSequence != 1<<16+2 {
+		t.Fatalf("unexpected highest sequence %d", report.HighestSequence)
+	}
+}
+
+func TestRTPSourceJitter(t *testing.T) {
+	start := time.Now()
+	// Start right before the timestamps wrap around.
+	const baseTS = 1<<32 - 960*5
+
+	s := newRTPSource(0)
+
+	// Packets arriving exactly every 20ms have no jitter.
+	for i := 0; i < 50; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		s.update(uint16(i), baseTS+uint32(i)*960, arrival)
+	}
+
+	if s.jitter > 1 {
+		t.Fatalf("expected no jitter, got %v timestamp units", s.jitter)
+	}
+
+	// Packets alternating between 10ms early and late have about 20ms jitter.
+	for i := 50; i < 500; i++ {
+		offset := 10 * time.Millisecond
+		if i%2 == 0 {
+			offset = -offset
+		}
+		arrival := start.Add(time.Duration(i)*20*time.Millisecond + offset)
+		s.update(uint16(i), baseTS+uint32(i)*960, arrival)
+	}
+
+	if jitter := s.jitterDuration(); jitter < 19*time.Millisecond || jitter > 21*time.Millisecond {
+		t.Fatalf("expected 20ms of jitter, got %v", jitter)
+	}
+}
+
+func TestConnectionSendReport(t *testing.T) {
+	server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal("failed to listen:", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := net.Dial("udp", server.LocalAddr().String())
+	if err != nil {
+		t.Fatal("failed to dial:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	sender := &Connection{
+		conn:     conn,
+		ssrc:     42,
+		sent:     10,
+		sentTime: 9600,
+		sources:  map[uint32]*rtpSource{7: newRTPSource(0)},
+	}
+	sender.UseSecret([32]byte{1, 2, 3})
+
+	receiver := &Connection{
+		ssrc:    7,
+		sources: map[uint32]*rtpSource{42: newRTPSource(0)},
+	}
+	receiver.UseSecret([32]byte{1, 2, 3})
+
+	if err := sender.sendReport(); err != nil {
+		t.Fatal("failed to send report:", err)
+	}
+
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var buf [1500]byte
+	n, err := server.Read(buf[:])
+	if err != nil {
+		t.Fatal("failed to read report:", err)
+	}
+
+	if length := 4 * (int(binary.BigEndian.Uint16(buf[2:4])) + 1); length < n {
+		t.Fatalf("length field covers %d bytes of the %d byte packet", length, n)
+	}
+
+	receiver.handleRTCP(buf[:n])
+
+	receiver.statsMu.Lock()
+	lastSR := receiver.sources[42].lastSR
+	receiver.statsMu.Unlock()
+
+	if lastSR == 0 {
+		t.Fatal("sender report was not received")
+	}
+}
+
+func TestConnectionRTT(t *testing.T) {
+	c := &Connection{
+		ssrc:    42,
+		sources: make(map[uint32]*rtpSource),
+	}
+	c.UseSecret([32]byte{1, 2, 3})
+
+	const delay = 250 * time.Millisecond
+
+	packet := AppendRTCP(nil, RTCPReport{
+		Type: RTCPReceiverReport,
+		SSRC: 7,
+		ReportBlocks: []ReceptionReport{{
+			SSRC:             42,
+			FractionLost:     128,
+			TotalLost:        12,
+			LastSenderReport: ntpShort(ntpTime(time.Now().Add(-delay))),
+		}},
+	})
+
+	var nonce [24]byte
+	copy(nonce[:], packet[:rtcpHeaderSize])
+	encrypted := secretbox.Seal(packet[:rtcpHeaderSize:rtcpHeaderSize], packet[rtcpHeaderSize:], &nonce, &c.secret)
+
+	c.handleRTCP(encrypted)
+
+	stats := c.Stats()
+	if stats.RemoteFractionLost != 0.5 || stats.RemotePacketsLost != 12 {
+		t.Fatalf("unexpected remote loss in %#v", stats)
+	}
+
+	if stats.RTT < delay || stats.RTT > delay+100*time.Millisecond {
+		t.Fatalf("unexpected RTT %v", stats.RTT)
+	}
+}
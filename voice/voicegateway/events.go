@@ -123,6 +123,19 @@ type ClientConnectEvent struct {
 	UserID    discord.UserID `json:"user_id"`
 	AudioSSRC uint32         `json:"audio_ssrc"`
 	VideoSSRC uint32         `json:"video_ssrc"`
+	RTXSSRC   uint32         `json:"rtx_ssrc,omitempty"`
+	Streams   []VideoStream  `json:"streams,omitempty"`
+}
+
+// VideoStream describes a single video stream (simulcast layer) of a client.
+// It is undocumented.
+type VideoStream struct {
+	Type    string `json:"type"`
+	RID     string `json:"rid"`
+	SSRC    uint32 `json:"ssrc"`
+	RTXSSRC uint32 `json:"rtx_ssrc"`
+	Active  bool   `json:"active"`
+	Quality int    `json:"quality"`
 }
 
 // ClientDisconnectEvent is an event for Op 13. It is undocumented, but its
@@ -52,10 +52,7 @@ type Gateway struct {
 // DefaultGatewayOpts contains the default options to be used for connecting to
 // the gateway.
 var DefaultGatewayOpts = ws.GatewayOpts{
-	ReconnectDelay: func(try int) time.Duration {
-		// minimum 4 seconds
-		return time.Duration(4+(2*try)) * time.Second
-	},
+	ReconnectDelay: ws.ExponentialBackoff(4*time.Second, 2*time.Minute),
 	// FatalCloseCodes contains the default gateway close codes that will cause
 	// the gateway to exit. In other words, it's a list of unrecoverable close
 	// codes.
@@ -0,0 +1,21 @@
+package voicegateway
+
+import "github.com/diamondburned/arikawa/v3/utils/ws"
+
+// Commands that keep the connection alive are never delayed by other
+// commands. See ws.SendPriority.
+
+var (
+	_ ws.PrioritizedEvent = (*HeartbeatCommand)(nil)
+	_ ws.PrioritizedEvent = (*IdentifyCommand)(nil)
+	_ ws.PrioritizedEvent = (*ResumeCommand)(nil)
+)
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*HeartbeatCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*IdentifyCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }
+
+// SendPriority implements ws.PrioritizedEvent.
+func (*ResumeCommand) SendPriority() ws.SendPriority { return ws.SendPriorityCritical }